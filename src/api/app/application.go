@@ -1,18 +1,344 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"log"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/config"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/health"
+	"golang-microservices/src/api/labels"
+	"golang-microservices/src/api/middlewares"
+	"golang-microservices/src/api/openapi"
+	"golang-microservices/src/api/protection"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/providers/gitea_provider"
+	"golang-microservices/src/api/providers/github_provider"
+	"golang-microservices/src/api/providers/gitlab_provider"
+	"golang-microservices/src/api/ratelimit"
+	"golang-microservices/src/api/secrets"
+	"golang-microservices/src/api/services"
+	"golang-microservices/src/api/templates"
+	"golang-microservices/src/api/tracing"
+	"golang-microservices/src/api/utils/logger"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
-var router *gin.Engine
+const tracingExporterStdout = "stdout"
 
-func init() {
-	router = gin.Default()
+type Application struct {
+	router    *gin.Engine
+	inFlight  *inFlightRequests
+	health    services.HealthServiceInterface
+	auditSink audit.Sink
+}
+
+type dependencies struct {
+	providers      map[string]providers.RepoProvider
+	auditLog       audit.Log
+	rateLimitStore ratelimit.Store
+	quotaStore     ratelimit.Store
+	templates      *templates.Store
+	secrets        *secrets.Store
+	protection     *protection.Store
+	labels         *labels.Store
+}
+
+type Option func(*dependencies)
+
+// WithProvider replaces the provider registered under name, mostly useful to
+// plug fakes in tests.
+func WithProvider(name string, provider providers.RepoProvider) Option {
+	return func(d *dependencies) {
+		d.providers[name] = provider
+	}
+}
+
+func WithAuditLog(auditLog audit.Log) Option {
+	return func(d *dependencies) {
+		d.auditLog = auditLog
+	}
+}
+
+func WithRateLimitStore(store ratelimit.Store) Option {
+	return func(d *dependencies) {
+		d.rateLimitStore = store
+		d.quotaStore = store
+	}
+}
+
+func WithTemplates(store *templates.Store) Option {
+	return func(d *dependencies) {
+		d.templates = store
+	}
+}
+
+func WithSecrets(store *secrets.Store) Option {
+	return func(d *dependencies) {
+		d.secrets = store
+	}
+}
+
+func WithProtectionPolicies(store *protection.Store) Option {
+	return func(d *dependencies) {
+		d.protection = store
+	}
+}
+
+func WithLabelSets(store *labels.Store) Option {
+	return func(d *dependencies) {
+		d.labels = store
+	}
+}
+
+// load fills the dependencies not given as options from the configuration.
+func (d *dependencies) load(cfg config.Config) error {
+	var err error
+	if d.templates == nil {
+		if d.templates, err = templates.Load(cfg.TemplatesDir); err != nil {
+			return err
+		}
+	}
+	if d.secrets == nil {
+		if d.secrets, err = secrets.Load(cfg.SecretsFile); err != nil {
+			return err
+		}
+	}
+	if d.protection == nil {
+		if d.protection, err = protection.Load(cfg.ProtectionPoliciesFile); err != nil {
+			return err
+		}
+	}
+	if _, ok := d.protection.Get(cfg.DefaultProtectionPolicy); cfg.DefaultProtectionPolicy != "" && !ok {
+		return fmt.Errorf("unknown default protection policy %s", cfg.DefaultProtectionPolicy)
+	}
+	if d.labels == nil {
+		if d.labels, err = labels.Load(cfg.LabelSetsFile); err != nil {
+			return err
+		}
+	}
+	if d.rateLimitStore == nil {
+		d.rateLimitStore = ratelimit.NewMemoryStore()
+		d.quotaStore = ratelimit.NewMemoryStore()
+	}
+	if d.auditLog == nil {
+		fileSink, err := audit.NewFileSink(cfg.AuditFile)
+		if err != nil {
+			return err
+		}
+		d.auditLog = fileSink
+	}
+	return nil
+}
+
+func newReposService(cfg config.Config, registry *providers.Registry, deps dependencies) services.ReposServiceInterface {
+	return services.NewRepositoryService(services.ReposDependencies{
+		Providers:  registry,
+		QuotaStore: deps.quotaStore,
+		DailyQuota: cfg.DailyRepoQuota,
+		AuditLog:   deps.auditLog,
+		DeletePolicy: services.DeletePolicy{
+			AllowedOrgs:         cfg.DeleteAllowedOrgs,
+			TopicMarker:         cfg.DeleteTopicMarker,
+			ArchiveOnly:         cfg.DeleteArchiveOnly,
+			RequireConfirmation: cfg.DeleteRequireConfirmation,
+		},
+		Templates:         deps.templates,
+		Secrets:           deps.secrets,
+		Protection:        deps.protection,
+		DefaultProtection: cfg.DefaultProtectionPolicy,
+		Labels:            deps.labels,
+	})
+}
+
+// NewReposService builds the repository service the way New does, for tools
+// running it in process. The caller closes the returned audit log.
+func NewReposService(cfg config.Config, options ...Option) (services.ReposServiceInterface, audit.Log, error) {
+	deps := dependencies{providers: make(map[string]providers.RepoProvider)}
+	for _, option := range options {
+		option(&deps)
+	}
+
+	registry, err := newRegistry(cfg, deps.providers)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := deps.load(cfg); err != nil {
+		return nil, nil, err
+	}
+	return newReposService(cfg, registry, deps), deps.auditLog, nil
+}
+
+func New(cfg config.Config, options ...Option) (*Application, error) {
+	for _, limit := range []ratelimit.Limit{cfg.CallerRateLimit(), cfg.IpRateLimit()} {
+		if err := limit.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	deps := dependencies{providers: make(map[string]providers.RepoProvider)}
+	for _, option := range options {
+		option(&deps)
+	}
+
+	registry, err := newRegistry(cfg, deps.providers)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticator, err := auth.NewAuthenticator(auth.Options{
+		ApiKeysFile: cfg.AuthKeysFile,
+		JwtSecret:   cfg.AuthJwtSecret,
+		JwksFile:    cfg.AuthJwksFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := deps.load(cfg); err != nil {
+		return nil, err
+	}
+	document, err := openapi.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// without trusted proxies the client ip is the peer address, forwarded
+	// headers would let any client pick its own rate limit bucket.
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	application := &Application{
+		router:    router,
+		inFlight:  &inFlightRequests{},
+		auditSink: deps.auditLog,
+	}
+	checks := []health.Check{services.NewConfigCheck(cfg)}
+	for _, name := range registry.Names() {
+		if checker, ok := registry.Get(name).(providers.CredentialsChecker); ok {
+			checks = append(checks, services.NewProviderTokenCheck(name, checker))
+		}
+	}
+	checks = append(checks,
+		health.NewCheck("audit_sink", deps.auditLog.Ping),
+		health.NewCheck("rate_limit_store", deps.rateLimitStore.Ping),
+	)
+	application.health = services.NewHealthService(checks...)
+
+	application.router.Use(
+		gin.Recovery(),
+		middlewares.RequestId(),
+		application.inFlight.middleware(),
+		middlewares.AccessLog(),
+		middlewares.Metrics(),
+		middlewares.Tracing(),
+	)
+	application.mapUrls(cfg, authenticator, deps.rateLimitStore, openapi.NewValidator(document),
+		newReposService(cfg, registry, deps),
+		services.NewAuditService(deps.auditLog),
+	)
+
+	return application, nil
+}
+
+// newRegistry registers every enabled provider, overrides take precedence over
+// the clients built from the configuration.
+func newRegistry(cfg config.Config, overrides map[string]providers.RepoProvider) (*providers.Registry, error) {
+	registry := providers.NewRegistry(cfg.DefaultProvider, cfg.OrgProviders)
+	for name, provider := range overrides {
+		registry.Register(name, provider)
+	}
+
+	for _, name := range cfg.EnabledProviders() {
+		if overrides[name] != nil {
+			continue
+		}
+		// each provider trusts its own CA bundle, hosts of one are not
+		// vouched for by the bundle of another.
+		client, err := restclient.NewClient(cfg.CaBundle(name))
+		if err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case providers.Github:
+			registry.Register(name, github_provider.NewRepoProvider(
+				github_provider.New(client, cfg.GetGithubBaseUrl(), cfg.GithubApiVersion, cfg.GithubAccessToken),
+			))
+		case providers.Gitlab:
+			registry.Register(name, gitlab_provider.New(client, cfg.GetGitlabBaseUrl(), cfg.GitlabAccessToken))
+		case providers.Gitea:
+			registry.Register(name, gitea_provider.New(client, cfg.GetGiteaBaseUrl(), cfg.GiteaAccessToken))
+		}
+	}
+
+	return registry, nil
+}
+
+func (a *Application) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.router.ServeHTTP(w, r)
+}
+
+// Close closes the audit sink once the in-flight handlers finished, or after
+// cancelGracePeriod if some are stuck.
+func (a *Application) Close() error {
+	if !a.inFlight.wait(cancelGracePeriod) {
+		logger.Default().Warn("closing the audit log while requests are still in flight",
+			logger.Any("remaining", a.inFlight.count()),
+		)
+		a.inFlight.log(logger.Default(), "request still in flight")
+	}
+	return a.auditSink.Close()
+}
+
+func fatal(message string, err error) {
+	logger.Default().Error(message, logger.Err(err))
+	os.Exit(1)
 }
 
 func StartApp() {
-	mapUrls()
+	cfg := config.Load()
+	logger.SetDefault(logger.New(os.Stdout, cfg.GetLogLevel()))
+
+	switch cfg.TracingExporter {
+	case "":
+	case tracingExporterStdout:
+		tracing.Default().SetExporter(tracing.NewStdoutExporter(os.Stdout))
+	default:
+		logger.Default().Warn("unknown tracing exporter, tracing is disabled",
+			logger.Any("exporter", cfg.TracingExporter),
+		)
+	}
+
+	application, err := New(cfg)
+	if err != nil {
+		fatal("error when building application", err)
+	}
+
+	listener, err := net.Listen("tcp", cfg.GetListenAddr())
+	if err != nil {
+		fatal("error when listening for http requests", err)
+	}
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	options := shutdownOptions{timeout: cfg.ShutdownTimeout, drainDelay: cfg.ShutdownDrainDelay}
+	if err := runServer(signals, listener, application, options); err != nil && err != http.ErrServerClosed {
+		logger.Default().Error("error when running http server", logger.Err(err))
+	}
 
-	log.Fatal(router.Run(""))
+	if err := application.Close(); err != nil {
+		logger.Default().Error("error when flushing audit log", logger.Err(err))
+	}
+	if err := tracing.Default().Shutdown(); err != nil {
+		logger.Default().Error("error when flushing traces", logger.Err(err))
+	}
 }
@@ -0,0 +1,494 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/config"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/health"
+	"golang-microservices/src/api/openapi"
+	"golang-microservices/src/api/protection"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/providers/providertest"
+	"golang-microservices/src/api/secrets"
+	"golang-microservices/src/api/templates"
+	"golang-microservices/src/api/utils/errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestApplication(t *testing.T, provider *providertest.Provider, auditLog audit.Log, options ...Option) *httptest.Server {
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	assert.Nil(t, ioutil.WriteFile(keysFile, []byte(`[
+		{"id": "team-a", "key": "key-a", "scopes": ["repos:create", "repos:batch", "repos:read", "repos:update", "repos:delete", "repos:transfer", "repos:access", "repos:reconcile", "audit:read"], "orgs": ["my-org", "new-org"]},
+		{"id": "team-b", "key": "key-b", "scopes": ["repos:create"], "orgs": ["*"]}
+	]`), 0600))
+
+	cfg := config.Default()
+	cfg.GithubAccessToken = "abc123"
+	cfg.AuthKeysFile = keysFile
+
+	options = append(options, WithProvider(providers.Github, provider), WithAuditLog(auditLog))
+	application, err := New(cfg, options...)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(application)
+	t.Cleanup(func() {
+		server.Close()
+		_ = application.Close()
+	})
+	return server
+}
+
+func get(t *testing.T, url string, apiKey string) *http.Response {
+	request, _ := http.NewRequest(http.MethodGet, url, nil)
+	request.Header.Set("X-Api-Key", apiKey)
+	response, err := http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	return response
+}
+
+func post(t *testing.T, url string, apiKey string, body string) *http.Response {
+	return send(t, http.MethodPost, url, apiKey, body)
+}
+
+func patch(t *testing.T, url string, apiKey string, body string) *http.Response {
+	return send(t, http.MethodPatch, url, apiKey, body)
+}
+
+func send(t *testing.T, method string, url string, apiKey string, body string, headers ...string) *http.Response {
+	request, _ := http.NewRequest(method, url, strings.NewReader(body))
+	request.Header.Set("X-Api-Key", apiKey)
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+	response, err := http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	return response
+}
+
+func TestNewInvalidApiKeysFile(t *testing.T) {
+	cfg := config.Default()
+	cfg.AuthKeysFile = filepath.Join(t.TempDir(), "missing.json")
+
+	application, err := New(cfg, WithProvider(providers.Github, providertest.New()), WithAuditLog(audit.NewMemorySink()))
+	assert.Nil(t, application)
+	assert.NotNil(t, err)
+}
+
+func TestNewUsesCaBundleOfEachProvider(t *testing.T) {
+	cfg := config.Default()
+	cfg.AuthKeysFile = filepath.Join(t.TempDir(), "keys.json")
+	assert.Nil(t, ioutil.WriteFile(cfg.AuthKeysFile, []byte(`[]`), 0600))
+	cfg.GitlabBaseUrl = "https://gitlab.corp"
+	cfg.GitlabCaBundle = filepath.Join(t.TempDir(), "missing.pem")
+
+	application, err := New(cfg, WithProvider(providers.Github, providertest.New()), WithAuditLog(audit.NewMemorySink()))
+	assert.Nil(t, application)
+	assert.NotNil(t, err)
+
+	application, err = New(cfg, WithProvider(providers.Gitlab, providertest.New()), WithAuditLog(audit.NewMemorySink()))
+	assert.NotNil(t, application)
+	assert.Nil(t, err)
+}
+
+func TestNewInvalidRateLimit(t *testing.T) {
+	cfg := config.Default()
+	cfg.AuthKeysFile = filepath.Join(t.TempDir(), "keys.json")
+	cfg.RateLimitIpRps = 0
+
+	application, err := New(cfg, WithProvider(providers.Github, providertest.New()), WithAuditLog(audit.NewMemorySink()))
+	assert.Nil(t, application)
+	assert.EqualValues(t, "rate limit must be positive", err.Error())
+}
+
+func TestUnauthenticatedRequestsAreRateLimited(t *testing.T) {
+	server := newTestApplication(t, providertest.New(providertest.WithOwner("my-org")), audit.NewMemorySink())
+
+	var response *http.Response
+	for i := 0; i <= config.Default().RateLimitIpBurst; i++ {
+		response = get(t, server.URL+"/repositories", "wrong-key")
+	}
+	assert.EqualValues(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.NotEmpty(t, response.Header.Get("Retry-After"))
+}
+
+func TestForwardedForDoesNotChangeIpKey(t *testing.T) {
+	server := newTestApplication(t, providertest.New(providertest.WithOwner("my-org")), audit.NewMemorySink())
+
+	var response *http.Response
+	for i := 0; i <= config.Default().RateLimitIpBurst; i++ {
+		response = send(t, http.MethodGet, server.URL+"/repositories", "wrong-key", "",
+			"X-Forwarded-For", fmt.Sprintf("10.0.0.%d", i), "X-Real-Ip", fmt.Sprintf("10.0.1.%d", i))
+	}
+	assert.EqualValues(t, http.StatusTooManyRequests, response.StatusCode)
+}
+
+func TestCreateRepoEndToEnd(t *testing.T) {
+	provider := providertest.New(providertest.WithOwner("LeJeksey"))
+	auditLog := audit.NewMemorySink()
+	server := newTestApplication(t, provider, auditLog)
+
+	response := post(t, server.URL+"/repository", "key-a", `{"name": "testing_repo", "org": "my-org"}`)
+	defer response.Body.Close()
+
+	var result repositories.CreateRepoResponse
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+	assert.EqualValues(t, "my-org/testing_repo", result.FullName)
+	assert.EqualValues(t, []string{"my-org/testing_repo"}, provider.FullNames())
+
+	entries, _ := auditLog.Query(repositories.AuditFilter{})
+	assert.EqualValues(t, 1, len(entries))
+	assert.EqualValues(t, "team-a", entries[0].Caller)
+}
+
+func TestCreateRepoEndToEndErrors(t *testing.T) {
+	server := newTestApplication(t, providertest.New(providertest.WithOwner("LeJeksey")), audit.NewMemorySink())
+
+	response := post(t, server.URL+"/repository", "", `{"name": "testing_repo"}`)
+	assert.EqualValues(t, http.StatusUnauthorized, response.StatusCode)
+
+	response = post(t, server.URL+"/repositories", "key-b", `[{"name": "testing_repo"}]`)
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+
+	response = post(t, server.URL+"/repository", "key-b", `{"name": "existing"}`)
+	body, _ := ioutil.ReadAll(response.Body)
+	apiErr, _ := errors.NewApiErrorFromBytes(body)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, response.StatusCode)
+	assert.EqualValues(t, "Repository creation failed.", apiErr.Message())
+}
+
+func TestApplicationsAreIsolated(t *testing.T) {
+	first := providertest.New(providertest.WithOwner("first"))
+	second := providertest.New(providertest.WithOwner("second"))
+	firstServer := newTestApplication(t, first, audit.NewMemorySink())
+	secondServer := newTestApplication(t, second, audit.NewMemorySink())
+
+	response := post(t, firstServer.URL+"/repositories", "key-a", `[{"name": "one", "org": "my-org"}, {"name": "two", "org": "my-org"}]`)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+	response = post(t, secondServer.URL+"/repository", "key-b", `{"name": "three"}`)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+
+	assert.EqualValues(t, 2, len(first.FullNames()))
+	assert.EqualValues(t, []string{"second/three"}, second.FullNames())
+}
+
+func TestReadyzReportsEveryDependency(t *testing.T) {
+	server := newTestApplication(t, providertest.New(providertest.WithOwner("LeJeksey")), audit.NewMemorySink())
+
+	response, err := http.Get(server.URL + "/readyz")
+	assert.Nil(t, err)
+	defer response.Body.Close()
+
+	var report health.Report
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&report))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+
+	names := make([]string, 0)
+	for _, check := range report.Checks {
+		names = append(names, check.Name)
+	}
+	assert.EqualValues(t, []string{"shutdown", "config", "github_token", "audit_sink", "rate_limit_store"}, names)
+}
+
+func TestCreateRepoWithExplicitProvider(t *testing.T) {
+	github := providertest.New(providertest.WithName(providers.Github), providertest.WithOwner("LeJeksey"))
+	gitlab := providertest.New(providertest.WithName(providers.Gitlab), providertest.WithOwner("platform"))
+	server := newTestApplication(t, github, audit.NewMemorySink(), WithProvider(providers.Gitlab, gitlab))
+
+	response := post(t, server.URL+"/repository", "key-b", `{"name": "testing_repo", "provider": "gitlab"}`)
+	defer response.Body.Close()
+
+	var result repositories.CreateRepoResponse
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+	assert.EqualValues(t, "gitlab", result.Provider)
+	assert.EqualValues(t, []string{"platform/testing_repo"}, gitlab.FullNames())
+	assert.Empty(t, github.FullNames())
+
+	response = post(t, server.URL+"/repository", "key-b", `{"name": "testing_repo", "provider": "bitbucket"}`)
+	assert.EqualValues(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestGetAndListReposEndToEnd(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/one", "my-org/two"), providertest.WithPageSize(1))
+	server := newTestApplication(t, provider, audit.NewMemorySink())
+
+	response := get(t, server.URL+"/repository/my-org/two", "key-a")
+	var repo repositories.Repository
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&repo))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, "my-org/two", repo.FullName)
+
+	response = get(t, server.URL+"/repository/my-org/missing", "key-a")
+	assert.EqualValues(t, http.StatusNotFound, response.StatusCode)
+
+	response = get(t, server.URL+"/repository/other-org/one", "key-a")
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+
+	response = get(t, server.URL+"/repositories?owner=my-org", "key-a")
+	var page repositories.RepositoryPage
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&page))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, "my-org/one", page.Repositories[0].FullName)
+	assert.EqualValues(t, `</repositories?owner=my-org&page=2>; rel="next", </repositories?owner=my-org&page=2>; rel="last"`,
+		response.Header.Get("Link"))
+
+	response = get(t, server.URL+"/repositories?owner=my-org", "key-b")
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+}
+
+func TestUpdateRepoEndToEnd(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/one"))
+	server := newTestApplication(t, provider, audit.NewMemorySink())
+
+	response := patch(t, server.URL+"/repository/my-org/one", "key-a", `{"name": "renamed", "archived": true}`)
+	var repo repositories.Repository
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&repo))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, "my-org/renamed", repo.FullName)
+	assert.True(t, repo.Archived)
+
+	response = patch(t, server.URL+"/repository/my-org/renamed", "key-a", `{}`)
+	assert.EqualValues(t, http.StatusBadRequest, response.StatusCode)
+
+	response = patch(t, server.URL+"/repository/my-org/missing", "key-a", `{"archived": false}`)
+	assert.EqualValues(t, http.StatusNotFound, response.StatusCode)
+
+	response = patch(t, server.URL+"/repository/my-org/renamed", "key-b", `{"archived": false}`)
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+}
+
+func TestDeleteRepoEndToEnd(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/keep"))
+	server := newTestApplication(t, provider, audit.NewMemorySink())
+
+	for _, name := range []string{"ci-one", "ci-two"} {
+		response := post(t, server.URL+"/repository", "key-a", `{"name": "`+name+`", "org": "my-org"}`)
+		assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+	}
+
+	response := send(t, http.MethodDelete, server.URL+"/repository/my-org/ci-one", "key-a", "")
+	assert.EqualValues(t, http.StatusPreconditionRequired, response.StatusCode)
+
+	response = send(t, http.MethodDelete, server.URL+"/repository/my-org/ci-one", "key-a", "", "X-Confirm-Delete", "my-org/ci-one")
+	var res repositories.DeleteRepoResponse
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&res))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, repositories.ActionDeleted, res.Action)
+
+	response = send(t, http.MethodDelete, server.URL+"/repository/my-org/keep", "key-a", "", "X-Confirm-Delete", "my-org/keep")
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+
+	response = send(t, http.MethodDelete, server.URL+"/repositories?owner=my-org&prefix=ci-", "key-a", "", "X-Confirm-Delete", "my-org/ci-*")
+	var batch repositories.DeleteReposResponse
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&batch))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, 1, len(batch.Results))
+	assert.EqualValues(t, "my-org/ci-two", batch.Results[0].Response.FullName)
+	assert.EqualValues(t, []string{"my-org/keep"}, provider.FullNames())
+
+	response = send(t, http.MethodDelete, server.URL+"/repository/my-org/keep", "key-b", "", "X-Confirm-Delete", "my-org/keep")
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+}
+
+func TestTransferRepoEndToEnd(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/one"))
+	server := newTestApplication(t, provider, audit.NewMemorySink())
+
+	response := post(t, server.URL+"/repository/my-org/one/transfer", "key-a", `{"new_owner": "other-org"}`)
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+
+	response = post(t, server.URL+"/repository/my-org/one/transfer", "key-a", `{"new_owner": "my-org"}`)
+	assert.EqualValues(t, http.StatusBadRequest, response.StatusCode)
+
+	response = post(t, server.URL+"/repository/my-org/one/transfer", "key-a", `{"new_owner": "new-org", "new_name": "moved"}`)
+	var repo repositories.Repository
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&repo))
+	assert.EqualValues(t, http.StatusAccepted, response.StatusCode)
+	assert.EqualValues(t, "new-org/moved", repo.FullName)
+	assert.EqualValues(t, []string{"new-org/moved"}, provider.FullNames())
+}
+
+func TestCreateRepoWithTemplatesEndToEnd(t *testing.T) {
+	store := templates.NewStore()
+	assert.Nil(t, store.Add("go-service", "CODEOWNERS", "* @{{.Owner}}/{{.Team}}"))
+	provider := providertest.New()
+	server := newTestApplication(t, provider, audit.NewMemorySink(), WithTemplates(store))
+
+	response := post(t, server.URL+"/repository", "key-a", `{"name": "payments", "org": "my-org", "team": "core", "templates": ["go-service"]}`)
+	var res repositories.CreateRepoResponse
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&res))
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+	assert.EqualValues(t, []string{"CODEOWNERS"}, res.Files)
+	assert.EqualValues(t, []string{"my-org/payments/CODEOWNERS: * @my-org/core"}, provider.Committed())
+
+	response = post(t, server.URL+"/repository", "key-a", `{"name": "billing", "org": "my-org", "templates": ["missing"]}`)
+	assert.EqualValues(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestCreateRepoWithWebhooksEndToEnd(t *testing.T) {
+	sink := audit.NewMemorySink()
+	provider := providertest.New()
+	server := newTestApplication(t, provider, sink, WithSecrets(secrets.NewScopedStore(
+		map[string]string{"ci-hook": "s3cr3t"},
+		map[string]secrets.Scope{"ci-hook": {Orgs: []string{"my-org"}}},
+	)))
+
+	response := post(t, server.URL+"/repository", "key-a",
+		`{"name": "payments", "org": "my-org", "webhooks": [{"url": "https://ci.example.com/hooks/payments", "secret": "ci-hook"}]}`)
+	body, _ := ioutil.ReadAll(response.Body)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+	assert.NotContains(t, string(body), "s3cr3t")
+	assert.EqualValues(t, []string{"my-org/payments https://ci.example.com/hooks/payments s3cr3t"}, provider.Hooks())
+
+	entries, _ := sink.Query(repositories.AuditFilter{Owner: "my-org"})
+	assert.EqualValues(t, "https://ci.example.com", entries[0].Request.Webhooks[0].Url)
+
+	response = post(t, server.URL+"/repository", "key-a",
+		`{"name": "billing", "org": "my-org", "webhooks": [{"url": "https://ci.example.com/hooks", "secret": "missing"}]}`)
+	assert.EqualValues(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestGrantAccessEndToEnd(t *testing.T) {
+	provider := providertest.New()
+	server := newTestApplication(t, provider, audit.NewMemorySink())
+
+	response := send(t, http.MethodPut, server.URL+"/repository/my-org/payments/access", "key-a",
+		`{"teams": [{"slug": "Core", "permission": "maintain"}], "collaborators": [{"username": "octocat"}]}`)
+	var res repositories.AccessResponse
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&res))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, "core", res.Granted[0].Name)
+	assert.EqualValues(t, "octocat", res.Invited[0].Name)
+	assert.EqualValues(t, []string{"my-org/payments team core maintain", "my-org/payments collaborator octocat pull"}, provider.Access())
+
+	response = send(t, http.MethodPut, server.URL+"/repository/my-org/payments/access", "key-b", `{"teams": [{"slug": "core"}]}`)
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+}
+
+func TestReconcileEndToEnd(t *testing.T) {
+	provider := providertest.New()
+	server := newTestApplication(t, provider, audit.NewMemorySink())
+	manifest := "owner: my-org\nrepositories:\n  - name: payments\n    description: Payments API\n"
+
+	var res repositories.ReconcileResponse
+	response := send(t, http.MethodPost, server.URL+"/reconcile?plan=true", "key-a", manifest)
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&res))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, []repositories.ReconcileAction{{Action: repositories.ReconcileCreate, Owner: "my-org", Name: "payments"}}, res.Actions)
+	assert.Empty(t, provider.FullNames())
+
+	response = send(t, http.MethodPost, server.URL+"/reconcile", "key-a", manifest)
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&res))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, http.StatusCreated, res.Actions[0].Status)
+	assert.EqualValues(t, []string{"my-org/payments"}, provider.FullNames())
+
+	manifest = strings.Replace(manifest, "Payments API", "Payments service", 1)
+	response = send(t, http.MethodPost, server.URL+"/reconcile?plan=true", "key-a", manifest)
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&res))
+	assert.EqualValues(t, 1, len(res.Actions))
+	assert.EqualValues(t, repositories.ReconcileUpdate, res.Actions[0].Action)
+	assert.EqualValues(t, []string{`description: "Payments API" -> "Payments service"`}, res.Actions[0].Changes)
+
+	response = send(t, http.MethodPost, server.URL+"/reconcile", "key-b", manifest)
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+}
+
+func TestBranchProtectionEndToEnd(t *testing.T) {
+	provider := providertest.New()
+	store := protection.NewStore(map[string]repositories.ProtectionPolicy{
+		"strict": {BranchProtection: repositories.BranchProtection{RequiredReviews: 2, BlockForcePushes: true}},
+	})
+	server := newTestApplication(t, provider, audit.NewMemorySink(), WithProtectionPolicies(store))
+
+	var report repositories.ProtectionReport
+	response := get(t, server.URL+"/repository/my-org/payments/protection?policy=strict&branch=main", "key-a")
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&report))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.False(t, report.InSync)
+	assert.EqualValues(t, 2, len(report.Drift))
+
+	response = send(t, http.MethodPut, server.URL+"/repository/my-org/payments/protection", "key-a", `{"policy": "strict", "branch": "main"}`)
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+
+	response = get(t, server.URL+"/repository/my-org/payments/protection?policy=strict&branch=main", "key-a")
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&report))
+	assert.True(t, report.InSync)
+	assert.EqualValues(t, []repositories.ProtectionDrift{}, report.Drift)
+}
+
+func TestNewUnknownDefaultProtectionPolicy(t *testing.T) {
+	cfg := config.Default()
+	cfg.AuthJwtSecret = "jwt-secret"
+	cfg.DefaultProtectionPolicy = "strict"
+
+	_, err := New(cfg, WithAuditLog(audit.NewMemorySink()), WithProtectionPolicies(protection.NewStore(nil)))
+	assert.EqualValues(t, "unknown default protection policy strict", err.Error())
+}
+
+// TestOpenApiCoversRoutes fails when a route is added to or removed from
+// mapUrls without the route table of the openapi package.
+func TestOpenApiCoversRoutes(t *testing.T) {
+	cfg := config.Default()
+	cfg.AuthJwtSecret = "jwt-secret"
+	application, err := New(cfg, WithProvider(providers.Github, providertest.New()), WithAuditLog(audit.NewMemorySink()))
+	assert.Nil(t, err)
+
+	document, err := openapi.Load()
+	assert.Nil(t, err)
+
+	routes := make(map[string]bool)
+	for _, route := range application.router.Routes() {
+		path := openapi.PathTemplate(route.Path)
+		routes[route.Method+" "+path] = true
+		assert.NotNil(t, document.Operation(route.Method, path), "%s %s is missing from openapi.json", route.Method, path)
+	}
+	for path, item := range document.Paths {
+		for method := range item {
+			assert.True(t, routes[strings.ToUpper(method)+" "+path], "%s %s of openapi.json is not routed", method, path)
+		}
+	}
+}
+
+func TestOpenApiEndToEnd(t *testing.T) {
+	provider := providertest.New()
+	server := newTestApplication(t, provider, audit.NewMemorySink())
+
+	response := get(t, server.URL+"/openapi.json", "")
+	var document openapi.Document
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&document))
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.NotNil(t, document.Operation(http.MethodPost, "/reconcile"))
+
+	response = get(t, server.URL+"/docs", "")
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.True(t, strings.HasPrefix(response.Header.Get("Content-Type"), "text/html"))
+
+	response = post(t, server.URL+"/repository", "key-a", `{"name": "testing_repo", "org": "my-org", "description": 12}`)
+	body, _ := ioutil.ReadAll(response.Body)
+	apiErr, _ := errors.NewApiErrorFromBytes(body)
+	assert.EqualValues(t, http.StatusBadRequest, response.StatusCode)
+	assert.EqualValues(t, "invalid request body: description: expected string", apiErr.Message())
+	assert.EqualValues(t, 0, len(provider.FullNames()))
+
+	response = get(t, server.URL+"/repositories?owner=my-org&per_page=1000", "key-a")
+	body, _ = ioutil.ReadAll(response.Body)
+	apiErr, _ = errors.NewApiErrorFromBytes(body)
+	assert.EqualValues(t, http.StatusBadRequest, response.StatusCode)
+	assert.EqualValues(t, "invalid query parameter per_page: expected at most 100", apiErr.Message())
+
+	response = post(t, server.URL+"/repositories", "key-b", `[{"name": 12}]`)
+	assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+
+	response = post(t, server.URL+"/repository", "key-a", `{"name": "testing_repo", "org": "my-org", "colour": "red"}`)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/utils/logger"
+	"golang-microservices/src/api/utils/requestid"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const cancelGracePeriod = 5 * time.Second
+
+type inFlightRequest struct {
+	method    string
+	path      string
+	requestId string
+}
+
+// inFlightRequests tracks the requests being served, the ones still running
+// at the shutdown deadline are logged so an operator knows what was cut off.
+type inFlightRequests struct {
+	wg       sync.WaitGroup
+	mutex    sync.Mutex
+	requests map[*inFlightRequest]struct{}
+}
+
+// middleware runs after the request id middleware, the tracked request
+// carries the id the access log reports.
+func (r *inFlightRequests) middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := &inFlightRequest{
+			method:    ctx.Request.Method,
+			path:      ctx.Request.URL.Path,
+			requestId: requestid.FromContext(ctx.Request.Context()),
+		}
+		r.wg.Add(1)
+		r.mutex.Lock()
+		if r.requests == nil {
+			r.requests = map[*inFlightRequest]struct{}{}
+		}
+		r.requests[request] = struct{}{}
+		r.mutex.Unlock()
+		defer func() {
+			r.mutex.Lock()
+			delete(r.requests, request)
+			r.mutex.Unlock()
+			r.wg.Done()
+		}()
+
+		ctx.Next()
+	}
+}
+
+func (r *inFlightRequests) snapshot() []inFlightRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	result := make([]inFlightRequest, 0, len(r.requests))
+	for request := range r.requests {
+		result = append(result, *request)
+	}
+	return result
+}
+
+func (r *inFlightRequests) count() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.requests)
+}
+
+func (r *inFlightRequests) log(log logger.Logger, message string) {
+	for _, request := range r.snapshot() {
+		log.Warn(message,
+			logger.Any("method", request.method),
+			logger.Any("path", request.path),
+			logger.Any("request_id", request.requestId),
+		)
+	}
+}
+
+func (r *inFlightRequests) wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+type shutdownOptions struct {
+	timeout    time.Duration
+	drainDelay time.Duration
+}
+
+func runServer(signals context.Context, listener net.Listener, application *Application, options shutdownOptions) error {
+	inFlight := application.inFlight
+
+	baseCtx, cancelInFlight := context.WithCancel(context.Background())
+	defer cancelInFlight()
+
+	server := &http.Server{
+		Handler:     application,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(listener)
+	}()
+
+	logger.Default().Info("http server started", logger.Any("addr", listener.Addr().String()))
+
+	select {
+	case err := <-errs:
+		return err
+	case <-signals.Done():
+	}
+
+	log := logger.Default()
+	log.Info("shutdown requested, draining in-flight requests",
+		logger.Any("in_flight", inFlight.count()),
+		logger.Any("timeout", options.timeout.String()),
+	)
+	application.health.SetShuttingDown()
+	time.Sleep(options.drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), options.timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Warn("in-flight requests did not finish before the shutdown deadline, cancelling them",
+			logger.Any("cancelled", inFlight.count()),
+		)
+		inFlight.log(log, "cancelling in-flight request")
+		cancelInFlight()
+		if !inFlight.wait(cancelGracePeriod) {
+			log.Error("cancelled requests did not finish, exiting anyway",
+				logger.Any("remaining", inFlight.count()),
+			)
+			inFlight.log(log, "cancelled request did not finish")
+		}
+	}
+
+	log.Info("http server stopped")
+	return nil
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/middlewares"
+	"golang-microservices/src/api/services"
+	"golang-microservices/src/api/utils/logger"
+	"golang-microservices/src/api/utils/requestid"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startTestServer(t *testing.T, handler gin.HandlerFunc, timeout time.Duration) (*Application, string, context.CancelFunc, <-chan error) {
+	application := &Application{
+		router:   gin.New(),
+		inFlight: &inFlightRequests{},
+		health:   services.NewHealthService(),
+	}
+	application.router.Use(middlewares.RequestId(), application.inFlight.middleware())
+	application.router.GET("/slow", handler)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	signals, stop := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runServer(signals, listener, application, shutdownOptions{timeout: timeout})
+	}()
+
+	return application, "http://" + listener.Addr().String(), stop, done
+}
+
+func TestRunServerFinishesInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	application, url, stop, done := startTestServer(t, func(ctx *gin.Context) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		ctx.String(http.StatusOK, "finished")
+	}, time.Second)
+
+	responses := make(chan string, 1)
+	go func() {
+		response, err := http.Get(url + "/slow")
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(response.Body)
+		responses <- string(body)
+	}()
+
+	<-started
+	stop()
+
+	assert.EqualValues(t, "finished", <-responses)
+	assert.Nil(t, <-done)
+	assert.False(t, application.health.Readiness(context.Background()).Ok())
+}
+
+func TestRunServerCancelsRequestsAfterDeadline(t *testing.T) {
+	var buffer bytes.Buffer
+	previous := logger.Default()
+	logger.SetDefault(logger.New(&buffer, logger.LevelInfo))
+	defer logger.SetDefault(previous)
+
+	started := make(chan struct{})
+	_, url, stop, done := startTestServer(t, func(ctx *gin.Context) {
+		close(started)
+		select {
+		case <-ctx.Request.Context().Done():
+			ctx.String(http.StatusServiceUnavailable, "cancelled")
+		case <-time.After(5 * time.Second):
+			ctx.String(http.StatusOK, "finished")
+		}
+	}, 50*time.Millisecond)
+
+	responses := make(chan string, 1)
+	go func() {
+		request, _ := http.NewRequest(http.MethodGet, url+"/slow", nil)
+		request.Header.Set(requestid.Header, "req-42")
+		response, err := http.DefaultClient.Do(request)
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(response.Body)
+		responses <- string(body)
+	}()
+
+	<-started
+	stop()
+
+	assert.EqualValues(t, "cancelled", <-responses)
+	assert.Nil(t, <-done)
+
+	cancelled := false
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		var entry map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "cancelling in-flight request" {
+			cancelled = true
+			assert.EqualValues(t, http.MethodGet, entry["method"])
+			assert.EqualValues(t, "/slow", entry["path"])
+			assert.EqualValues(t, "req-42", entry["request_id"])
+		}
+	}
+	assert.True(t, cancelled)
+}
+
+type closeRecorder struct {
+	*audit.MemorySink
+	closed chan struct{}
+}
+
+func (s *closeRecorder) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestCloseWaitsForInFlightHandlers(t *testing.T) {
+	sink := &closeRecorder{MemorySink: audit.NewMemorySink(), closed: make(chan struct{})}
+	started := make(chan struct{})
+	application, url, _, _ := startTestServer(t, func(ctx *gin.Context) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		select {
+		case <-sink.closed:
+			ctx.String(http.StatusInternalServerError, "closed")
+		default:
+			ctx.String(http.StatusOK, "finished")
+		}
+	}, time.Second)
+	application.auditSink = sink
+
+	responses := make(chan string, 1)
+	go func() {
+		response, err := http.Get(url + "/slow")
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(response.Body)
+		responses <- string(body)
+	}()
+
+	<-started
+	assert.Nil(t, application.Close())
+	assert.EqualValues(t, "finished", <-responses)
+}
@@ -1,12 +1,53 @@
 package app
 
 import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/config"
+	"golang-microservices/src/api/controllers/audit"
+	"golang-microservices/src/api/controllers/docs"
+	"golang-microservices/src/api/controllers/health"
 	"golang-microservices/src/api/controllers/marcopolo"
 	"golang-microservices/src/api/controllers/repositories"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/middlewares"
+	"golang-microservices/src/api/openapi"
+	"golang-microservices/src/api/ratelimit"
+	"golang-microservices/src/api/services"
 )
 
-func mapUrls() {
-	router.GET("/marco", marcopolo.Marco)
-	router.POST("/repository", repositories.CreateRepo)
-	router.POST("/repositories", repositories.CreateRepos)
+func (a *Application) mapUrls(cfg config.Config, authenticator *auth.Authenticator, rateLimitStore ratelimit.Store,
+	validator *openapi.Validator, reposService services.ReposServiceInterface, auditService services.AuditServiceInterface) {
+	healthController := health.NewController(a.health)
+	reposController := repositories.NewController(reposService)
+	auditController := audit.NewController(auditService)
+
+	a.router.GET("/marco", marcopolo.Marco)
+	a.router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	a.router.GET("/healthz", healthController.Healthz)
+	a.router.GET("/readyz", healthController.Readyz)
+	a.router.GET("/openapi.json", docs.Spec)
+	a.router.GET("/docs", docs.Page)
+
+	api := a.router.Group("/",
+		middlewares.RateLimitByIp(rateLimitStore, cfg.IpRateLimit()),
+		middlewares.Authenticate(authenticator),
+		middlewares.RateLimit(rateLimitStore, cfg.CallerRateLimit()),
+	)
+	validate := middlewares.ValidateRequest(validator)
+	quota := middlewares.QuotaHeaders()
+	api.POST("/repository", middlewares.RequireScope(auth.ScopeReposCreate), validate, quota, reposController.CreateRepo)
+	api.POST("/repositories", middlewares.RequireScope(auth.ScopeReposBatch), validate, quota, reposController.CreateRepos)
+	api.GET("/repository/:owner/:name", middlewares.RequireScope(auth.ScopeReposRead), validate, reposController.GetRepo)
+	api.PATCH("/repository/:owner/:name", middlewares.RequireScope(auth.ScopeReposUpdate), validate, reposController.UpdateRepo)
+	api.DELETE("/repository/:owner/:name", middlewares.RequireScope(auth.ScopeReposDelete), validate, reposController.DeleteRepo)
+	api.POST("/repository/:owner/:name/transfer", middlewares.RequireScope(auth.ScopeReposTransfer), validate, reposController.TransferRepo)
+	api.GET("/repository/:owner/:name/protection", middlewares.RequireScope(auth.ScopeReposRead), validate, reposController.GetProtection)
+	api.PUT("/repository/:owner/:name/protection", middlewares.RequireScope(auth.ScopeReposUpdate), validate, reposController.ApplyProtection)
+	api.POST("/repository/:owner/:name/sync", middlewares.RequireScope(auth.ScopeReposUpdate), validate, reposController.SyncRepo)
+	api.PUT("/repository/:owner/:name/access", middlewares.RequireScope(auth.ScopeReposAccess), validate, reposController.GrantAccess)
+	api.GET("/repositories", middlewares.RequireScope(auth.ScopeReposRead), validate, reposController.ListRepos)
+	api.DELETE("/repositories", middlewares.RequireScope(auth.ScopeReposDelete), validate, reposController.DeleteRepos)
+	api.POST("/reconcile", middlewares.RequireScope(auth.ScopeReposReconcile), validate, quota, reposController.Reconcile)
+	api.GET("/audit", middlewares.RequireScope(auth.ScopeAuditRead), validate, auditController.GetAudit)
 }
@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/repositories"
+)
+
+type Sink interface {
+	Record(entry repositories.AuditEntry) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+type Reader interface {
+	Query(filter repositories.AuditFilter) ([]repositories.AuditEntry, error)
+}
+
+type Log interface {
+	Sink
+	Reader
+}
+
+type batchIdKey struct{}
+
+func WithBatchId(ctx context.Context, batchId string) context.Context {
+	return context.WithValue(ctx, batchIdKey{}, batchId)
+}
+
+func BatchIdFromContext(ctx context.Context) string {
+	batchId, _ := ctx.Value(batchIdKey{}).(string)
+	return batchId
+}
+
+func lastEntries(entries []repositories.AuditEntry, limit int) []repositories.AuditEntry {
+	if limit > 0 && len(entries) > limit {
+		return entries[len(entries)-limit:]
+	}
+	return entries
+}
@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/utils/logger"
+	"os"
+	"sync"
+)
+
+type FileSink struct {
+	mutex  sync.Mutex
+	path   string
+	file   *os.File
+	closed bool
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{path: path, file: file}, nil
+}
+
+func (s *FileSink) Record(entry repositories.AuditEntry) error {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		// a handler outliving the shutdown must not fail on the audit log.
+		logger.Default().Warn("dropping audit entry recorded after the audit log was closed",
+			logger.Any("action", entry.Action),
+			logger.Any("full_name", entry.FullName),
+		)
+		return nil
+	}
+	_, err = s.file.Write(append(bytes, '\n'))
+	return err
+}
+
+func (s *FileSink) Query(filter repositories.AuditFilter) ([]repositories.AuditEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := make([]repositories.AuditEntry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry repositories.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logger.Default().Warn("skipping invalid audit log line", logger.Err(err))
+			continue
+		}
+		if filter.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lastEntries(result, filter.Limit), nil
+}
+
+func (s *FileSink) Ping(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.file.Stat()
+	return err
+}
+
+// Close flushes and closes the file, entries recorded afterwards are dropped.
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	return s.file.Close()
+}
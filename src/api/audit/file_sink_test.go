@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/repositories"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSinkRecordAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	assert.Nil(t, err)
+
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []repositories.AuditEntry{
+		{Timestamp: start, Caller: "team-a", Owner: "my-org", Request: &repositories.CreateRepoRequest{Name: "first", Org: "my-org"}, Status: 201, RepoId: 1, FullName: "my-org/first"},
+		{Timestamp: start.Add(time.Hour), Caller: "team-b", Owner: "other-org", Request: &repositories.CreateRepoRequest{Name: "second", Org: "other-org"}, Status: 422, Error: "name already exists on this account"},
+		{Timestamp: start.Add(2 * time.Hour), Caller: "team-a", Owner: "my-org", BatchId: "abc", Request: &repositories.CreateRepoRequest{Name: "third", Org: "my-org"}, Status: 201, RepoId: 3, FullName: "my-org/third"},
+	}
+	for _, entry := range entries {
+		assert.Nil(t, sink.Record(entry))
+	}
+
+	all, err := sink.Query(repositories.AuditFilter{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, entries, all)
+
+	byOwner, err := sink.Query(repositories.AuditFilter{Owner: "my-org"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []repositories.AuditEntry{entries[0], entries[2]}, byOwner)
+
+	since, err := sink.Query(repositories.AuditFilter{Since: start.Add(30 * time.Minute)})
+	assert.Nil(t, err)
+	assert.EqualValues(t, entries[1:], since)
+
+	limited, err := sink.Query(repositories.AuditFilter{Limit: 1})
+	assert.Nil(t, err)
+	assert.EqualValues(t, entries[2:], limited)
+
+	assert.Nil(t, sink.Close())
+}
+
+func TestFileSinkDropsEntriesAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	assert.Nil(t, err)
+
+	assert.Nil(t, sink.Close())
+	assert.Nil(t, sink.Record(repositories.AuditEntry{Caller: "team-a", Owner: "my-org", Status: 201}))
+	assert.Nil(t, sink.Close())
+
+	entries, err := sink.Query(repositories.AuditFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/repositories"
+	"sync"
+)
+
+type MemorySink struct {
+	mutex   sync.Mutex
+	entries []repositories.AuditEntry
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Record(entry repositories.AuditEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *MemorySink) Query(filter repositories.AuditFilter) ([]repositories.AuditEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]repositories.AuditEntry, 0)
+	for _, entry := range s.entries {
+		if filter.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+
+	return lastEntries(result, filter.Limit), nil
+}
+
+func (s *MemorySink) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemorySink) Close() error {
+	return nil
+}
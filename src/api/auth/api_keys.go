@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+)
+
+type apiKeyEntry struct {
+	Caller
+	Key string `json:"key"`
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func loadApiKeys(path string) (map[string]*Caller, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []apiKeyEntry
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return nil, errors.New("invalid api keys file: " + err.Error())
+	}
+
+	result := make(map[string]*Caller, len(entries))
+	for i := range entries {
+		if entries[i].Id == "" || entries[i].Key == "" {
+			return nil, errors.New("invalid api keys file: every entry needs an id and a key")
+		}
+		caller := entries[i].Caller
+		result[hashApiKey(entries[i].Key)] = &caller
+	}
+
+	return result, nil
+}
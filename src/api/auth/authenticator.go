@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"golang-microservices/src/api/domain/headers"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const headerAuthorization = "Authorization"
+const bearerPrefix = "Bearer "
+
+type Options struct {
+	ApiKeysFile string
+	JwtSecret   string
+	JwksFile    string
+}
+
+type Authenticator struct {
+	apiKeys   map[string]*Caller
+	jwtSecret []byte
+	rsaKeys   map[string]*rsa.PublicKey
+	now       func() time.Time
+}
+
+func NewAuthenticator(options Options) (*Authenticator, error) {
+	result := &Authenticator{
+		apiKeys:   map[string]*Caller{},
+		jwtSecret: []byte(options.JwtSecret),
+		rsaKeys:   map[string]*rsa.PublicKey{},
+		now:       time.Now,
+	}
+
+	if options.ApiKeysFile != "" {
+		apiKeys, err := loadApiKeys(options.ApiKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		result.apiKeys = apiKeys
+	}
+
+	if options.JwksFile != "" {
+		rsaKeys, err := loadJwks(options.JwksFile)
+		if err != nil {
+			return nil, err
+		}
+		result.rsaKeys = rsaKeys
+	}
+
+	return result, nil
+}
+
+func (a *Authenticator) Authenticate(request *http.Request) (*Caller, errors.ApiError) {
+	if apiKey := request.Header.Get(headers.ApiKey); apiKey != "" {
+		caller := a.apiKeys[hashApiKey(apiKey)]
+		if caller == nil {
+			return nil, errors.NewUnauthorizedApiError("invalid api key")
+		}
+		return caller, nil
+	}
+
+	authorization := request.Header.Get(headerAuthorization)
+	if strings.HasPrefix(authorization, bearerPrefix) {
+		caller, err := a.verifyJwt(strings.TrimSpace(strings.TrimPrefix(authorization, bearerPrefix)))
+		if err != nil {
+			return nil, errors.NewUnauthorizedApiError("invalid bearer token: " + err.Error())
+		}
+		return caller, nil
+	}
+
+	return nil, errors.NewUnauthorizedApiError("missing credentials")
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func encodeJwtPart(t *testing.T, value interface{}) string {
+	bytes, err := json.Marshal(value)
+	assert.Nil(t, err)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	signingInput := encodeJwtPart(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeJwtPart(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signingInput := encodeJwtPart(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeJwtPart(t, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.Nil(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func requestWithHeader(name string, value string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/repository", nil)
+	request.Header.Set(name, value)
+	return request
+}
+
+func TestAuthenticateMissingCredentials(t *testing.T) {
+	authenticator, _ := NewAuthenticator(Options{})
+
+	caller, err := authenticator.Authenticate(httptest.NewRequest(http.MethodPost, "/repository", nil))
+
+	assert.Nil(t, caller)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+	assert.EqualValues(t, "missing credentials", err.Message())
+}
+
+func TestAuthenticateApiKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`[
+{"id": "team-a", "key": "secret-a", "scopes": ["repos:create"], "orgs": ["my-org"]}
+]`), 0600))
+
+	authenticator, err := NewAuthenticator(Options{ApiKeysFile: path})
+	assert.Nil(t, err)
+
+	caller, apiErr := authenticator.Authenticate(requestWithHeader("X-Api-Key", "secret-a"))
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, &Caller{Id: "team-a", Scopes: []string{"repos:create"}, Orgs: []string{"my-org"}}, caller)
+
+	caller, apiErr = authenticator.Authenticate(requestWithHeader("X-Api-Key", "secret-b"))
+	assert.Nil(t, caller)
+	assert.EqualValues(t, http.StatusUnauthorized, apiErr.Status())
+	assert.EqualValues(t, "invalid api key", apiErr.Message())
+}
+
+func TestNewAuthenticatorInvalidApiKeysFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`[{"id": "team-a"}]`), 0600))
+
+	authenticator, err := NewAuthenticator(Options{ApiKeysFile: path})
+	assert.Nil(t, authenticator)
+	assert.NotNil(t, err)
+}
+
+func TestAuthenticateHS256(t *testing.T) {
+	authenticator, _ := NewAuthenticator(Options{JwtSecret: "jwt-secret"})
+	token := signHS256(t, "jwt-secret", map[string]interface{}{
+		"sub":   "ci-bot",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "repos:create repos:batch",
+		"orgs":  []string{"my-org"},
+	})
+
+	caller, err := authenticator.Authenticate(requestWithHeader("Authorization", "Bearer "+token))
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ci-bot", caller.Id)
+	assert.True(t, caller.HasScope(ScopeReposCreate))
+	assert.True(t, caller.HasScope(ScopeReposBatch))
+	assert.True(t, caller.CanUseOrg("my-org"))
+	assert.False(t, caller.CanUseOrg("other-org"))
+}
+
+func TestAuthenticateHS256InvalidSignature(t *testing.T) {
+	authenticator, _ := NewAuthenticator(Options{JwtSecret: "jwt-secret"})
+	token := signHS256(t, "another-secret", map[string]interface{}{
+		"sub": "ci-bot",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	caller, err := authenticator.Authenticate(requestWithHeader("Authorization", "Bearer "+token))
+
+	assert.Nil(t, caller)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+	assert.EqualValues(t, "invalid bearer token: invalid token signature", err.Message())
+}
+
+func TestAuthenticateExpiredToken(t *testing.T) {
+	authenticator, _ := NewAuthenticator(Options{JwtSecret: "jwt-secret"})
+	token := signHS256(t, "jwt-secret", map[string]interface{}{
+		"sub": "ci-bot",
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	})
+
+	caller, err := authenticator.Authenticate(requestWithHeader("Authorization", "Bearer "+token))
+
+	assert.Nil(t, caller)
+	assert.EqualValues(t, "invalid bearer token: token expired", err.Message())
+}
+
+func TestAuthenticateRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	jwks := map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "key-1",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	jwksBytes, _ := json.Marshal(jwks)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.Nil(t, ioutil.WriteFile(path, jwksBytes, 0600))
+
+	authenticator, err := NewAuthenticator(Options{JwksFile: path})
+	assert.Nil(t, err)
+
+	token := signRS256(t, key, "key-1", map[string]interface{}{
+		"sub":    "portal",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"scopes": []string{"repos:create"},
+	})
+	caller, apiErr := authenticator.Authenticate(requestWithHeader("Authorization", "Bearer "+token))
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, "portal", caller.Id)
+	assert.True(t, caller.HasScope(ScopeReposCreate))
+	assert.False(t, caller.HasScope(ScopeReposBatch))
+
+	token = signRS256(t, key, "key-2", map[string]interface{}{
+		"sub": "portal",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	caller, apiErr = authenticator.Authenticate(requestWithHeader("Authorization", "Bearer "+token))
+	assert.Nil(t, caller)
+	assert.EqualValues(t, "invalid bearer token: unknown token key", apiErr.Message())
+}
+
+func TestAuthenticateUnsupportedAlgorithm(t *testing.T) {
+	authenticator, _ := NewAuthenticator(Options{})
+	token := signHS256(t, "", map[string]interface{}{
+		"sub": "ci-bot",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	caller, err := authenticator.Authenticate(requestWithHeader("Authorization", "Bearer "+token))
+
+	assert.Nil(t, caller)
+	assert.EqualValues(t, "invalid bearer token: unsupported token algorithm", err.Message())
+}
+
+func TestCanUseOrg(t *testing.T) {
+	assert.False(t, (&Caller{Id: "team-a"}).CanUseOrg("my-org"))
+	assert.False(t, (&Caller{Id: "team-a"}).CanUseOrg(""))
+	assert.True(t, (&Caller{Id: "team-a", Orgs: []string{"*"}}).CanUseOrg("other-org"))
+	assert.True(t, (&Caller{Id: "team-a", Orgs: []string{""}}).CanUseOrg(""))
+	assert.False(t, (&Caller{Id: "team-a", Orgs: []string{"my-org"}}).CanUseOrg(""))
+}
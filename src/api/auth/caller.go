@@ -0,0 +1,53 @@
+package auth
+
+import "context"
+
+const ScopeReposCreate = "repos:create"
+const ScopeReposBatch = "repos:batch"
+const ScopeReposRead = "repos:read"
+const ScopeReposUpdate = "repos:update"
+const ScopeReposDelete = "repos:delete"
+const ScopeReposTransfer = "repos:transfer"
+const ScopeReposAccess = "repos:access"
+const ScopeReposReconcile = "repos:reconcile"
+const ScopeAuditRead = "audit:read"
+
+const anyOrg = "*"
+
+type Caller struct {
+	Id     string   `json:"id"`
+	Scopes []string `json:"scopes"`
+	Orgs   []string `json:"orgs"`
+}
+
+func (c *Caller) HasScope(scope string) bool {
+	for _, current := range c.Scopes {
+		if current == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CanUseOrg reports whether the caller may act on the given org. Orgs not in
+// the allowlist are denied, so an empty allowlist allows nothing, "*" allows
+// any org and "" stands for the account of the token owner.
+func (c *Caller) CanUseOrg(org string) bool {
+	for _, current := range c.Orgs {
+		if current == anyOrg || current == org {
+			return true
+		}
+	}
+	return false
+}
+
+type callerKey struct{}
+
+func WithCaller(ctx context.Context, caller *Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+func CallerFromContext(ctx context.Context) *Caller {
+	caller, _ := ctx.Value(callerKey{}).(*Caller)
+	return caller
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+func loadJwks(path string) (map[string]*rsa.PublicKey, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keySet jsonWebKeySet
+	if err := json.Unmarshal(bytes, &keySet); err != nil {
+		return nil, errors.New("invalid jwks file: " + err.Error())
+	}
+
+	result := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, key := range keySet.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, errors.New("invalid jwks modulus for key " + key.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, errors.New("invalid jwks exponent for key " + key.Kid)
+		}
+		result[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const algHS256 = "HS256"
+const algRS256 = "RS256"
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Scope     string   `json:"scope"`
+	Scopes    []string `json:"scopes"`
+	Orgs      []string `json:"orgs"`
+}
+
+func decodeJwtPart(part string, target interface{}) error {
+	bytes, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, target)
+}
+
+func (a *Authenticator) verifyJwt(token string) (*Caller, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeJwtPart(parts[0], &header); err != nil {
+		return nil, errors.New("malformed token header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	switch header.Alg {
+	case algHS256:
+		if len(a.jwtSecret) == 0 {
+			return nil, errors.New("unsupported token algorithm")
+		}
+		mac := hmac.New(sha256.New, a.jwtSecret)
+		mac.Write([]byte(parts[0] + "." + parts[1]))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, errors.New("invalid token signature")
+		}
+	case algRS256:
+		key := a.rsaKey(header.Kid)
+		if key == nil {
+			return nil, errors.New("unknown token key")
+		}
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("invalid token signature")
+		}
+	default:
+		return nil, errors.New("unsupported token algorithm")
+	}
+
+	var claims jwtClaims
+	if err := decodeJwtPart(parts[1], &claims); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+
+	now := a.now()
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, errors.New("token expired")
+	}
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, errors.New("token not valid yet")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token without subject")
+	}
+
+	scopes := claims.Scopes
+	if claims.Scope != "" {
+		scopes = append(scopes, strings.Fields(claims.Scope)...)
+	}
+
+	return &Caller{Id: claims.Subject, Scopes: scopes, Orgs: claims.Orgs}, nil
+}
+
+func (a *Authenticator) rsaKey(kid string) *rsa.PublicKey {
+	if kid == "" && len(a.rsaKeys) == 1 {
+		for _, key := range a.rsaKeys {
+			return key
+		}
+	}
+	return a.rsaKeys[kid]
+}
@@ -0,0 +1,251 @@
+// Package client calls the repository service over http and returns the
+// domain types, failures are the errors.ApiError the service answered with.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"golang-microservices/src/api/domain/headers"
+	"golang-microservices/src/api/utils/errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const headerAuthorization = "Authorization"
+
+const defaultMaxRetries = 3
+const defaultRetryDelay = 500 * time.Millisecond
+const defaultMaxRetryDelay = 30 * time.Second
+
+type Client struct {
+	baseUrl       string
+	apiKey        string
+	bearerToken   string
+	httpClient    *http.Client
+	maxRetries    int
+	maxRetryDelay time.Duration
+}
+
+type Option func(*Client)
+
+func WithApiKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.bearerToken = token
+	}
+}
+
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times an idempotent request answered with 429 or
+// 503 is sent again, 0 disables retries.
+func WithRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+	}
+}
+
+// WithMaxRetryDelay caps the wait between two attempts, whatever Retry-After
+// asks for.
+func WithMaxRetryDelay(delay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetryDelay = delay
+	}
+}
+
+func New(baseUrl string, options ...Option) *Client {
+	c := &Client{
+		baseUrl:       strings.TrimSuffix(strings.TrimSpace(baseUrl), "/"),
+		httpClient:    http.DefaultClient,
+		maxRetries:    defaultMaxRetries,
+		maxRetryDelay: defaultMaxRetryDelay,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+type call struct {
+	method  string
+	path    string
+	query   url.Values
+	headers http.Header
+	body    interface{}
+}
+
+// retryDelay follows Retry-After, in seconds or as a date, and backs off
+// exponentially without it.
+func (c *Client) retryDelay(header http.Header, attempt int, now time.Time) time.Duration {
+	delay := defaultRetryDelay << attempt
+	if value := strings.TrimSpace(header.Get(headers.RetryAfter)); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(value); err == nil {
+			delay = date.Sub(now)
+		}
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > c.maxRetryDelay {
+		delay = c.maxRetryDelay
+	}
+	return delay
+}
+
+// isRetryable retries the answers of the rate limiter, a 429 with
+// Retry-After, whatever the method since the limiter runs before any handler.
+// A 503 is only retried on idempotent methods, a creation may have happened
+// already. A 429 without Retry-After is a quota error, which lasts until the
+// next day.
+func isRetryable(method string, status int, header http.Header, payload []byte) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		if header.Get(headers.RetryAfter) == "" {
+			return false
+		}
+	case http.StatusServiceUnavailable:
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	// a batch answered with the status of its first failure was processed,
+	// sending it again would repeat the successful items.
+	return !isBatchBody(payload)
+}
+
+// isBatchBody tells batch responses, which carry their own status, from api
+// errors.
+func isBatchBody(payload []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return false
+	}
+	_, results := fields["results"]
+	_, actions := fields["actions"]
+	return results || actions
+}
+
+// send runs the call and retries the 429 and 503 answers isRetryable
+// accepts. Transport failures are reported as 503 and not retried, the
+// request state is unknown.
+func (c *Client) send(ctx context.Context, request call) (int, []byte, errors.ApiError) {
+	var body []byte
+	if request.body != nil {
+		var err error
+		if body, err = json.Marshal(request.body); err != nil {
+			return 0, nil, errors.NewBadRequestApiError("invalid request body: " + err.Error())
+		}
+	}
+	target := c.baseUrl + request.path
+	if len(request.query) > 0 {
+		target += "?" + request.query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		httpRequest, err := http.NewRequestWithContext(ctx, request.method, target, bytes.NewReader(body))
+		if err != nil {
+			return 0, nil, errors.NewBadRequestApiError("invalid service url: " + err.Error())
+		}
+		for key := range request.headers {
+			httpRequest.Header.Set(key, request.headers.Get(key))
+		}
+		if request.body != nil {
+			httpRequest.Header.Set("Content-Type", "application/json")
+		}
+		if c.apiKey != "" {
+			httpRequest.Header.Set(headers.ApiKey, c.apiKey)
+		}
+		if c.bearerToken != "" {
+			httpRequest.Header.Set(headerAuthorization, "Bearer "+c.bearerToken)
+		}
+
+		response, err := c.httpClient.Do(httpRequest)
+		if err != nil {
+			return 0, nil, errors.NewServiceUnavailableApiError("error when calling the service: " + err.Error())
+		}
+		payload, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return 0, nil, errors.NewServiceUnavailableApiError("error when reading the service response: " + err.Error())
+		}
+
+		if attempt >= c.maxRetries || !isRetryable(request.method, response.StatusCode, response.Header, payload) {
+			return response.StatusCode, payload, nil
+		}
+
+		timer := time.NewTimer(c.retryDelay(response.Header, attempt, time.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, nil, errors.NewServiceUnavailableApiError("request cancelled while waiting to retry: " + ctx.Err().Error())
+		case <-timer.C:
+		}
+	}
+}
+
+// decodeResponse fills result from a successful response, any other status
+// carries an api error.
+func decodeResponse(status int, payload []byte, result interface{}) errors.ApiError {
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		apiErr, err := errors.NewApiErrorFromBytes(payload)
+		if err != nil || apiErr.Status() == 0 {
+			return errors.NewApiError(status, "unexpected response from the service: "+http.StatusText(status))
+		}
+		return apiErr
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return errors.NewInternalServerError("invalid response from the service: " + err.Error())
+	}
+	return nil
+}
+
+// decodeBatch reads batch bodies whatever their status, the batch status is
+// part of the result.
+func decodeBatch(status int, payload []byte, result interface{}) errors.ApiError {
+	if isBatchBody(payload) {
+		if err := json.Unmarshal(payload, result); err != nil {
+			return errors.NewInternalServerError("invalid response from the service: " + err.Error())
+		}
+		return nil
+	}
+	return decodeResponse(status, payload, result)
+}
+
+func (c *Client) do(ctx context.Context, request call, result interface{}) errors.ApiError {
+	status, payload, apiErr := c.send(ctx, request)
+	if apiErr != nil {
+		return apiErr
+	}
+	return decodeResponse(status, payload, result)
+}
+
+func (c *Client) doBatch(ctx context.Context, request call, result interface{}) errors.ApiError {
+	status, payload, apiErr := c.send(ctx, request)
+	if apiErr != nil {
+		return apiErr
+	}
+	return decodeBatch(status, payload, result)
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/repositories"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFlakyServer(t *testing.T, failures int32, status int, body string) (*httptest.Server, *int32) {
+	calls := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": 1, "owner": "my-org", "name": "repo", "full_name": "my-org/repo"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+func TestRetryDelay(t *testing.T) {
+	c := New("http://localhost", WithMaxRetryDelay(10*time.Second))
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.EqualValues(t, 2*time.Second, c.retryDelay(http.Header{"Retry-After": []string{"2"}}, 0, now))
+	assert.EqualValues(t, 10*time.Second, c.retryDelay(http.Header{"Retry-After": []string{"120"}}, 0, now))
+	assert.EqualValues(t, 5*time.Second, c.retryDelay(http.Header{"Retry-After": []string{now.Add(5 * time.Second).Format(http.TimeFormat)}}, 0, now))
+	assert.EqualValues(t, 0, c.retryDelay(http.Header{"Retry-After": []string{now.Add(-time.Minute).Format(http.TimeFormat)}}, 0, now))
+	assert.EqualValues(t, defaultRetryDelay, c.retryDelay(http.Header{}, 0, now))
+	assert.EqualValues(t, 4*defaultRetryDelay, c.retryDelay(http.Header{"Retry-After": []string{"soon"}}, 2, now))
+}
+
+func TestRetriesTooManyRequests(t *testing.T) {
+	server, calls := newFlakyServer(t, 2, http.StatusTooManyRequests, `{"status": 429, "message": "rate limit exceeded"}`)
+
+	repo, err := New(server.URL).GetRepo(context.Background(), "", "my-org", "repo")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org/repo", repo.FullName)
+	assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+}
+
+func TestRetriesServiceUnavailable(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusServiceUnavailable, `{"status": 503, "message": "github is unavailable"}`)
+
+	repo, err := New(server.URL).GetRepo(context.Background(), "", "my-org", "repo")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, repo)
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	server, calls := newFlakyServer(t, 10, http.StatusTooManyRequests, `{"status": 429, "message": "rate limit exceeded"}`)
+
+	repo, err := New(server.URL, WithRetries(1)).GetRepo(context.Background(), "", "my-org", "repo")
+
+	assert.Nil(t, repo)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+	assert.EqualValues(t, "rate limit exceeded", err.Message())
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+}
+
+func TestNoRetryOnOtherErrors(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusInternalServerError, `{"status": 500, "message": "boom"}`)
+
+	_, err := New(server.URL).GetRepo(context.Background(), "", "my-org", "repo")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestNoRetryOnBatchBody(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusServiceUnavailable,
+		`{"status": 503, "batch_id": "b-1", "results": [{"error": {"status": 503, "message": "github is unavailable"}}]}`)
+
+	res, err := New(server.URL).CreateRepos(context.Background(), []repositories.CreateRepoRequest{{Name: "repo"}})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.EqualValues(t, 1, len(res.Results))
+	assert.EqualValues(t, "github is unavailable", res.Results[0].Error.Message())
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestNoRetryOnCreate(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusServiceUnavailable, `{"status": 503, "message": "github is unavailable"}`)
+
+	res, err := New(server.URL).CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "repo"})
+
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusServiceUnavailable, err.Status())
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestRetriesCreateWhenRateLimited(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusTooManyRequests, `{"status": 429, "message": "rate limit exceeded"}`)
+
+	res, err := New(server.URL).CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "repo"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org/repo", res.FullName)
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+}
+
+func TestNoRetryOnQuotaError(t *testing.T) {
+	calls := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"status": 429, "message": "daily repository creation quota of 1 exceeded"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := New(server.URL).GetRepo(context.Background(), "", "my-org", "repo")
+
+	assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestRetryCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"status": 429, "message": "rate limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	repo, err := New(server.URL).GetRepo(ctx, "", "my-org", "repo")
+
+	assert.Nil(t, repo)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, err.Status())
+	assert.Contains(t, err.Message(), "request cancelled while waiting to retry")
+}
+
+func TestTransportError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	repo, err := New(server.URL).GetRepo(context.Background(), "", "my-org", "repo")
+
+	assert.Nil(t, repo)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, err.Status())
+	assert.Contains(t, err.Message(), "error when calling the service")
+}
+
+func TestUnexpectedErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("<html>bad gateway</html>"))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL).GetRepo(context.Background(), "", "my-org", "repo")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadGateway, err.Status())
+	assert.EqualValues(t, "unexpected response from the service: Bad Gateway", err.Message())
+}
+
+func TestAuthenticationHeaders(t *testing.T) {
+	var apiKey, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey, authorization = r.Header.Get("X-Api-Key"), r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL+"/", WithApiKey("key-a"), WithBearerToken("token")).GetRepo(context.Background(), "", "my-org", "repo")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "key-a", apiKey)
+	assert.EqualValues(t, "Bearer token", authorization)
+}
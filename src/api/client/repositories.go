@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/headers"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func repositoryPath(owner string, name string, suffix string) string {
+	return "/repository/" + url.PathEscape(owner) + "/" + url.PathEscape(name) + suffix
+}
+
+func providerQuery(provider string) url.Values {
+	query := url.Values{}
+	if provider != "" {
+		query.Set("provider", provider)
+	}
+	return query
+}
+
+func (c *Client) CreateRepo(ctx context.Context, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	var result repositories.CreateRepoResponse
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/repository", body: input}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateRepos returns the batch whatever its status, StatusCode tells
+// whether every repository was created.
+func (c *Client) CreateRepos(ctx context.Context, input []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError) {
+	var result repositories.CreateReposResponse
+	if err := c.doBatch(ctx, call{method: http.MethodPost, path: "/repositories", body: input}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetRepo(ctx context.Context, provider string, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	var result repositories.Repository
+	request := call{method: http.MethodGet, path: repositoryPath(owner, name, ""), query: providerQuery(provider)}
+	if err := c.do(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) ListRepos(ctx context.Context, provider string, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	query := providerQuery(provider)
+	query.Set("owner", options.Owner)
+	if options.Visibility != "" {
+		query.Set("visibility", options.Visibility)
+	}
+	if options.Page > 0 {
+		query.Set("page", strconv.Itoa(options.Page))
+	}
+	if options.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(options.PerPage))
+	}
+
+	var result repositories.RepositoryPage
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/repositories", query: query}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) SyncRepo(ctx context.Context, provider string, owner string, name string, input repositories.SyncRequest) (*repositories.SyncResponse, errors.ApiError) {
+	var result repositories.SyncResponse
+	request := call{method: http.MethodPost, path: repositoryPath(owner, name, "/sync"), query: providerQuery(provider), body: input}
+	if err := c.do(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) UpdateRepo(ctx context.Context, provider string, owner string, name string, input repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	var result repositories.Repository
+	request := call{method: http.MethodPatch, path: repositoryPath(owner, name, ""), query: providerQuery(provider), body: input}
+	if err := c.do(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) TransferRepo(ctx context.Context, provider string, owner string, name string, input repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	var result repositories.Repository
+	request := call{method: http.MethodPost, path: repositoryPath(owner, name, "/transfer"), query: providerQuery(provider), body: input}
+	if err := c.do(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) ApplyProtection(ctx context.Context, provider string, owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError) {
+	var result repositories.ProtectionReport
+	request := call{method: http.MethodPut, path: repositoryPath(owner, name, "/protection"), query: providerQuery(provider), body: input}
+	if err := c.do(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetProtectionDrift(ctx context.Context, provider string, owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError) {
+	query := providerQuery(provider)
+	if input.Policy != "" {
+		query.Set("policy", input.Policy)
+	}
+	if input.Branch != "" {
+		query.Set("branch", input.Branch)
+	}
+
+	var result repositories.ProtectionReport
+	if err := c.do(ctx, call{method: http.MethodGet, path: repositoryPath(owner, name, "/protection"), query: query}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GrantAccess(ctx context.Context, provider string, owner string, name string, input repositories.AccessRequest) (*repositories.AccessResponse, errors.ApiError) {
+	var result repositories.AccessResponse
+	request := call{method: http.MethodPut, path: repositoryPath(owner, name, "/access"), query: providerQuery(provider), body: input}
+	if err := c.do(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func deleteCall(path string, provider string, archive bool, confirm string) call {
+	request := call{method: http.MethodDelete, path: path, query: providerQuery(provider), headers: http.Header{}}
+	if archive {
+		request.query.Set("archive", "true")
+	}
+	if confirm != "" {
+		request.headers.Set(headers.ConfirmDelete, confirm)
+	}
+	return request
+}
+
+func (c *Client) DeleteRepo(ctx context.Context, input repositories.DeleteRepoRequest) (*repositories.DeleteRepoResponse, errors.ApiError) {
+	var result repositories.DeleteRepoResponse
+	request := deleteCall(repositoryPath(input.Owner, input.Name, ""), input.Provider, input.Archive, input.Confirm)
+	if err := c.do(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) DeleteRepos(ctx context.Context, input repositories.DeleteReposRequest) (*repositories.DeleteReposResponse, errors.ApiError) {
+	request := deleteCall("/repositories", input.Provider, input.Archive, input.Confirm)
+	request.query.Set("owner", input.Owner)
+	request.query.Set("prefix", input.Prefix)
+
+	var result repositories.DeleteReposResponse
+	if err := c.doBatch(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Reconcile sends the manifest as json, planOnly only asks for the plan.
+func (c *Client) Reconcile(ctx context.Context, manifest repositories.Manifest, planOnly bool) (*repositories.ReconcileResponse, errors.ApiError) {
+	request := call{method: http.MethodPost, path: "/reconcile", body: manifest}
+	if planOnly {
+		request.query = url.Values{"plan": []string{"true"}}
+	}
+
+	var result repositories.ReconcileResponse
+	if err := c.doBatch(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) QueryAudit(ctx context.Context, filter repositories.AuditFilter) ([]repositories.AuditEntry, errors.ApiError) {
+	query := url.Values{}
+	if filter.Owner != "" {
+		query.Set("owner", filter.Owner)
+	}
+	if !filter.Since.IsZero() {
+		query.Set("since", filter.Since.Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	result := make([]repositories.AuditEntry, 0)
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/audit", query: query}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
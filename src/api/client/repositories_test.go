@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/app"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/config"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/providers/providertest"
+	"golang-microservices/src/api/services"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var _ services.ReposServiceInterface = (*Client)(nil)
+
+func newTestServer(t *testing.T, provider *providertest.Provider, auditLog audit.Log, configure ...func(*config.Config)) *httptest.Server {
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	assert.Nil(t, ioutil.WriteFile(keysFile, []byte(`[
+		{"id": "team-a", "key": "key-a", "scopes": ["repos:create", "repos:batch", "repos:read", "repos:update", "repos:delete", "repos:reconcile", "audit:read"], "orgs": ["my-org"]},
+		{"id": "team-b", "key": "key-b", "scopes": ["repos:create"]}
+	]`), 0600))
+
+	cfg := config.Default()
+	cfg.GithubAccessToken = "abc123"
+	cfg.AuthKeysFile = keysFile
+	cfg.DeleteAllowedOrgs = []string{"my-org"}
+	for _, apply := range configure {
+		apply(&cfg)
+	}
+
+	application, err := app.New(cfg, app.WithProvider(providers.Github, provider), app.WithAuditLog(auditLog))
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(application)
+	t.Cleanup(func() {
+		server.Close()
+		_ = application.Close()
+	})
+	return server
+}
+
+func TestCreateRepo(t *testing.T) {
+	provider := providertest.New()
+	server := newTestServer(t, provider, audit.NewMemorySink())
+
+	res, err := New(server.URL, WithApiKey("key-a")).CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:        "repo",
+		Description: "a repository",
+		Org:         "my-org",
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org", res.Owner)
+	assert.EqualValues(t, "repo", res.Name)
+	assert.EqualValues(t, providers.Github, res.Provider)
+	assert.True(t, provider.Repo("my-org/repo").Private)
+}
+
+func TestCreateRepoInvalid(t *testing.T) {
+	server := newTestServer(t, providertest.New(), audit.NewMemorySink())
+
+	res, err := New(server.URL, WithApiKey("key-a")).CreateRepo(context.Background(), repositories.CreateRepoRequest{Org: "my-org"})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestCreateRepoUnauthenticated(t *testing.T) {
+	server := newTestServer(t, providertest.New(), audit.NewMemorySink())
+
+	res, err := New(server.URL, WithApiKey("unknown")).CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "repo", Org: "my-org"})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+}
+
+func TestCreateReposPartial(t *testing.T) {
+	server := newTestServer(t, providertest.New(), audit.NewMemorySink())
+
+	res, err := New(server.URL, WithApiKey("key-a")).CreateRepos(context.Background(), []repositories.CreateRepoRequest{
+		{Name: "repo", Org: "my-org"},
+		{Name: "existing", Org: "my-org"},
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, res.StatusCode)
+	assert.NotEmpty(t, res.BatchId)
+	assert.EqualValues(t, 2, len(res.Results))
+
+	failures := 0
+	for _, result := range res.Results {
+		if result.Error != nil {
+			failures++
+			assert.EqualValues(t, http.StatusUnprocessableEntity, result.Error.Status())
+		} else {
+			assert.EqualValues(t, "my-org/repo", result.Response.FullName)
+		}
+	}
+	assert.EqualValues(t, 1, failures)
+}
+
+func TestCreateReposAllFailed(t *testing.T) {
+	server := newTestServer(t, providertest.New(), audit.NewMemorySink())
+
+	res, err := New(server.URL, WithApiKey("key-a")).CreateRepos(context.Background(), []repositories.CreateRepoRequest{
+		{Name: "existing", Org: "my-org"},
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, res.StatusCode)
+	assert.EqualValues(t, "Repository creation failed.", res.Results[0].Error.Message())
+}
+
+func TestGetAndListRepos(t *testing.T) {
+	server := newTestServer(t, providertest.New(providertest.WithRepos("my-org/api", "my-org/web")), audit.NewMemorySink())
+	c := New(server.URL, WithApiKey("key-a"))
+
+	repo, err := c.GetRepo(context.Background(), providers.Github, "my-org", "api")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org/api", repo.FullName)
+
+	repo, err = c.GetRepo(context.Background(), "", "my-org", "missing")
+	assert.Nil(t, repo)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+
+	page, err := c.ListRepos(context.Background(), "", repositories.ListReposOptions{Owner: "my-org", PerPage: 10})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(page.Repositories))
+	assert.EqualValues(t, "api", page.Repositories[0].Name)
+}
+
+func TestUpdateRepo(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/api"))
+	server := newTestServer(t, provider, audit.NewMemorySink())
+	description := "the api"
+
+	repo, err := New(server.URL, WithApiKey("key-a")).UpdateRepo(context.Background(), "", "my-org", "api", repositories.UpdateRepoRequest{Description: &description})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "the api", repo.Description)
+	assert.EqualValues(t, "the api", provider.Repo("my-org/api").Description)
+}
+
+func TestDeleteRepoConfirmation(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/api"))
+	server := newTestServer(t, provider, audit.NewMemorySink())
+	c := New(server.URL, WithApiKey("key-a"))
+	request := repositories.DeleteRepoRequest{Owner: "my-org", Name: "api"}
+
+	res, err := c.DeleteRepo(context.Background(), request)
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusPreconditionRequired, err.Status())
+
+	request.Confirm = request.ConfirmationToken()
+	res, err = c.DeleteRepo(context.Background(), request)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org/api", res.FullName)
+	assert.EqualValues(t, 0, len(provider.FullNames()))
+}
+
+func TestDeleteRepos(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/tmp-a", "my-org/tmp-b", "my-org/api"))
+	server := newTestServer(t, provider, audit.NewMemorySink())
+	request := repositories.DeleteReposRequest{Owner: "my-org", Prefix: "tmp-", Archive: true}
+	request.Confirm = request.ConfirmationToken()
+
+	res, err := New(server.URL, WithApiKey("key-a")).DeleteRepos(context.Background(), request)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, res.StatusCode)
+	assert.EqualValues(t, 2, len(res.Results))
+	assert.True(t, provider.Repo("my-org/tmp-a").Archived)
+	assert.False(t, provider.Repo("my-org/api").Archived)
+}
+
+func TestReconcilePlan(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/api"))
+	server := newTestServer(t, provider, audit.NewMemorySink())
+	description := "the api"
+
+	res, err := New(server.URL, WithApiKey("key-a")).Reconcile(context.Background(), repositories.Manifest{
+		Owner: "my-org",
+		Repositories: []repositories.ManifestRepository{
+			{Name: "api", Description: &description},
+			{Name: "web"},
+		},
+	}, true)
+
+	assert.Nil(t, err)
+	assert.True(t, res.PlanOnly)
+	assert.EqualValues(t, 2, len(res.Actions))
+	assert.EqualValues(t, repositories.ReconcileUpdate, res.Actions[0].Action)
+	assert.EqualValues(t, repositories.ReconcileCreate, res.Actions[1].Action)
+	assert.EqualValues(t, 1, len(provider.FullNames()))
+}
+
+func TestQueryAudit(t *testing.T) {
+	server := newTestServer(t, providertest.New(), audit.NewMemorySink())
+	c := New(server.URL, WithApiKey("key-a"))
+
+	_, err := c.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "repo", Org: "my-org"})
+	assert.Nil(t, err)
+
+	entries, err := c.QueryAudit(context.Background(), repositories.AuditFilter{Owner: "my-org", Since: time.Now().Add(-time.Hour), Limit: 10})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(entries))
+	assert.EqualValues(t, "team-a", entries[0].Caller)
+
+	_, err = New(server.URL, WithApiKey("key-b")).QueryAudit(context.Background(), repositories.AuditFilter{})
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
+
+func TestRateLimitedRetry(t *testing.T) {
+	server := newTestServer(t, providertest.New(providertest.WithRepos("my-org/api")), audit.NewMemorySink(), func(cfg *config.Config) {
+		cfg.RateLimitRps = 20
+		cfg.RateLimitBurst = 1
+	})
+
+	noRetry := New(server.URL, WithApiKey("key-a"), WithRetries(0))
+	_, err := noRetry.GetRepo(context.Background(), "", "my-org", "api")
+	assert.Nil(t, err)
+	_, err = noRetry.GetRepo(context.Background(), "", "my-org", "api")
+	assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+
+	repo, err := New(server.URL, WithApiKey("key-a"), WithMaxRetryDelay(100*time.Millisecond)).GetRepo(context.Background(), "", "my-org", "api")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org/api", repo.FullName)
+}
@@ -0,0 +1,120 @@
+// Command reconcile converges the repositories of a manifest from a cron job
+// or a pipeline. It builds the repository service in process from the
+// environment and never calls a running service, archives are confirmed with
+// -confirm the way the endpoint expects the X-Confirm-Delete header.
+//
+//	reconcile -f repos.yaml [-plan] [-json] [-confirm OWNER/*]
+//
+// It exits with 0 when every action succeeded, 1 when some failed and 2 on
+// usage or configuration errors.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"golang-microservices/src/api/app"
+	"golang-microservices/src/api/config"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/services"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const exitOk = 0
+const exitFailed = 1
+const exitUsage = 2
+
+func main() {
+	cfg := config.Load()
+	service, auditLog, err := app.NewReposService(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error when building repository service:", err)
+		os.Exit(exitUsage)
+	}
+
+	code := run(os.Args[1:], os.Stdout, os.Stderr, service)
+	if err := auditLog.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "error when flushing audit log:", err)
+	}
+	os.Exit(code)
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer, service services.ReposServiceInterface) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "", "manifest file, yaml or json")
+	planOnly := flags.Bool("plan", false, "only print the plan")
+	asJson := flags.Bool("json", false, "print the result as json")
+	confirm := flags.String("confirm", "", "confirmation of the archives, OWNER/* for each archived owner")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *file == "" {
+		fmt.Fprintln(stderr, "missing manifest, use -f FILE")
+		return exitUsage
+	}
+
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		fmt.Fprintln(stderr, "error when reading manifest:", err)
+		return exitUsage
+	}
+	manifest, apiErr := repositories.ParseManifest(content)
+	if apiErr != nil {
+		fmt.Fprintln(stderr, apiErr.Message())
+		return exitUsage
+	}
+
+	manifest.Confirm = *confirm
+
+	res, apiErr := service.Reconcile(context.Background(), *manifest, *planOnly)
+	if apiErr != nil {
+		fmt.Fprintln(stderr, apiErr.Message())
+		return exitFailed
+	}
+
+	if *asJson {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(res)
+	} else {
+		printTable(stdout, res)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return exitFailed
+	}
+	return exitOk
+}
+
+func printTable(out io.Writer, res *repositories.ReconcileResponse) {
+	if len(res.Actions) == 0 {
+		fmt.Fprintln(out, "nothing to do, live state matches the manifest")
+		return
+	}
+
+	table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if res.PlanOnly {
+		fmt.Fprintln(table, "ACTION\tREPOSITORY\tCHANGES")
+	} else {
+		fmt.Fprintln(table, "ACTION\tREPOSITORY\tSTATUS\tDETAILS")
+	}
+	for _, action := range res.Actions {
+		fullName := action.Owner + "/" + action.Name
+		if res.PlanOnly {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", action.Action, fullName, strings.Join(action.Changes, "; "))
+			continue
+		}
+		details := action.Error
+		if details == "" {
+			details = strings.Join(append(action.Changes, action.Warnings...), "; ")
+		}
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\n", action.Action, fullName, action.Status, details)
+	}
+	_ = table.Flush()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/providers/providertest"
+	"golang-microservices/src/api/services"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newService(provider *providertest.Provider) services.ReposServiceInterface {
+	registry := providers.NewRegistry(providers.Github, nil)
+	registry.Register(providers.Github, provider)
+	return services.NewRepositoryService(services.ReposDependencies{Providers: registry})
+}
+
+func writeManifest(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "repos.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.EqualValues(t, exitUsage, run(nil, &stdout, &stderr, nil))
+	assert.EqualValues(t, "missing manifest, use -f FILE\n", stderr.String())
+
+	stderr.Reset()
+	path := writeManifest(t, "repositories: [{name: payments}]")
+	assert.EqualValues(t, exitUsage, run([]string{"-f", path}, &stdout, &stderr, nil))
+	assert.EqualValues(t, "repository payments has no owner\n", stderr.String())
+}
+
+func TestRunPlan(t *testing.T) {
+	provider := providertest.New(providertest.WithRepositories(repositories.Repository{Owner: "my-org", Name: "payments", Description: "old"}))
+	path := writeManifest(t, "owner: my-org\nrepositories:\n  - name: payments\n    description: new\n  - name: billing\n")
+
+	var stdout, stderr bytes.Buffer
+	assert.EqualValues(t, exitOk, run([]string{"-f", path, "-plan"}, &stdout, &stderr, newService(provider)))
+	assert.EqualValues(t, ""+
+		"ACTION  REPOSITORY       CHANGES\n"+
+		"update  my-org/payments  description: \"old\" -> \"new\"\n"+
+		"create  my-org/billing   \n", stdout.String())
+	assert.EqualValues(t, []string{"my-org/payments"}, provider.FullNames())
+}
+
+func TestRunApplyPartial(t *testing.T) {
+	provider := providertest.New()
+	path := writeManifest(t, "owner: my-org\nrepositories:\n  - name: billing\n  - name: existing\n")
+
+	var stdout, stderr bytes.Buffer
+	assert.EqualValues(t, exitFailed, run([]string{"-f", path, "-json"}, &stdout, &stderr, newService(provider)))
+	assert.EqualValues(t, []string{"my-org/billing"}, provider.FullNames())
+
+	var res repositories.ReconcileResponse
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &res))
+	assert.EqualValues(t, 206, res.StatusCode)
+	assert.EqualValues(t, "Repository creation failed.", res.Actions[1].Error)
+}
+
+func TestRunArchiveConfirmation(t *testing.T) {
+	provider := providertest.New(providertest.WithRepos("my-org/payments", "my-org/legacy"))
+	registry := providers.NewRegistry(providers.Github, nil)
+	registry.Register(providers.Github, provider)
+	service := services.NewRepositoryService(services.ReposDependencies{
+		Providers:    registry,
+		DeletePolicy: services.DeletePolicy{AllowedOrgs: []string{"my-org"}, RequireConfirmation: true},
+	})
+	path := writeManifest(t, "owner: my-org\narchive_missing: true\nrepositories:\n  - name: payments\n")
+
+	var stdout, stderr bytes.Buffer
+	assert.EqualValues(t, exitFailed, run([]string{"-f", path}, &stdout, &stderr, service))
+	assert.EqualValues(t, "confirmation required, set the X-Confirm-Delete header to my-org/*\n", stderr.String())
+
+	stdout.Reset()
+	assert.EqualValues(t, exitOk, run([]string{"-f", path, "-confirm", "my-org/*"}, &stdout, &stderr, service))
+	assert.Contains(t, stdout.String(), "archive  my-org/legacy")
+}
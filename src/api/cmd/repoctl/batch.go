@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"golang-microservices/src/api/domain/repositories"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// csvListSeparator splits the list columns of a csv batch file.
+const csvListSeparator = ";"
+
+// readBatchFile reads the repositories to create, the format follows the
+// file extension. Yaml and json files hold a list of creation requests.
+func readBatchFile(path string) ([]repositories.CreateRepoRequest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading batch file: %w", err)
+	}
+
+	var requests []repositories.CreateRepoRequest
+	switch extension := strings.ToLower(filepath.Ext(path)); extension {
+	case ".json":
+		err = json.Unmarshal(content, &requests)
+	case ".yaml", ".yml":
+		requests, err = parseYamlBatch(content)
+	case ".csv":
+		requests, err = parseCsvBatch(content)
+	default:
+		return nil, fmt.Errorf("unsupported batch file %s, expected .csv, .yaml or .json", extension)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch file %s: %w", path, err)
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no repositories in batch file %s", path)
+	}
+	return requests, nil
+}
+
+// parseYamlBatch goes through json so yaml keys match the json names of the
+// api, nested fields included.
+func parseYamlBatch(content []byte) ([]repositories.CreateRepoRequest, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(jsonCompatible(document))
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []repositories.CreateRepoRequest
+	if err := json.Unmarshal(payload, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// jsonCompatible turns the map[interface{}]interface{} yaml decodes into
+// values encoding/json accepts.
+func jsonCompatible(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			result[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return result
+	case []interface{}:
+		for i := range typed {
+			typed[i] = jsonCompatible(typed[i])
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// parseCsvBatch reads a csv file with a header row. Columns are name,
+// description, org, provider, private, team, protection, templates and
+// topics, the last two being lists separated by semicolons. private only
+// accepts true, repositories are always created private.
+func parseCsvBatch(content []byte) ([]repositories.CreateRepoRequest, error) {
+	reader := csv.NewReader(strings.NewReader(string(content)))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	requests := make([]repositories.CreateRepoRequest, 0, len(records)-1)
+	for line, record := range records[1:] {
+		var request repositories.CreateRepoRequest
+		for i, column := range header {
+			value := strings.TrimSpace(record[i])
+			if value == "" {
+				continue
+			}
+			switch column {
+			case "name":
+				request.Name = value
+			case "description":
+				request.Description = value
+			case "org":
+				request.Org = value
+			case "provider":
+				request.Provider = value
+			case "team":
+				request.Team = value
+			case "protection":
+				request.Protection = value
+			case "private":
+				if private, err := strconv.ParseBool(value); err != nil || !private {
+					return nil, fmt.Errorf("line %d: invalid private %s, repositories are always created private", line+2, value)
+				}
+			case "templates":
+				request.Templates = splitList(value)
+			case "topics":
+				request.Topics = splitList(value)
+			default:
+				return nil, fmt.Errorf("unknown column %s", column)
+			}
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+func splitList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, csvListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,322 @@
+// Command repoctl manages repositories from scripts. It calls the service at
+// -server, API_SERVICE_URL by default, and otherwise builds the repository
+// service in process from the environment, without the http layer and its
+// rate limits.
+//
+//	repoctl [-server URL] [-api-key KEY] [-provider NAME] [-o table|json] COMMAND
+//
+//	create NAME [-org ORG] [-desc TEXT] [-private]
+//	batch -f repos.csv|repos.yaml|repos.json
+//	get OWNER/NAME
+//	list -owner OWNER [-visibility all|public|private] [-page N] [-per-page N]
+//	delete OWNER/NAME [-archive] [-yes]
+//
+// It exits with 0 on success, 1 when the request or every batch item failed,
+// 2 on usage or configuration errors and 3 when a batch partially failed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"golang-microservices/src/api/app"
+	"golang-microservices/src/api/client"
+	"golang-microservices/src/api/config"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const exitOk = 0
+const exitFailed = 1
+const exitUsage = 2
+const exitPartial = 3
+
+const outputTable = "table"
+const outputJson = "json"
+
+// backend is the part of the repository service repoctl uses, the service
+// itself implements it when running in process.
+type backend interface {
+	CreateRepo(ctx context.Context, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
+	CreateRepos(ctx context.Context, input []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError)
+	GetRepo(ctx context.Context, provider string, owner string, name string) (*repositories.Repository, errors.ApiError)
+	ListRepos(ctx context.Context, provider string, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError)
+	DeleteRepo(ctx context.Context, input repositories.DeleteRepoRequest) (*repositories.DeleteRepoResponse, errors.ApiError)
+}
+
+// localBackend builds the in process backend, close flushes the audit log.
+type localBackend func() (service backend, close func() error, err error)
+
+type command struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	backend  backend
+	provider string
+	output   string
+}
+
+func main() {
+	logger.SetDefault(logger.New(os.Stderr, logger.LevelError))
+	cfg := config.Load()
+
+	local := func() (backend, func() error, error) {
+		service, auditLog, err := app.NewReposService(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return service, auditLog.Close, nil
+	}
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, cfg, local))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer, cfg config.Config, local localBackend) int {
+	flags := flag.NewFlagSet("repoctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	server := flags.String("server", cfg.ServiceUrl, "url of a running service, empty runs in process")
+	apiKey := flags.String("api-key", cfg.ServiceApiKey, "api key sent to the service")
+	provider := flags.String("provider", "", "repository provider, defaults to the configured one")
+	output := flags.String("o", outputTable, "output format, table or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *output != outputTable && *output != outputJson {
+		fmt.Fprintln(stderr, "invalid output "+*output+", expected table or json")
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "missing command, expected create, batch, get, list or delete")
+		return exitUsage
+	}
+
+	cmd := &command{stdout: stdout, stderr: stderr, provider: *provider, output: *output}
+	var handler func(args []string) int
+	switch name := flags.Arg(0); name {
+	case "create":
+		handler = cmd.create
+	case "batch":
+		handler = cmd.batch
+	case "get":
+		handler = cmd.get
+	case "list":
+		handler = cmd.list
+	case "delete":
+		handler = cmd.delete
+	default:
+		fmt.Fprintln(stderr, "unknown command "+name)
+		return exitUsage
+	}
+
+	if *server != "" {
+		cmd.backend = client.New(*server, client.WithApiKey(*apiKey))
+	} else {
+		service, closeBackend, err := local()
+		if err != nil {
+			fmt.Fprintln(stderr, "error when building repository service:", err)
+			return exitUsage
+		}
+		defer func() {
+			if err := closeBackend(); err != nil {
+				fmt.Fprintln(stderr, "error when flushing audit log:", err)
+			}
+		}()
+		cmd.backend = service
+	}
+
+	return handler(flags.Args()[1:])
+}
+
+// parseArgs parses flags placed before or after the positional arguments,
+// the flag package alone stops at the first positional one.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+func splitFullName(value string) (string, string, bool) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func (c *command) newFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("repoctl "+name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	return flags
+}
+
+func (c *command) fail(err errors.ApiError) int {
+	fmt.Fprintf(c.stderr, "error %d: %s\n", err.Status(), err.Message())
+	return exitFailed
+}
+
+func (c *command) create(args []string) int {
+	flags := c.newFlags("create")
+	org := flags.String("org", "", "owner of the repository")
+	description := flags.String("desc", "", "description of the repository")
+	private := flags.Bool("private", true, "create a private repository, the only visibility supported")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(c.stderr, "usage: repoctl create NAME [-org ORG] [-desc TEXT] [-private]")
+		return exitUsage
+	}
+	if !*private {
+		fmt.Fprintln(c.stderr, "repositories are always created private")
+		return exitUsage
+	}
+
+	res, apiErr := c.backend.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:        positional[0],
+		Description: *description,
+		Org:         *org,
+		Provider:    c.provider,
+	})
+	if apiErr != nil {
+		return c.fail(apiErr)
+	}
+	c.printCreated(res)
+	return exitOk
+}
+
+func (c *command) batch(args []string) int {
+	flags := c.newFlags("batch")
+	file := flags.String("f", "", "csv, yaml or json file listing the repositories")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if *file == "" || len(positional) != 0 {
+		fmt.Fprintln(c.stderr, "usage: repoctl batch -f repos.csv|repos.yaml|repos.json")
+		return exitUsage
+	}
+
+	requests, err := readBatchFile(*file)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitUsage
+	}
+	for i := range requests {
+		if requests[i].Provider == "" {
+			requests[i].Provider = c.provider
+		}
+	}
+
+	res, apiErr := c.backend.CreateRepos(context.Background(), requests)
+	if apiErr != nil {
+		return c.fail(apiErr)
+	}
+	c.printBatch(res)
+
+	switch res.StatusCode {
+	case http.StatusCreated:
+		return exitOk
+	case http.StatusPartialContent:
+		return exitPartial
+	default:
+		return exitFailed
+	}
+}
+
+func (c *command) get(args []string) int {
+	flags := c.newFlags("get")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	owner, name, ok := "", "", len(positional) == 1
+	if ok {
+		owner, name, ok = splitFullName(positional[0])
+	}
+	if !ok {
+		fmt.Fprintln(c.stderr, "usage: repoctl get OWNER/NAME")
+		return exitUsage
+	}
+
+	repo, apiErr := c.backend.GetRepo(context.Background(), c.provider, owner, name)
+	if apiErr != nil {
+		return c.fail(apiErr)
+	}
+	c.printRepositories([]repositories.Repository{*repo}, repo)
+	return exitOk
+}
+
+func (c *command) list(args []string) int {
+	flags := c.newFlags("list")
+	owner := flags.String("owner", "", "owner of the repositories")
+	visibility := flags.String("visibility", "", "all, public or private")
+	page := flags.Int("page", 0, "page to list")
+	perPage := flags.Int("per-page", 0, "repositories per page, at most 100")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if *owner == "" || len(positional) != 0 {
+		fmt.Fprintln(c.stderr, "usage: repoctl list -owner OWNER [-visibility all|public|private] [-page N] [-per-page N]")
+		return exitUsage
+	}
+
+	res, apiErr := c.backend.ListRepos(context.Background(), c.provider, repositories.ListReposOptions{
+		Owner:      *owner,
+		Visibility: *visibility,
+		Page:       *page,
+		PerPage:    *perPage,
+	})
+	if apiErr != nil {
+		return c.fail(apiErr)
+	}
+	c.printRepositories(res.Repositories, res)
+	if c.output == outputTable && res.NextPage > 0 {
+		fmt.Fprintf(c.stdout, "more repositories on page %d\n", res.NextPage)
+	}
+	return exitOk
+}
+
+func (c *command) delete(args []string) int {
+	flags := c.newFlags("delete")
+	archive := flags.Bool("archive", false, "archive instead of deleting")
+	yes := flags.Bool("yes", false, "confirm the removal")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	owner, name, ok := "", "", len(positional) == 1
+	if ok {
+		owner, name, ok = splitFullName(positional[0])
+	}
+	if !ok {
+		fmt.Fprintln(c.stderr, "usage: repoctl delete OWNER/NAME [-archive] [-yes]")
+		return exitUsage
+	}
+
+	request := repositories.DeleteRepoRequest{Provider: c.provider, Owner: owner, Name: name, Archive: *archive}
+	if *yes {
+		request.Confirm = request.ConfirmationToken()
+	}
+	res, apiErr := c.backend.DeleteRepo(context.Background(), request)
+	if apiErr != nil {
+		if apiErr.Status() == http.StatusPreconditionRequired {
+			fmt.Fprintln(c.stderr, "removal has to be confirmed, run again with -yes")
+		}
+		return c.fail(apiErr)
+	}
+	c.printDeleted(res)
+	return exitOk
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/config"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/utils/errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeBackend struct {
+	created []repositories.CreateRepoRequest
+	deleted []repositories.DeleteRepoRequest
+	closed  bool
+}
+
+func (b *fakeBackend) CreateRepo(ctx context.Context, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	if input.Name == "broken" {
+		return nil, errors.NewApiError(http.StatusUnprocessableEntity, "Repository creation failed.")
+	}
+	b.created = append(b.created, input)
+	return &repositories.CreateRepoResponse{Id: int64(len(b.created)), Owner: input.Org, Name: input.Name,
+		FullName: input.Org + "/" + input.Name, Provider: "github"}, nil
+}
+
+func (b *fakeBackend) CreateRepos(ctx context.Context, input []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError) {
+	result := &repositories.CreateReposResponse{BatchId: "abc"}
+	successes := 0
+	for _, request := range input {
+		res, err := b.CreateRepo(ctx, request)
+		if err == nil {
+			successes++
+		}
+		result.Results = append(result.Results, repositories.CreateRepositoresResult{Response: res, Error: err})
+	}
+	switch successes {
+	case 0:
+		result.StatusCode = http.StatusUnprocessableEntity
+	case len(input):
+		result.StatusCode = http.StatusCreated
+	default:
+		result.StatusCode = http.StatusPartialContent
+	}
+	return result, nil
+}
+
+func (b *fakeBackend) GetRepo(ctx context.Context, provider string, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	if name == "missing" {
+		return nil, errors.NewNotFoundApiError("Not Found")
+	}
+	return &repositories.Repository{Owner: owner, Name: name, FullName: owner + "/" + name, Private: true, DefaultBranch: "main"}, nil
+}
+
+func (b *fakeBackend) ListRepos(ctx context.Context, provider string, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	return &repositories.RepositoryPage{
+		Repositories: []repositories.Repository{{Owner: options.Owner, Name: "one", FullName: options.Owner + "/one"}},
+		Page:         1,
+		NextPage:     2,
+	}, nil
+}
+
+func (b *fakeBackend) DeleteRepo(ctx context.Context, input repositories.DeleteRepoRequest) (*repositories.DeleteRepoResponse, errors.ApiError) {
+	if input.Confirm != input.ConfirmationToken() {
+		return nil, errors.NewApiError(http.StatusPreconditionRequired, "confirmation required")
+	}
+	b.deleted = append(b.deleted, input)
+	return &repositories.DeleteRepoResponse{Owner: input.Owner, Name: input.Name, FullName: input.ConfirmationToken(), Action: repositories.ActionArchived}, nil
+}
+
+func runLocal(t *testing.T, fake *fakeBackend, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	local := func() (backend, func() error, error) {
+		return fake, func() error { fake.closed = true; return nil }, nil
+	}
+	code := run(args, &stdout, &stderr, config.Default(), local)
+	return code, stdout.String(), stderr.String()
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestRunUsage(t *testing.T) {
+	code, _, stderr := runLocal(t, &fakeBackend{})
+	assert.EqualValues(t, exitUsage, code)
+	assert.EqualValues(t, "missing command, expected create, batch, get, list or delete\n", stderr)
+
+	code, _, stderr = runLocal(t, &fakeBackend{}, "-o", "yaml", "get", "my-org/one")
+	assert.EqualValues(t, exitUsage, code)
+	assert.EqualValues(t, "invalid output yaml, expected table or json\n", stderr)
+
+	code, _, stderr = runLocal(t, &fakeBackend{}, "get", "one")
+	assert.EqualValues(t, exitUsage, code)
+	assert.EqualValues(t, "usage: repoctl get OWNER/NAME\n", stderr)
+
+	code, _, _ = runLocal(t, &fakeBackend{}, "rename")
+	assert.EqualValues(t, exitUsage, code)
+}
+
+func TestRunCreate(t *testing.T) {
+	backend := &fakeBackend{}
+	code, stdout, _ := runLocal(t, backend, "-provider", "github", "create", "payments", "--desc", "Payments API", "--org", "my-org", "--private")
+	assert.EqualValues(t, exitOk, code)
+	assert.True(t, backend.closed)
+	assert.EqualValues(t, "payments", backend.created[0].Name)
+	assert.EqualValues(t, "Payments API", backend.created[0].Description)
+	assert.EqualValues(t, "github", backend.created[0].Provider)
+	assert.EqualValues(t, ""+
+		"ID  REPOSITORY       PROVIDER  WARNINGS\n"+
+		"1   my-org/payments  github    \n", stdout)
+}
+
+func TestRunCreatePublic(t *testing.T) {
+	backend := &fakeBackend{}
+	code, _, stderr := runLocal(t, backend, "create", "payments", "--private=false")
+	assert.EqualValues(t, exitUsage, code)
+	assert.EqualValues(t, "repositories are always created private\n", stderr)
+	assert.Empty(t, backend.created)
+}
+
+func TestRunCreateFailed(t *testing.T) {
+	code, _, stderr := runLocal(t, &fakeBackend{}, "create", "broken")
+	assert.EqualValues(t, exitFailed, code)
+	assert.EqualValues(t, "error 422: Repository creation failed.\n", stderr)
+}
+
+func TestRunBatchFormats(t *testing.T) {
+	files := map[string]string{
+		"repos.json": `[{"name": "one", "org": "my-org"}, {"name": "two", "org": "my-org", "topics": ["go"]}]`,
+		"repos.yaml": "- name: one\n  org: my-org\n- name: two\n  org: my-org\n  topics: [go]\n",
+		"repos.csv":  "name,org,topics,private\none,my-org,,\ntwo,my-org,go,true\n",
+	}
+	for name, content := range files {
+		backend := &fakeBackend{}
+		code, stdout, _ := runLocal(t, backend, "batch", "-f", writeFile(t, name, content))
+		assert.EqualValues(t, exitOk, code, name)
+		assert.EqualValues(t, 2, len(backend.created), name)
+		assert.EqualValues(t, []string{"go"}, backend.created[1].Topics, name)
+		assert.Contains(t, stdout, "batch abc finished with status 201\n", name)
+	}
+}
+
+func TestRunBatchExitCodes(t *testing.T) {
+	path := writeFile(t, "repos.json", `[{"name": "one", "org": "my-org"}, {"name": "broken"}]`)
+	code, stdout, _ := runLocal(t, &fakeBackend{}, "batch", "-f", path)
+	assert.EqualValues(t, exitPartial, code)
+	assert.EqualValues(t, ""+
+		"STATUS  REPOSITORY  DETAILS\n"+
+		"201     my-org/one  \n"+
+		"422     -           Repository creation failed.\n"+
+		"batch abc finished with status 206\n", stdout)
+
+	path = writeFile(t, "repos.json", `[{"name": "broken"}]`)
+	code, _, _ = runLocal(t, &fakeBackend{}, "-o", "json", "batch", "-f", path)
+	assert.EqualValues(t, exitFailed, code)
+
+	code, _, stderr := runLocal(t, &fakeBackend{}, "batch", "-f", writeFile(t, "repos.csv", "name,colour\none,red\n"))
+	assert.EqualValues(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown column colour")
+
+	code, _, stderr = runLocal(t, &fakeBackend{}, "batch", "-f", writeFile(t, "repos.csv", "name,private\none,false\n"))
+	assert.EqualValues(t, exitUsage, code)
+	assert.Contains(t, stderr, "line 2: invalid private false, repositories are always created private")
+
+	code, _, stderr = runLocal(t, &fakeBackend{}, "batch", "-f", writeFile(t, "repos.yaml", "[]"))
+	assert.EqualValues(t, exitUsage, code)
+	assert.Contains(t, stderr, "no repositories in batch file")
+}
+
+func TestRunGetAndList(t *testing.T) {
+	code, stdout, _ := runLocal(t, &fakeBackend{}, "-o", "json", "get", "my-org/one")
+	assert.EqualValues(t, exitOk, code)
+	var repo repositories.Repository
+	assert.Nil(t, json.Unmarshal([]byte(stdout), &repo))
+	assert.EqualValues(t, "my-org/one", repo.FullName)
+
+	code, _, stderr := runLocal(t, &fakeBackend{}, "get", "my-org/missing")
+	assert.EqualValues(t, exitFailed, code)
+	assert.EqualValues(t, "error 404: Not Found\n", stderr)
+
+	code, stdout, _ = runLocal(t, &fakeBackend{}, "list", "-owner", "my-org")
+	assert.EqualValues(t, exitOk, code)
+	assert.EqualValues(t, ""+
+		"REPOSITORY  PRIVATE  ARCHIVED  DEFAULT BRANCH  URL\n"+
+		"my-org/one  false    false                     \n"+
+		"more repositories on page 2\n", stdout)
+}
+
+func TestRunDelete(t *testing.T) {
+	backend := &fakeBackend{}
+	code, _, stderr := runLocal(t, backend, "delete", "my-org/one")
+	assert.EqualValues(t, exitFailed, code)
+	assert.Contains(t, stderr, "run again with -yes")
+
+	code, stdout, _ := runLocal(t, backend, "delete", "my-org/one", "-archive", "-yes")
+	assert.EqualValues(t, exitOk, code)
+	assert.True(t, backend.deleted[0].Archive)
+	assert.Contains(t, stdout, "my-org/one  archived")
+}
+
+func newRemoteServer(t *testing.T, handler http.HandlerFunc) config.Config {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := config.Default()
+	cfg.ServiceUrl = server.URL
+	cfg.ServiceApiKey = "key-a"
+	return cfg
+}
+
+func runRemote(cfg config.Config, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	local := func() (backend, func() error, error) {
+		panic("the remote mode must not build the service")
+	}
+	code := run(args, &stdout, &stderr, cfg, local)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRemoteCreate(t *testing.T) {
+	cfg := newRemoteServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.EqualValues(t, http.MethodPost, r.Method)
+		assert.EqualValues(t, "/repository", r.URL.Path)
+		assert.EqualValues(t, "key-a", r.Header.Get("X-Api-Key"))
+
+		var request repositories.CreateRepoRequest
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.EqualValues(t, "payments", request.Name)
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"id": 7, "owner": "my-org", "name": "payments", "full_name": "my-org/payments", "provider": "github"}`)
+	})
+
+	code, stdout, _ := runRemote(cfg, "create", "payments", "--private")
+	assert.EqualValues(t, exitOk, code)
+	assert.Contains(t, stdout, "7   my-org/payments  github")
+}
+
+func TestRemoteBatchStatus(t *testing.T) {
+	cfg := newRemoteServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = io.WriteString(w, `{"status": 206, "batch_id": "abc", "results": [
+			{"response": {"id": 1, "owner": "my-org", "name": "one", "full_name": "my-org/one"}, "error": null},
+			{"response": null, "error": {"status": 422, "message": "Repository creation failed."}}
+		]}`)
+	})
+
+	code, stdout, _ := runRemote(cfg, "batch", "-f", writeFile(t, "repos.yaml", "- name: one\n- name: two\n"))
+	assert.EqualValues(t, exitPartial, code)
+	assert.Contains(t, stdout, "422     -           Repository creation failed.")
+}
+
+func TestRemoteApiError(t *testing.T) {
+	cfg := newRemoteServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"status": 403, "message": "missing scope repos:batch"}`)
+	})
+
+	code, _, stderr := runRemote(cfg, "batch", "-f", writeFile(t, "repos.json", `[{"name": "one"}]`))
+	assert.EqualValues(t, exitFailed, code)
+	assert.EqualValues(t, "error 403: missing scope repos:batch\n", stderr)
+}
+
+func TestRemoteListAndDelete(t *testing.T) {
+	cfg := newRemoteServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			assert.EqualValues(t, "/repositories", r.URL.Path)
+			assert.EqualValues(t, "owner=my-org&page=2&provider=gitlab", r.URL.RawQuery)
+			_, _ = io.WriteString(w, `{"repositories": [{"full_name": "my-org/one", "private": true}], "page": 2}`)
+		case http.MethodDelete:
+			assert.EqualValues(t, "/repository/my-org/one", r.URL.Path)
+			assert.EqualValues(t, "my-org/one", r.Header.Get("X-Confirm-Delete"))
+			_, _ = io.WriteString(w, `{"owner": "my-org", "name": "one", "full_name": "my-org/one", "action": "deleted"}`)
+		}
+	})
+
+	code, stdout, _ := runRemote(cfg, "-provider", "gitlab", "list", "-owner", "my-org", "-page", "2")
+	assert.EqualValues(t, exitOk, code)
+	assert.Contains(t, stdout, "my-org/one  true")
+
+	code, stdout, _ = runRemote(cfg, "delete", "my-org/one", "-yes")
+	assert.EqualValues(t, exitOk, code)
+	assert.Contains(t, stdout, "my-org/one  deleted")
+}
+
+func TestRemoteUnreachable(t *testing.T) {
+	cfg := config.Default()
+	cfg.ServiceUrl = "http://127.0.0.1:1"
+
+	code, _, stderr := runRemote(cfg, "get", "my-org/one")
+	assert.EqualValues(t, exitFailed, code)
+	assert.Contains(t, stderr, "error 503: error when calling the service")
+}
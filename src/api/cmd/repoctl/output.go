@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang-microservices/src/api/domain/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func (c *command) printJson(value interface{}) {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+func (c *command) newTable(columns ...string) *tabwriter.Writer {
+	table := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, strings.Join(columns, "\t"))
+	return table
+}
+
+func (c *command) printCreated(res *repositories.CreateRepoResponse) {
+	if c.output == outputJson {
+		c.printJson(res)
+		return
+	}
+	table := c.newTable("ID", "REPOSITORY", "PROVIDER", "WARNINGS")
+	fmt.Fprintf(table, "%d\t%s\t%s\t%s\n", res.Id, res.FullName, res.Provider, strings.Join(res.Warnings, "; "))
+	_ = table.Flush()
+}
+
+// printBatch lists successes first, failed creations do not tell which
+// repository they were about.
+func (c *command) printBatch(res *repositories.CreateReposResponse) {
+	if c.output == outputJson {
+		c.printJson(res)
+		return
+	}
+	table := c.newTable("STATUS", "REPOSITORY", "DETAILS")
+	for _, result := range res.Results {
+		if result.Response != nil {
+			fmt.Fprintf(table, "%d\t%s\t%s\n", http.StatusCreated, result.Response.FullName, strings.Join(result.Response.Warnings, "; "))
+		}
+	}
+	for _, result := range res.Results {
+		if result.Error != nil {
+			fmt.Fprintf(table, "%d\t-\t%s\n", result.Error.Status(), result.Error.Message())
+		}
+	}
+	_ = table.Flush()
+	fmt.Fprintf(c.stdout, "batch %s finished with status %d\n", res.BatchId, res.StatusCode)
+}
+
+// printRepositories prints repos as a table, or value as json.
+func (c *command) printRepositories(repos []repositories.Repository, value interface{}) {
+	if c.output == outputJson {
+		c.printJson(value)
+		return
+	}
+	table := c.newTable("REPOSITORY", "PRIVATE", "ARCHIVED", "DEFAULT BRANCH", "URL")
+	for _, repo := range repos {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", repo.FullName, strconv.FormatBool(repo.Private),
+			strconv.FormatBool(repo.Archived), repo.DefaultBranch, repo.HtmlUrl)
+	}
+	_ = table.Flush()
+}
+
+func (c *command) printDeleted(res *repositories.DeleteRepoResponse) {
+	if c.output == outputJson {
+		c.printJson(res)
+		return
+	}
+	table := c.newTable("REPOSITORY", "ACTION")
+	fmt.Fprintf(table, "%s\t%s\n", res.FullName, res.Action)
+	_ = table.Flush()
+}
@@ -1,20 +1,427 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/ratelimit"
+	"golang-microservices/src/api/utils/logger"
+	"net"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const apiGithubAccessToken = "SECRET_API_GITHUB_ACCESS_TOKEN"
+const apiGithubBaseUrl = "API_GITHUB_BASE_URL"
+const apiGithubCaBundle = "API_GITHUB_CA_BUNDLE"
+const apiGithubApiVersion = "API_GITHUB_API_VERSION"
+const apiGitlabAccessToken = "SECRET_API_GITLAB_ACCESS_TOKEN"
+const apiGitlabBaseUrl = "API_GITLAB_BASE_URL"
+const apiGitlabCaBundle = "API_GITLAB_CA_BUNDLE"
+const apiGiteaAccessToken = "SECRET_API_GITEA_ACCESS_TOKEN"
+const apiGiteaBaseUrl = "API_GITEA_BASE_URL"
+const apiGiteaCaBundle = "API_GITEA_CA_BUNDLE"
+const apiDefaultProvider = "API_DEFAULT_PROVIDER"
+const apiOrgProviders = "API_ORG_PROVIDERS"
+const apiAuthKeysFile = "API_AUTH_KEYS_FILE"
+const apiAuthJwtSecret = "SECRET_API_AUTH_JWT_SECRET"
+const apiAuthJwksFile = "API_AUTH_JWKS_FILE"
+const apiRateLimitRps = "API_RATE_LIMIT_RPS"
+const apiRateLimitBurst = "API_RATE_LIMIT_BURST"
+const apiRateLimitIpRps = "API_RATE_LIMIT_IP_RPS"
+const apiRateLimitIpBurst = "API_RATE_LIMIT_IP_BURST"
+const apiTrustedProxies = "API_TRUSTED_PROXIES"
+const apiDailyRepoQuota = "API_DAILY_REPO_QUOTA"
+const apiAuditFile = "API_AUDIT_FILE"
+const apiDeleteAllowedOrgs = "API_DELETE_ALLOWED_ORGS"
+const apiDeleteTopicMarker = "API_DELETE_TOPIC_MARKER"
+const apiDeleteArchiveOnly = "API_DELETE_ARCHIVE_ONLY"
+const apiDeleteRequireConfirmation = "API_DELETE_REQUIRE_CONFIRMATION"
+const apiTemplatesDir = "API_TEMPLATES_DIR"
+const apiSecretsFile = "API_SECRETS_FILE"
+const apiProtectionPoliciesFile = "API_PROTECTION_POLICIES_FILE"
+const apiDefaultProtectionPolicy = "API_DEFAULT_PROTECTION_POLICY"
+const apiLabelSetsFile = "API_LABEL_SETS_FILE"
+const apiLogLevel = "LOG_LEVEL"
+const apiTracingExporter = "TRACING_EXPORTER"
+const apiListenAddr = "PORT"
+const apiShutdownTimeout = "API_SHUTDOWN_TIMEOUT"
+const apiShutdownDrainDelay = "API_SHUTDOWN_DRAIN_DELAY"
 
-var githubAccessToken = os.Getenv(apiGithubAccessToken)
+// apiServiceUrl and apiServiceApiKey point the command line tools at a running
+// service, without them the tools run the services in process.
+const apiServiceUrl = "API_SERVICE_URL"
+const apiServiceApiKey = "SECRET_API_SERVICE_API_KEY"
 
-func init() {
-	if githubAccessToken == "" {
-		log.Println("WARNING: githubAccessToken is empty")
+const defaultGithubBaseUrl = "https://api.github.com"
+const defaultGithubApiVersion = "2022-11-28"
+const defaultRateLimitRps = 5
+const defaultRateLimitBurst = 10
+const defaultRateLimitIpRps = 20
+const defaultRateLimitIpBurst = 40
+const defaultDailyRepoQuota = 100
+const defaultAuditFile = "audit.jsonl"
+const defaultListenPort = "8080"
+const defaultShutdownTimeout = 30 * time.Second
+
+type Config struct {
+	GithubAccessToken         string
+	GithubBaseUrl             string
+	GithubCaBundle            string
+	GithubApiVersion          string
+	GitlabAccessToken         string
+	GitlabBaseUrl             string
+	GitlabCaBundle            string
+	GiteaAccessToken          string
+	GiteaBaseUrl              string
+	GiteaCaBundle             string
+	DefaultProvider           string
+	OrgProviders              map[string]string
+	AuthKeysFile              string
+	AuthJwtSecret             string
+	AuthJwksFile              string
+	RateLimitRps              float64
+	RateLimitBurst            int
+	RateLimitIpRps            float64
+	RateLimitIpBurst          int
+	TrustedProxies            []string
+	DailyRepoQuota            int
+	AuditFile                 string
+	DeleteAllowedOrgs         []string
+	DeleteTopicMarker         string
+	DeleteArchiveOnly         bool
+	DeleteRequireConfirmation bool
+	TemplatesDir              string
+	SecretsFile               string
+	ProtectionPoliciesFile    string
+	DefaultProtectionPolicy   string
+	LabelSetsFile             string
+	LogLevel                  string
+	TracingExporter           string
+	ListenPort                string
+	ShutdownTimeout           time.Duration
+	ShutdownDrainDelay        time.Duration
+	ServiceUrl                string
+	ServiceApiKey             string
+}
+
+func Default() Config {
+	return Config{
+		GithubBaseUrl:             defaultGithubBaseUrl,
+		GithubApiVersion:          defaultGithubApiVersion,
+		DefaultProvider:           providers.Github,
+		OrgProviders:              map[string]string{},
+		RateLimitRps:              defaultRateLimitRps,
+		RateLimitBurst:            defaultRateLimitBurst,
+		RateLimitIpRps:            defaultRateLimitIpRps,
+		RateLimitIpBurst:          defaultRateLimitIpBurst,
+		TrustedProxies:            []string{},
+		DailyRepoQuota:            defaultDailyRepoQuota,
+		AuditFile:                 defaultAuditFile,
+		DeleteAllowedOrgs:         []string{},
+		DeleteRequireConfirmation: true,
+		LogLevel:                  logger.LevelInfo.String(),
+		ListenPort:                defaultListenPort,
+		ShutdownTimeout:           defaultShutdownTimeout,
+	}
+}
+
+func Load() Config {
+	defaults := Default()
+
+	result := Config{
+		GithubAccessToken:         os.Getenv(apiGithubAccessToken),
+		GithubBaseUrl:             getEnv(apiGithubBaseUrl, defaults.GithubBaseUrl),
+		GithubCaBundle:            os.Getenv(apiGithubCaBundle),
+		GithubApiVersion:          getEnv(apiGithubApiVersion, defaults.GithubApiVersion),
+		GitlabAccessToken:         os.Getenv(apiGitlabAccessToken),
+		GitlabBaseUrl:             os.Getenv(apiGitlabBaseUrl),
+		GitlabCaBundle:            os.Getenv(apiGitlabCaBundle),
+		GiteaAccessToken:          os.Getenv(apiGiteaAccessToken),
+		GiteaBaseUrl:              os.Getenv(apiGiteaBaseUrl),
+		GiteaCaBundle:             os.Getenv(apiGiteaCaBundle),
+		DefaultProvider:           strings.ToLower(getEnv(apiDefaultProvider, defaults.DefaultProvider)),
+		OrgProviders:              parseOrgProviders(os.Getenv(apiOrgProviders)),
+		AuthKeysFile:              os.Getenv(apiAuthKeysFile),
+		AuthJwtSecret:             os.Getenv(apiAuthJwtSecret),
+		AuthJwksFile:              os.Getenv(apiAuthJwksFile),
+		RateLimitRps:              getEnvFloat(apiRateLimitRps, defaults.RateLimitRps),
+		RateLimitBurst:            getEnvInt(apiRateLimitBurst, defaults.RateLimitBurst),
+		RateLimitIpRps:            getEnvFloat(apiRateLimitIpRps, defaults.RateLimitIpRps),
+		RateLimitIpBurst:          getEnvInt(apiRateLimitIpBurst, defaults.RateLimitIpBurst),
+		TrustedProxies:            parseList(os.Getenv(apiTrustedProxies)),
+		DailyRepoQuota:            getEnvInt(apiDailyRepoQuota, defaults.DailyRepoQuota),
+		AuditFile:                 getEnv(apiAuditFile, defaults.AuditFile),
+		DeleteAllowedOrgs:         parseList(os.Getenv(apiDeleteAllowedOrgs)),
+		DeleteTopicMarker:         strings.ToLower(os.Getenv(apiDeleteTopicMarker)),
+		DeleteArchiveOnly:         getEnvBool(apiDeleteArchiveOnly, defaults.DeleteArchiveOnly),
+		DeleteRequireConfirmation: getEnvBool(apiDeleteRequireConfirmation, defaults.DeleteRequireConfirmation),
+		TemplatesDir:              os.Getenv(apiTemplatesDir),
+		SecretsFile:               os.Getenv(apiSecretsFile),
+		ProtectionPoliciesFile:    os.Getenv(apiProtectionPoliciesFile),
+		DefaultProtectionPolicy:   strings.TrimSpace(os.Getenv(apiDefaultProtectionPolicy)),
+		LabelSetsFile:             os.Getenv(apiLabelSetsFile),
+		LogLevel:                  getEnv(apiLogLevel, defaults.LogLevel),
+		TracingExporter:           os.Getenv(apiTracingExporter),
+		ListenPort:                getEnv(apiListenAddr, defaults.ListenPort),
+		ShutdownTimeout:           getEnvDuration(apiShutdownTimeout, defaults.ShutdownTimeout),
+		ShutdownDrainDelay:        getEnvDuration(apiShutdownDrainDelay, defaults.ShutdownDrainDelay),
+		ServiceUrl:                strings.TrimSuffix(strings.TrimSpace(os.Getenv(apiServiceUrl)), "/"),
+		ServiceApiKey:             os.Getenv(apiServiceApiKey),
+	}
+
+	if result.GithubAccessToken == "" {
+		logger.Default().Warn("githubAccessToken is empty")
+	}
+	if _, ok := logger.ParseLevel(result.LogLevel); !ok {
+		logger.Default().Warn(fmt.Sprintf("invalid log level %s, using info", result.LogLevel))
+	}
+	if !result.HasApiCredentials() {
+		logger.Default().Warn("no api keys or jwt keys configured, every api call will be rejected")
+	}
+
+	return result
+}
+
+func getEnv(key string, defaultValue string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// parseOrgProviders reads a comma separated list of org=provider pairs.
+func parseOrgProviders(value string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			logger.Default().Warn(fmt.Sprintf("invalid entry %s in %s, expected org=provider", pair, apiOrgProviders))
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = strings.ToLower(strings.TrimSpace(parts[1]))
+	}
+	return result
+}
+
+func parseList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		logger.Default().Warn(fmt.Sprintf("invalid boolean in %s, using %t", key, defaultValue))
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, strconv.Itoa(defaultValue)))
+	if err != nil {
+		logger.Default().Warn(fmt.Sprintf("invalid integer in %s, using %d", key, defaultValue))
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(getEnv(key, strconv.FormatFloat(defaultValue, 'f', -1, 64)), 64)
+	if err != nil {
+		logger.Default().Warn(fmt.Sprintf("invalid number in %s, using %g", key, defaultValue))
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, defaultValue.String()))
+	if err != nil {
+		logger.Default().Warn(fmt.Sprintf("invalid duration in %s, using %s", key, defaultValue))
+		return defaultValue
+	}
+	return value
+}
+
+func (c Config) GetGithubBaseUrl() string {
+	return strings.TrimRight(c.GithubBaseUrl, "/")
+}
+
+func (c Config) GetGitlabBaseUrl() string {
+	return strings.TrimRight(c.GitlabBaseUrl, "/")
+}
+
+func (c Config) GetGiteaBaseUrl() string {
+	return strings.TrimRight(c.GiteaBaseUrl, "/")
+}
+
+// EnabledProviders lists the providers that can be used, GitHub is always
+// available while GitLab and Gitea need their base url.
+func (c Config) EnabledProviders() []string {
+	result := []string{providers.Github}
+	if c.GitlabBaseUrl != "" {
+		result = append(result, providers.Gitlab)
 	}
+	if c.GiteaBaseUrl != "" {
+		result = append(result, providers.Gitea)
+	}
+	return result
+}
+
+// CaBundle is the extra CA bundle trusted when calling the given provider,
+// empty for the system roots only.
+func (c Config) CaBundle(provider string) string {
+	switch provider {
+	case providers.Gitlab:
+		return c.GitlabCaBundle
+	case providers.Gitea:
+		return c.GiteaCaBundle
+	default:
+		return c.GithubCaBundle
+	}
+}
+
+func (c Config) isEnabledProvider(name string) bool {
+	for _, enabled := range c.EnabledProviders() {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidUrl(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && parsed.Scheme != "" && parsed.Host != ""
+}
+
+func (c Config) GetLogLevel() logger.Level {
+	level, _ := logger.ParseLevel(c.LogLevel)
+	return level
+}
+
+func (c Config) GetListenAddr() string {
+	return ":" + strings.TrimPrefix(c.ListenPort, ":")
+}
+
+// CallerRateLimit applies to each authenticated caller.
+func (c Config) CallerRateLimit() ratelimit.Limit {
+	return ratelimit.Limit{Rate: c.RateLimitRps, Burst: c.RateLimitBurst}
+}
+
+// IpRateLimit applies to each client ip before authentication.
+func (c Config) IpRateLimit() ratelimit.Limit {
+	return ratelimit.Limit{Rate: c.RateLimitIpRps, Burst: c.RateLimitIpBurst}
+}
+
+func (c Config) HasApiCredentials() bool {
+	return c.AuthKeysFile != "" || c.AuthJwtSecret != "" || c.AuthJwksFile != ""
+}
+
+func (c Config) Validate() error {
+	problems := make([]string, 0)
+
+	if !isValidUrl(c.GetGithubBaseUrl()) {
+		problems = append(problems, "invalid "+apiGithubBaseUrl)
+	}
+	if c.GitlabBaseUrl != "" && !isValidUrl(c.GetGitlabBaseUrl()) {
+		problems = append(problems, "invalid "+apiGitlabBaseUrl)
+	}
+	if c.GiteaBaseUrl != "" && !isValidUrl(c.GetGiteaBaseUrl()) {
+		problems = append(problems, "invalid "+apiGiteaBaseUrl)
+	}
+	if !c.isEnabledProvider(c.DefaultProvider) {
+		problems = append(problems, "default provider "+c.DefaultProvider+" is not configured")
+	}
+	for _, org := range sortedKeys(c.OrgProviders) {
+		if !c.isEnabledProvider(c.OrgProviders[org]) {
+			problems = append(problems, "provider "+c.OrgProviders[org]+" of org "+org+" is not configured")
+		}
+	}
+	for _, bundle := range []struct{ path, env string }{
+		{c.GithubCaBundle, apiGithubCaBundle},
+		{c.GitlabCaBundle, apiGitlabCaBundle},
+		{c.GiteaCaBundle, apiGiteaCaBundle},
+	} {
+		if bundle.path == "" {
+			continue
+		}
+		if _, err := os.Stat(bundle.path); err != nil {
+			problems = append(problems, "unreadable "+bundle.env)
+		}
+	}
+	if c.TemplatesDir != "" {
+		if info, err := os.Stat(c.TemplatesDir); err != nil || !info.IsDir() {
+			problems = append(problems, "unreadable "+apiTemplatesDir)
+		}
+	}
+	if c.SecretsFile != "" {
+		if _, err := os.Stat(c.SecretsFile); err != nil {
+			problems = append(problems, "unreadable "+apiSecretsFile)
+		}
+	}
+	if c.ProtectionPoliciesFile != "" {
+		if _, err := os.Stat(c.ProtectionPoliciesFile); err != nil {
+			problems = append(problems, "unreadable "+apiProtectionPoliciesFile)
+		}
+	} else if c.DefaultProtectionPolicy != "" {
+		problems = append(problems, apiDefaultProtectionPolicy+" requires "+apiProtectionPoliciesFile)
+	}
+	if c.LabelSetsFile != "" {
+		if _, err := os.Stat(c.LabelSetsFile); err != nil {
+			problems = append(problems, "unreadable "+apiLabelSetsFile)
+		}
+	}
+	if !c.HasApiCredentials() {
+		problems = append(problems, "no api keys or jwt keys configured")
+	}
+	if _, ok := logger.ParseLevel(c.LogLevel); !ok {
+		problems = append(problems, "invalid "+apiLogLevel)
+	}
+	if c.CallerRateLimit().Validate() != nil || c.IpRateLimit().Validate() != nil {
+		problems = append(problems, "rate limit must be positive")
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problems = append(problems, "invalid "+apiTrustedProxies)
+			break
+		}
+	}
+	if c.ShutdownTimeout <= 0 || c.ShutdownDrainDelay < 0 {
+		problems = append(problems, "shutdown timeout must be positive")
+	}
+	if c.ServiceUrl != "" && !isValidUrl(c.ServiceUrl) {
+		problems = append(problems, "invalid "+apiServiceUrl)
+	}
+	if c.DailyRepoQuota < 0 {
+		problems = append(problems, "daily repository quota must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
 }
 
-func GetGithubAccessToken() string {
-	return githubAccessToken
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
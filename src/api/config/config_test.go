@@ -0,0 +1,99 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/providers"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	t.Setenv("API_GITHUB_BASE_URL", "https://ghe.corp/api/v3/")
+	t.Setenv("API_DAILY_REPO_QUOTA", "not a number")
+	t.Setenv("API_SHUTDOWN_TIMEOUT", "5s")
+	t.Setenv("PORT", "9090")
+	t.Setenv("API_DEFAULT_PROVIDER", "GitLab")
+	t.Setenv("API_ORG_PROVIDERS", "platform=gitlab, tools = Gitea,broken")
+	t.Setenv("API_DELETE_ALLOWED_ORGS", "sandbox, ci-scratch,")
+	t.Setenv("API_DELETE_REQUIRE_CONFIRMATION", "maybe")
+	t.Setenv("API_DELETE_ARCHIVE_ONLY", "true")
+	t.Setenv("API_SERVICE_URL", "https://repos.corp/ ")
+
+	cfg := Load()
+
+	assert.EqualValues(t, "https://ghe.corp/api/v3", cfg.GetGithubBaseUrl())
+	assert.EqualValues(t, 100, cfg.DailyRepoQuota)
+	assert.EqualValues(t, 5*time.Second, cfg.ShutdownTimeout)
+	assert.EqualValues(t, ":9090", cfg.GetListenAddr())
+	assert.EqualValues(t, "gitlab", cfg.DefaultProvider)
+	assert.EqualValues(t, map[string]string{"platform": "gitlab", "tools": "gitea"}, cfg.OrgProviders)
+	assert.EqualValues(t, []string{"sandbox", "ci-scratch"}, cfg.DeleteAllowedOrgs)
+	assert.True(t, cfg.DeleteRequireConfirmation)
+	assert.True(t, cfg.DeleteArchiveOnly)
+	assert.EqualValues(t, "https://repos.corp", cfg.ServiceUrl)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	assert.EqualValues(t, "no api keys or jwt keys configured", cfg.Validate().Error())
+
+	cfg.AuthJwtSecret = "jwt-secret"
+	assert.Nil(t, cfg.Validate())
+
+	cfg.TemplatesDir = filepath.Join(t.TempDir(), "missing")
+	assert.EqualValues(t, "unreadable API_TEMPLATES_DIR", cfg.Validate().Error())
+
+	cfg.TemplatesDir = t.TempDir()
+	cfg.SecretsFile = filepath.Join(t.TempDir(), "missing.json")
+	assert.EqualValues(t, "unreadable API_SECRETS_FILE", cfg.Validate().Error())
+
+	cfg.SecretsFile = ""
+	cfg.GiteaCaBundle = filepath.Join(t.TempDir(), "missing.pem")
+	assert.EqualValues(t, "unreadable API_GITEA_CA_BUNDLE", cfg.Validate().Error())
+
+	cfg.GiteaCaBundle = ""
+	cfg.DefaultProtectionPolicy = "strict"
+	assert.EqualValues(t, "API_DEFAULT_PROTECTION_POLICY requires API_PROTECTION_POLICIES_FILE", cfg.Validate().Error())
+
+	cfg.ProtectionPoliciesFile = filepath.Join(t.TempDir(), "missing.json")
+	assert.EqualValues(t, "unreadable API_PROTECTION_POLICIES_FILE", cfg.Validate().Error())
+
+	cfg.ProtectionPoliciesFile = ""
+	cfg.DefaultProtectionPolicy = ""
+	cfg.LabelSetsFile = filepath.Join(t.TempDir(), "missing.json")
+	assert.EqualValues(t, "unreadable API_LABEL_SETS_FILE", cfg.Validate().Error())
+
+	cfg.LabelSetsFile = ""
+	cfg.GithubBaseUrl = "ghe.corp"
+	cfg.LogLevel = "verbose"
+	cfg.RateLimitIpRps = -1
+	cfg.ServiceUrl = "repos.corp"
+	cfg.TrustedProxies = []string{"10.0.0.0/8", "proxy.corp"}
+	assert.EqualValues(t, "invalid API_GITHUB_BASE_URL; invalid LOG_LEVEL; rate limit must be positive; invalid API_TRUSTED_PROXIES; invalid API_SERVICE_URL", cfg.Validate().Error())
+}
+
+func TestCaBundle(t *testing.T) {
+	cfg := Default()
+	cfg.GithubCaBundle = "github.pem"
+	cfg.GitlabCaBundle = "gitlab.pem"
+
+	assert.EqualValues(t, "github.pem", cfg.CaBundle(providers.Github))
+	assert.EqualValues(t, "gitlab.pem", cfg.CaBundle(providers.Gitlab))
+	assert.EqualValues(t, "", cfg.CaBundle(providers.Gitea))
+}
+
+func TestValidateProviders(t *testing.T) {
+	cfg := Default()
+	cfg.AuthJwtSecret = "jwt-secret"
+	cfg.DefaultProvider = "gitlab"
+	cfg.OrgProviders = map[string]string{"tools": "gitea", "platform": "gitlab"}
+	assert.EqualValues(t, "default provider gitlab is not configured; provider gitlab of org platform is not configured; "+
+		"provider gitea of org tools is not configured", cfg.Validate().Error())
+
+	cfg.GitlabBaseUrl = "https://gitlab.example.com/"
+	cfg.GiteaBaseUrl = "https://gitea.example.com"
+	assert.EqualValues(t, []string{"github", "gitlab", "gitea"}, cfg.EnabledProviders())
+	assert.EqualValues(t, "https://gitlab.example.com", cfg.GetGitlabBaseUrl())
+	assert.Nil(t, cfg.Validate())
+}
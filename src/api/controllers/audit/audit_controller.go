@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/services"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultLimit = 100
+
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(-duration), nil
+}
+
+type Controller struct {
+	service services.AuditServiceInterface
+}
+
+func NewController(service services.AuditServiceInterface) *Controller {
+	return &Controller{service: service}
+}
+
+func (c *Controller) GetAudit(ctx *gin.Context) {
+	since, err := parseSince(ctx.Query("since"), time.Now())
+	if err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid since, expected RFC3339 time or duration")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	limit := defaultLimit
+	if value := ctx.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			apiErr := errors.NewBadRequestApiError("invalid limit")
+			ctx.JSON(apiErr.Status(), apiErr)
+			return
+		}
+	}
+
+	entries, apiErr := c.service.Query(repositories.AuditFilter{
+		Owner: ctx.Query("owner"),
+		Since: since,
+		Limit: limit,
+	})
+	if apiErr != nil {
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
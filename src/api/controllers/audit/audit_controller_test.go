@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/services"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2022, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	since, err := parseSince("2022-01-01T00:00:00Z", now)
+	assert.Nil(t, err)
+	assert.EqualValues(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), since)
+
+	since, err = parseSince("24h", now)
+	assert.Nil(t, err)
+	assert.EqualValues(t, time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC), since)
+
+	_, err = parseSince("yesterday", now)
+	assert.NotNil(t, err)
+}
+
+func TestGetAuditInvalidSince(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/audit?since=yesterday", nil)
+
+	NewController(services.NewAuditService(nil)).GetAudit(ctx)
+
+	apiErr, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "invalid since, expected RFC3339 time or duration", apiErr.Message())
+}
+
+func TestGetAuditNoError(t *testing.T) {
+	sink := audit.NewMemorySink()
+	_ = sink.Record(repositories.AuditEntry{Timestamp: time.Now(), Caller: "team-a", Owner: "my-org", Status: http.StatusCreated})
+	_ = sink.Record(repositories.AuditEntry{Timestamp: time.Now(), Caller: "team-b", Owner: "other-org", Status: http.StatusCreated})
+
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/audit?owner=my-org&since=1h", nil)
+
+	NewController(services.NewAuditService(sink)).GetAudit(ctx)
+
+	var entries []repositories.AuditEntry
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &entries))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, 1, len(entries))
+	assert.EqualValues(t, "team-a", entries[0].Caller)
+}
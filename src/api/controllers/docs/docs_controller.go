@@ -0,0 +1,16 @@
+package docs
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/openapi"
+	"net/http"
+)
+
+// Spec serves the OpenAPI document the requests are validated against.
+func Spec(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", openapi.Spec())
+}
+
+func Page(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage())
+}
@@ -0,0 +1,37 @@
+package docs
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSpec(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+
+	Spec(ctx)
+
+	var document map[string]interface{}
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.True(t, strings.HasPrefix(response.Header().Get("Content-Type"), "application/json"))
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &document))
+	assert.EqualValues(t, "3.0.3", document["openapi"])
+}
+
+func TestPage(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/docs", nil)
+
+	Page(ctx)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.True(t, strings.HasPrefix(response.Header().Get("Content-Type"), "text/html"))
+	assert.Contains(t, response.Body.String(), `fetch("openapi.json")`)
+}
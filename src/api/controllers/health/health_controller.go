@@ -0,0 +1,41 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/health"
+	"golang-microservices/src/api/services"
+	"golang-microservices/src/api/utils/logger"
+	"net/http"
+)
+
+// respond serves only the check names and statuses, the probes are public
+// and the failing errors go to the log.
+func respond(ctx *gin.Context, report health.Report) {
+	if !report.Ok() {
+		log := logger.FromContext(ctx.Request.Context())
+		for _, check := range report.Checks {
+			if check.Status != health.StatusOk {
+				log.Warn("health check failing", logger.Any("check", check.Name), logger.Any("error", check.Error))
+			}
+		}
+		ctx.JSON(http.StatusServiceUnavailable, report.Public())
+		return
+	}
+	ctx.JSON(http.StatusOK, report.Public())
+}
+
+type Controller struct {
+	service services.HealthServiceInterface
+}
+
+func NewController(service services.HealthServiceInterface) *Controller {
+	return &Controller{service: service}
+}
+
+func (c *Controller) Healthz(ctx *gin.Context) {
+	respond(ctx, c.service.Liveness(ctx.Request.Context()))
+}
+
+func (c *Controller) Readyz(ctx *gin.Context) {
+	respond(ctx, c.service.Readiness(ctx.Request.Context()))
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/health"
+	"golang-microservices/src/api/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthz(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+
+	NewController(services.NewHealthService()).Healthz(ctx)
+
+	var report health.PublicReport
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &report))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, health.StatusOk, report.Status)
+}
+
+func TestReadyzFailing(t *testing.T) {
+	service := services.NewHealthService(health.NewCheck("github_token", func(ctx context.Context) error {
+		return errors.New("github access token is not configured")
+	}))
+
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	NewController(service).Readyz(ctx)
+
+	var report health.PublicReport
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &report))
+	assert.EqualValues(t, http.StatusServiceUnavailable, response.Code)
+	assert.EqualValues(t, health.StatusFailing, report.Status)
+	assert.EqualValues(t, health.CheckStatus{Name: "github_token", Status: health.StatusFailing}, report.Checks[1])
+	assert.NotContains(t, response.Body.String(), "access token")
+}
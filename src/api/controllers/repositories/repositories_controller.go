@@ -1,14 +1,33 @@
 package repositories
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/domain/headers"
 	"golang-microservices/src/api/domain/repositories"
 	"golang-microservices/src/api/services"
 	"golang-microservices/src/api/utils/errors"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
-func CreateRepo(ctx *gin.Context) {
+const headerLink = "Link"
+
+// maxManifestSize bounds the reconcile body, manifests are read whole.
+const maxManifestSize = 1 << 20
+
+type Controller struct {
+	service services.ReposServiceInterface
+}
+
+func NewController(service services.ReposServiceInterface) *Controller {
+	return &Controller{service: service}
+}
+
+func (c *Controller) CreateRepo(ctx *gin.Context) {
 	var request repositories.CreateRepoRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		apiErr := errors.NewBadRequestApiError("invalid json body")
@@ -16,7 +35,7 @@ func CreateRepo(ctx *gin.Context) {
 		return
 	}
 
-	res, err := services.RepositoryService.CreateRepo(request)
+	res, err := c.service.CreateRepo(ctx.Request.Context(), request)
 	if err != nil {
 		ctx.JSON(err.Status(), err)
 		return
@@ -25,7 +44,7 @@ func CreateRepo(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, res)
 }
 
-func CreateRepos(ctx *gin.Context) {
+func (c *Controller) CreateRepos(ctx *gin.Context) {
 	var request []repositories.CreateRepoRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		apiErr := errors.NewBadRequestApiError("invalid json body")
@@ -33,11 +52,259 @@ func CreateRepos(ctx *gin.Context) {
 		return
 	}
 
-	res, err := services.RepositoryService.CreateRepos(request)
+	res, err := c.service.CreateRepos(ctx.Request.Context(), request)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (c *Controller) GetRepo(ctx *gin.Context) {
+	res, err := c.service.GetRepo(ctx.Request.Context(), ctx.Query("provider"), ctx.Param("owner"), ctx.Param("name"))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *Controller) UpdateRepo(ctx *gin.Context) {
+	var request repositories.UpdateRepoRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid json body")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	res, err := c.service.UpdateRepo(ctx.Request.Context(), ctx.Query("provider"), ctx.Param("owner"), ctx.Param("name"), request)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *Controller) TransferRepo(ctx *gin.Context) {
+	var request repositories.TransferRepoRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid json body")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	res, err := c.service.TransferRepo(ctx.Request.Context(), ctx.Query("provider"), ctx.Param("owner"), ctx.Param("name"), request)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, res)
+}
+
+func (c *Controller) ApplyProtection(ctx *gin.Context) {
+	var request repositories.ProtectionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid json body")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	res, err := c.service.ApplyProtection(ctx.Request.Context(), ctx.Query("provider"), ctx.Param("owner"), ctx.Param("name"), request)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *Controller) GetProtection(ctx *gin.Context) {
+	request := repositories.ProtectionRequest{Policy: ctx.Query("policy"), Branch: ctx.Query("branch")}
+	res, err := c.service.GetProtectionDrift(ctx.Request.Context(), ctx.Query("provider"), ctx.Param("owner"), ctx.Param("name"), request)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *Controller) SyncRepo(ctx *gin.Context) {
+	var request repositories.SyncRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid json body")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	res, err := c.service.SyncRepo(ctx.Request.Context(), ctx.Query("provider"), ctx.Param("owner"), ctx.Param("name"), request)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *Controller) GrantAccess(ctx *gin.Context) {
+	var request repositories.AccessRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid json body")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	res, err := c.service.GrantAccess(ctx.Request.Context(), ctx.Query("provider"), ctx.Param("owner"), ctx.Param("name"), request)
 	if err != nil {
 		ctx.JSON(err.Status(), err)
 		return
 	}
 
+	ctx.JSON(http.StatusOK, res)
+}
+
+func queryBool(ctx *gin.Context, key string) (bool, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func (c *Controller) DeleteRepo(ctx *gin.Context) {
+	archive, err := queryBool(ctx, "archive")
+	if err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid archive, expected true or false")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	res, apiErr := c.service.DeleteRepo(ctx.Request.Context(), repositories.DeleteRepoRequest{
+		Provider: ctx.Query("provider"),
+		Owner:    ctx.Param("owner"),
+		Name:     ctx.Param("name"),
+		Archive:  archive,
+		Confirm:  ctx.GetHeader(headers.ConfirmDelete),
+	})
+	if apiErr != nil {
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *Controller) DeleteRepos(ctx *gin.Context) {
+	archive, err := queryBool(ctx, "archive")
+	if err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid archive, expected true or false")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	res, apiErr := c.service.DeleteRepos(ctx.Request.Context(), repositories.DeleteReposRequest{
+		Provider: ctx.Query("provider"),
+		Owner:    ctx.Query("owner"),
+		Prefix:   ctx.Query("prefix"),
+		Archive:  archive,
+		Confirm:  ctx.GetHeader(headers.ConfirmDelete),
+	})
+	if apiErr != nil {
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx.JSON(res.StatusCode, res)
+}
+
+// Reconcile takes the manifest as yaml or json of at most maxManifestSize
+// bytes, the plan query parameter only reports the plan.
+func (c *Controller) Reconcile(ctx *gin.Context) {
+	planOnly, err := queryBool(ctx, "plan")
+	if err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid plan, expected true or false")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxManifestSize))
+	if err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid manifest body")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+	manifest, apiErr := repositories.ParseManifest(body)
+	if apiErr != nil {
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+	manifest.Confirm = ctx.GetHeader(headers.ConfirmDelete)
+
+	res, apiErr := c.service.Reconcile(ctx.Request.Context(), *manifest, planOnly)
+	if apiErr != nil {
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
 	ctx.JSON(res.StatusCode, res)
 }
+
+func queryInt(ctx *gin.Context, key string) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// linkHeader mirrors the provider pagination as an RFC 8288 Link header
+// pointing back at this service.
+func linkHeader(requestUrl *url.URL, next int, last int) string {
+	links := make([]string, 0, 2)
+	for _, link := range []struct {
+		rel  string
+		page int
+	}{{"next", next}, {"last", last}} {
+		if link.page <= 0 {
+			continue
+		}
+		query := requestUrl.Query()
+		query.Set("page", strconv.Itoa(link.page))
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, requestUrl.Path, query.Encode(), link.rel))
+	}
+	return strings.Join(links, ", ")
+}
+
+func (c *Controller) ListRepos(ctx *gin.Context) {
+	page, err := queryInt(ctx, "page")
+	if err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid page")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+	perPage, err := queryInt(ctx, "per_page")
+	if err != nil {
+		apiErr := errors.NewBadRequestApiError("invalid per_page, expected at most 100")
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	res, apiErr := c.service.ListRepos(ctx.Request.Context(), ctx.Query("provider"), repositories.ListReposOptions{
+		Owner:      ctx.Query("owner"),
+		Visibility: ctx.Query("visibility"),
+		Page:       page,
+		PerPage:    perPage,
+	})
+	if apiErr != nil {
+		ctx.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	if link := linkHeader(ctx.Request.URL, res.NextPage, res.LastPage); link != "" {
+		ctx.Header(headerLink, link)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
@@ -1,15 +1,16 @@
 package repositories
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"golang-microservices/src/api/domain/repositories"
-	"golang-microservices/src/api/services"
 	"golang-microservices/src/api/utils/errors"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -20,7 +21,7 @@ func TestCreateRepoInvalidJsonBody(t *testing.T) {
 
 	ctx.Request = httptest.NewRequest(http.MethodPost, "/repositories", strings.NewReader(""))
 
-	CreateRepo(ctx)
+	NewController(&reposServiceMock{}).CreateRepo(ctx)
 
 	resBody := response.Body.Bytes()
 	resError, _ := errors.NewApiErrorFromBytes(resBody)
@@ -31,17 +32,70 @@ func TestCreateRepoInvalidJsonBody(t *testing.T) {
 	assert.EqualValues(t, "", resError.Error())
 }
 
-type reposServiceMock struct{}
+type reposServiceMock struct {
+	createRepoFunc   func(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
+	listReposFunc    func(provider string, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError)
+	updateRepoFunc   func(owner string, name string, input repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError)
+	transferRepoFunc func(owner string, name string, input repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError)
+	deleteRepoFunc   func(input repositories.DeleteRepoRequest) (*repositories.DeleteRepoResponse, errors.ApiError)
+	deleteReposFunc  func(input repositories.DeleteReposRequest) (*repositories.DeleteReposResponse, errors.ApiError)
+	grantAccessFunc  func(owner string, name string, input repositories.AccessRequest) (*repositories.AccessResponse, errors.ApiError)
+	syncRepoFunc     func(owner string, name string, input repositories.SyncRequest) (*repositories.SyncResponse, errors.ApiError)
+	protectionFunc   func(owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError)
+	reconcileFunc    func(manifest repositories.Manifest, planOnly bool) (*repositories.ReconcileResponse, errors.ApiError)
+}
 
-func (r *reposServiceMock) CreateRepos(input []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError) {
+func (r *reposServiceMock) CreateRepos(ctx context.Context, input []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError) {
 	panic("not implemented")
 }
 
-func (r *reposServiceMock) CreateRepo(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
-	return createRepoFunc(input)
+func (r *reposServiceMock) GetRepo(ctx context.Context, provider string, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	panic("not implemented")
+}
+
+func (r *reposServiceMock) ListRepos(ctx context.Context, provider string, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	return r.listReposFunc(provider, options)
+}
+
+func (r *reposServiceMock) UpdateRepo(ctx context.Context, provider string, owner string, name string, input repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	return r.updateRepoFunc(owner, name, input)
+}
+
+func (r *reposServiceMock) TransferRepo(ctx context.Context, provider string, owner string, name string, input repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	return r.transferRepoFunc(owner, name, input)
+}
+
+func (r *reposServiceMock) ApplyProtection(ctx context.Context, provider string, owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError) {
+	return r.protectionFunc(owner, name, input)
+}
+
+func (r *reposServiceMock) GetProtectionDrift(ctx context.Context, provider string, owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError) {
+	return r.protectionFunc(owner, name, input)
+}
+
+func (r *reposServiceMock) SyncRepo(ctx context.Context, provider string, owner string, name string, input repositories.SyncRequest) (*repositories.SyncResponse, errors.ApiError) {
+	return r.syncRepoFunc(owner, name, input)
+}
+
+func (r *reposServiceMock) GrantAccess(ctx context.Context, provider string, owner string, name string, input repositories.AccessRequest) (*repositories.AccessResponse, errors.ApiError) {
+	return r.grantAccessFunc(owner, name, input)
+}
+
+func (r *reposServiceMock) DeleteRepo(ctx context.Context, input repositories.DeleteRepoRequest) (*repositories.DeleteRepoResponse, errors.ApiError) {
+	return r.deleteRepoFunc(input)
 }
 
-var createRepoFunc func(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
+func (r *reposServiceMock) DeleteRepos(ctx context.Context, input repositories.DeleteReposRequest) (*repositories.DeleteReposResponse, errors.ApiError) {
+	return r.deleteReposFunc(input)
+}
+
+func (r *reposServiceMock) Reconcile(ctx context.Context, manifest repositories.Manifest, planOnly bool) (*repositories.ReconcileResponse, errors.ApiError) {
+	return r.reconcileFunc(manifest, planOnly)
+}
+
+func (r *reposServiceMock) CreateRepo(ctx context.Context, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	return r.createRepoFunc(input)
+}
 
 func TestCreateRepoErrorFromGithub(t *testing.T) {
 	response := httptest.NewRecorder()
@@ -52,13 +106,13 @@ func TestCreateRepoErrorFromGithub(t *testing.T) {
 	)
 
 	var actualCreateRepoInput repositories.CreateRepoRequest
-	createRepoFunc = func(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	service := &reposServiceMock{}
+	service.createRepoFunc = func(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
 		actualCreateRepoInput = input
 		return nil, errors.NewBadRequestApiError("invalid repository name")
 	}
-	services.RepositoryService = &reposServiceMock{}
 
-	CreateRepo(ctx)
+	NewController(service).CreateRepo(ctx)
 
 	assert.EqualValues(
 		t,
@@ -88,13 +142,13 @@ func TestCreateRepoNoError(t *testing.T) {
 
 	var actualCreateRepoInput repositories.CreateRepoRequest
 	expectedResponse := &repositories.CreateRepoResponse{Id: 123, Name: "test_repo", Owner: "SomeOwnerOfGithubToken"}
-	createRepoFunc = func(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	service := &reposServiceMock{}
+	service.createRepoFunc = func(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
 		actualCreateRepoInput = input
 		return expectedResponse, nil
 	}
-	services.RepositoryService = &reposServiceMock{}
 
-	CreateRepo(ctx)
+	NewController(service).CreateRepo(ctx)
 
 	assert.EqualValues(
 		t,
@@ -110,3 +164,309 @@ func TestCreateRepoNoError(t *testing.T) {
 	assert.EqualValues(t, http.StatusCreated, response.Code)
 	assert.EqualValues(t, *expectedResponse, res)
 }
+
+func TestLinkHeader(t *testing.T) {
+	requestUrl, _ := url.Parse("/repositories?owner=my-org&page=2")
+
+	assert.EqualValues(t,
+		`</repositories?owner=my-org&page=3>; rel="next", </repositories?owner=my-org&page=5>; rel="last"`,
+		linkHeader(requestUrl, 3, 5),
+	)
+	assert.EqualValues(t, "", linkHeader(requestUrl, 0, 0))
+}
+
+func TestListReposInvalidPage(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/repositories?page=two", nil)
+
+	NewController(&reposServiceMock{}).ListRepos(ctx)
+
+	apiErr, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "invalid page", apiErr.Message())
+}
+
+func TestListReposNoError(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/repositories?owner=my-org&visibility=private&page=2&provider=gitlab", nil)
+
+	var actualProvider string
+	var actualOptions repositories.ListReposOptions
+	service := &reposServiceMock{}
+	service.listReposFunc = func(provider string, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+		actualProvider, actualOptions = provider, options
+		return &repositories.RepositoryPage{
+			Repositories: []repositories.Repository{{Id: 1, Owner: "my-org", Name: "one", FullName: "my-org/one"}},
+			Page:         2,
+			NextPage:     3,
+		}, nil
+	}
+
+	NewController(service).ListRepos(ctx)
+
+	var res repositories.RepositoryPage
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &res))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, "gitlab", actualProvider)
+	assert.EqualValues(t, repositories.ListReposOptions{Owner: "my-org", Visibility: "private", Page: 2}, actualOptions)
+	assert.EqualValues(t, 1, len(res.Repositories))
+	assert.EqualValues(t,
+		`</repositories?owner=my-org&page=3&provider=gitlab&visibility=private>; rel="next"`,
+		response.Header().Get("Link"),
+	)
+}
+
+func TestUpdateRepoInvalidJsonBody(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodPatch, "/repository/my-org/one", strings.NewReader("{"))
+
+	NewController(&reposServiceMock{}).UpdateRepo(ctx)
+
+	apiErr, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "invalid json body", apiErr.Message())
+}
+
+func TestUpdateRepoNoError(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodPatch, "/repository/my-org/one", strings.NewReader(`{"archived": true}`))
+	ctx.Params = gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "one"}}
+
+	var actualOwner, actualName string
+	var actualInput repositories.UpdateRepoRequest
+	service := &reposServiceMock{}
+	service.updateRepoFunc = func(owner string, name string, input repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+		actualOwner, actualName, actualInput = owner, name, input
+		return &repositories.Repository{Id: 1, Owner: owner, Name: name, Archived: true}, nil
+	}
+
+	NewController(service).UpdateRepo(ctx)
+
+	var res repositories.Repository
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &res))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, "my-org", actualOwner)
+	assert.EqualValues(t, "one", actualName)
+	assert.True(t, *actualInput.Archived)
+	assert.Nil(t, actualInput.Name)
+	assert.True(t, res.Archived)
+}
+
+func TestDeleteRepoInvalidArchive(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodDelete, "/repository/my-org/one?archive=maybe", nil)
+
+	NewController(&reposServiceMock{}).DeleteRepo(ctx)
+
+	apiErr, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "invalid archive, expected true or false", apiErr.Message())
+}
+
+func TestDeleteRepoNoError(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodDelete, "/repository/my-org/one?archive=true", nil)
+	ctx.Request.Header.Set("X-Confirm-Delete", "my-org/one")
+	ctx.Params = gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "one"}}
+
+	var actualInput repositories.DeleteRepoRequest
+	service := &reposServiceMock{}
+	service.deleteRepoFunc = func(input repositories.DeleteRepoRequest) (*repositories.DeleteRepoResponse, errors.ApiError) {
+		actualInput = input
+		return &repositories.DeleteRepoResponse{Owner: "my-org", Name: "one", FullName: "my-org/one", Action: repositories.ActionArchived}, nil
+	}
+
+	NewController(service).DeleteRepo(ctx)
+
+	var res repositories.DeleteRepoResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &res))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, repositories.DeleteRepoRequest{Owner: "my-org", Name: "one", Archive: true, Confirm: "my-org/one"}, actualInput)
+	assert.EqualValues(t, repositories.ActionArchived, res.Action)
+}
+
+func TestDeleteReposUsesBatchStatus(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodDelete, "/repositories?owner=my-org&prefix=ci-", nil)
+
+	var actualInput repositories.DeleteReposRequest
+	service := &reposServiceMock{}
+	service.deleteReposFunc = func(input repositories.DeleteReposRequest) (*repositories.DeleteReposResponse, errors.ApiError) {
+		actualInput = input
+		return &repositories.DeleteReposResponse{StatusCode: http.StatusPartialContent}, nil
+	}
+
+	NewController(service).DeleteRepos(ctx)
+
+	assert.EqualValues(t, http.StatusPartialContent, response.Code)
+	assert.EqualValues(t, repositories.DeleteReposRequest{Owner: "my-org", Prefix: "ci-"}, actualInput)
+}
+
+func TestTransferRepoAccepted(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/repository/my-org/one/transfer",
+		strings.NewReader(`{"new_owner": "new-org", "team_ids": [12]}`))
+	ctx.Params = gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "one"}}
+
+	var actualInput repositories.TransferRepoRequest
+	service := &reposServiceMock{}
+	service.transferRepoFunc = func(owner string, name string, input repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+		actualInput = input
+		return &repositories.Repository{Id: 1, Owner: input.NewOwner, Name: name, FullName: input.NewOwner + "/" + name}, nil
+	}
+
+	NewController(service).TransferRepo(ctx)
+
+	var res repositories.Repository
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &res))
+	assert.EqualValues(t, http.StatusAccepted, response.Code)
+	assert.EqualValues(t, repositories.TransferRepoRequest{NewOwner: "new-org", TeamIds: []int64{12}}, actualInput)
+	assert.EqualValues(t, "new-org/one", res.FullName)
+}
+
+func TestGrantAccessNoError(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/repository/my-org/one/access",
+		strings.NewReader(`{"teams": [{"slug": "core", "permission": "push"}], "collaborators": [{"username": "octocat"}]}`))
+	ctx.Params = gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "one"}}
+
+	var actualInput repositories.AccessRequest
+	service := &reposServiceMock{}
+	service.grantAccessFunc = func(owner string, name string, input repositories.AccessRequest) (*repositories.AccessResponse, errors.ApiError) {
+		actualInput = input
+		return &repositories.AccessResponse{
+			Granted: []repositories.AccessGrant{{Kind: repositories.AccessKindTeam, Name: "core", Permission: "push"}},
+			Invited: []repositories.AccessGrant{{Kind: repositories.AccessKindCollaborator, Name: "octocat", Permission: "pull", InvitationId: 1}},
+		}, nil
+	}
+
+	NewController(service).GrantAccess(ctx)
+
+	var res repositories.AccessResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &res))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, "core", actualInput.Teams[0].Slug)
+	assert.EqualValues(t, "octocat", actualInput.Collaborators[0].Username)
+	assert.EqualValues(t, 1, res.Invited[0].InvitationId)
+}
+
+func TestGetProtectionUsesQuery(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/repository/my-org/one/protection?policy=strict&branch=release", nil)
+	ctx.Params = gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "one"}}
+
+	var actualInput repositories.ProtectionRequest
+	service := &reposServiceMock{}
+	service.protectionFunc = func(owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError) {
+		actualInput = input
+		return &repositories.ProtectionReport{Owner: owner, Name: name, Branch: input.Branch, Policy: input.Policy,
+			Drift: []repositories.ProtectionDrift{{Field: "required_reviews", Expected: 2, Actual: 0}}}, nil
+	}
+
+	NewController(service).GetProtection(ctx)
+
+	var res repositories.ProtectionReport
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &res))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, repositories.ProtectionRequest{Policy: "strict", Branch: "release"}, actualInput)
+	assert.False(t, res.InSync)
+	assert.EqualValues(t, "required_reviews", res.Drift[0].Field)
+}
+
+func TestApplyProtectionInvalidJsonBody(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/repository/my-org/one/protection", strings.NewReader("{"))
+
+	NewController(&reposServiceMock{}).ApplyProtection(ctx)
+
+	apiErr, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "invalid json body", apiErr.Message())
+}
+
+func TestSyncRepoNoError(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/repository/my-org/one/sync",
+		strings.NewReader(`{"labels": {"set": "platform", "remove_defaults": true}, "topics": []}`))
+	ctx.Params = gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "one"}}
+
+	var actualInput repositories.SyncRequest
+	service := &reposServiceMock{}
+	service.syncRepoFunc = func(owner string, name string, input repositories.SyncRequest) (*repositories.SyncResponse, errors.ApiError) {
+		actualInput = input
+		return &repositories.SyncResponse{Labels: &repositories.LabelSyncResult{Created: []string{"incident"}}}, nil
+	}
+
+	NewController(service).SyncRepo(ctx)
+
+	var res repositories.SyncResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &res))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, &repositories.LabelSync{Set: "platform", RemoveDefaults: true}, actualInput.Labels)
+	assert.EqualValues(t, []string{}, actualInput.Topics)
+	assert.EqualValues(t, []string{"incident"}, res.Labels.Created)
+}
+
+func TestReconcileInvalidManifest(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/reconcile", strings.NewReader(`repositories: [{name: payments}]`))
+
+	NewController(&reposServiceMock{}).Reconcile(ctx)
+
+	resError, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "repository payments has no owner", resError.Message())
+}
+
+func TestReconcileManifestTooLarge(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	body := "owner: my-org\nrepositories:\n  - name: payments\n#" + strings.Repeat("x", maxManifestSize)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/reconcile", strings.NewReader(body))
+
+	NewController(&reposServiceMock{}).Reconcile(ctx)
+
+	resError, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "invalid manifest body", resError.Message())
+}
+
+func TestReconcilePlanOnly(t *testing.T) {
+	response := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/reconcile?plan=true",
+		strings.NewReader("owner: my-org\nrepositories:\n  - name: payments\n"))
+	ctx.Request.Header.Set("X-Confirm-Delete", "my-org/*")
+
+	var actualManifest repositories.Manifest
+	service := &reposServiceMock{}
+	service.reconcileFunc = func(manifest repositories.Manifest, planOnly bool) (*repositories.ReconcileResponse, errors.ApiError) {
+		actualManifest = manifest
+		assert.True(t, planOnly)
+		return &repositories.ReconcileResponse{StatusCode: http.StatusOK, PlanOnly: true, Actions: []repositories.ReconcileAction{
+			{Action: repositories.ReconcileCreate, Owner: "my-org", Name: "payments"},
+		}}, nil
+	}
+
+	NewController(service).Reconcile(ctx)
+
+	var res repositories.ReconcileResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &res))
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, "my-org", actualManifest.Repositories[0].Owner)
+	assert.EqualValues(t, "my-org/*", actualManifest.Confirm)
+	assert.EqualValues(t, repositories.ReconcileCreate, res.Actions[0].Action)
+}
@@ -2,13 +2,28 @@ package restclient
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/tracing"
+	"golang-microservices/src/api/utils/logger"
+	"golang-microservices/src/api/utils/requestid"
+	"io"
+	"io/ioutil"
 	"net/http"
 )
 
-var enabledMocks = false
-var mocks = make(map[string]*Mock)
+const headerContentType = "Content-Type"
+const contentTypeJson = "application/json"
+
+type Client struct {
+	httpClient   *http.Client
+	enabledMocks bool
+	mocks        map[string]*Mock
+}
 
 type Mock struct {
 	Url        string
@@ -17,40 +32,119 @@ type Mock struct {
 	Error      error
 }
 
-func StartMock() {
-	enabledMocks = true
+func NewClient(caBundle string) (*Client, error) {
+	if caBundle == "" {
+		return newClient(http.DefaultTransport), nil
+	}
+
+	transport, err := newCaBundleTransport(caBundle)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(transport), nil
+}
+
+func newClient(base http.RoundTripper) *Client {
+	return &Client{
+		httpClient: &http.Client{Transport: tracing.NewTransport(metrics.NewTransport(base))},
+		mocks:      make(map[string]*Mock),
+	}
+}
+
+func newCaBundleTransport(path string) (http.RoundTripper, error) {
+	pemBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, errors.New("no certificates found in ca bundle " + path)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return transport, nil
 }
 
-func StopMock() {
-	enabledMocks = false
-	mocks = make(map[string]*Mock)
+func (c *Client) StartMock() {
+	c.enabledMocks = true
 }
 
-func AddMock(mock *Mock) {
-	mocks[mock.Url] = mock
+func (c *Client) StopMock() {
+	c.enabledMocks = false
+	c.mocks = make(map[string]*Mock)
 }
 
-func Post(url string, body interface{}, headers http.Header) (*http.Response, error) {
-	if enabledMocks {
-		mock := mocks[url]
+func (c *Client) AddMock(mock *Mock) {
+	c.mocks[getMockKey(mock.HttpMethod, mock.Url)] = mock
+}
+
+func (c *Client) Post(ctx context.Context, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return c.do(ctx, http.MethodPost, url, body, headers)
+}
+
+func (c *Client) Get(ctx context.Context, url string, headers http.Header) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, url, nil, headers)
+}
+
+func (c *Client) Put(ctx context.Context, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return c.do(ctx, http.MethodPut, url, body, headers)
+}
+
+func (c *Client) Patch(ctx context.Context, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return c.do(ctx, http.MethodPatch, url, body, headers)
+}
+
+func (c *Client) Delete(ctx context.Context, url string, headers http.Header) (*http.Response, error) {
+	return c.do(ctx, http.MethodDelete, url, nil, headers)
+}
+
+func getMockKey(method string, url string) string {
+	return method + " " + url
+}
+
+func (c *Client) do(ctx context.Context, method string, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	if c.enabledMocks {
+		mock := c.mocks[getMockKey(method, url)]
 		if mock == nil {
 			return nil, errors.New("no mock found for given url")
 		}
 		return mock.Response, mock.Error
 	}
 
-	jsonBytes, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
+	var bodyReader io.Reader
+	if body != nil {
+		jsonBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(jsonBytes)
 	}
 
-	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(jsonBytes))
+	request, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, err
 	}
-	request.Header = headers
+	request.Header = headers.Clone()
+	if request.Header == nil {
+		request.Header = http.Header{}
+	}
+	if body != nil && request.Header.Get(headerContentType) == "" {
+		request.Header.Set(headerContentType, contentTypeJson)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		request.Header.Set(requestid.Header, id)
+	}
 
-	client := http.Client{}
-	return client.Do(request)
+	logger.FromContext(ctx).Debug("outbound request",
+		logger.Any("method", request.Method),
+		logger.Any("url", url),
+		logger.Any("headers", request.Header),
+	)
 
+	return c.httpClient.Do(request)
 }
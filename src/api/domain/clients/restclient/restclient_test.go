@@ -0,0 +1,88 @@
+package restclient
+
+import (
+	"context"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/utils/requestid"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewClientInvalidCaBundlePath(t *testing.T) {
+	client, err := NewClient("-;dlfaksd;fasdf")
+	assert.Nil(t, client)
+	assert.NotNil(t, err)
+}
+
+func TestNewClientCaBundleWithoutCertificates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("not a certificate"), 0600))
+
+	_, err := NewClient(path)
+	assert.NotNil(t, err)
+}
+
+func TestPostWithCaBundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.Nil(t, ioutil.WriteFile(path, certPem, 0600))
+
+	client, err := NewClient(path)
+	assert.Nil(t, err)
+
+	response, err := client.Post(context.Background(), server.URL, map[string]string{}, http.Header{})
+	assert.Nil(t, err)
+	assert.NotNil(t, response)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+}
+
+func TestPostPropagatesRequestId(t *testing.T) {
+	var actualRequestId string
+	var actualContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actualRequestId = r.Header.Get("X-Request-Id")
+		actualContentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	ctx := requestid.WithRequestId(context.Background(), "abc123")
+	client, _ := NewClient("")
+	response, err := client.Post(ctx, server.URL, map[string]string{}, http.Header{})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+	assert.EqualValues(t, "abc123", actualRequestId)
+	assert.EqualValues(t, "application/json", actualContentType)
+}
+
+func TestMocksAreScopedToClient(t *testing.T) {
+	mocked, _ := NewClient("")
+	mocked.StartMock()
+	mocked.AddMock(&Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+
+	response, err := mocked.Get(context.Background(), "https://api.github.com/user", http.Header{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+
+	_, err = mocked.Get(context.Background(), "https://api.github.com/user/repos", http.Header{})
+	assert.EqualValues(t, "no mock found for given url", err.Error())
+
+	mocked.StopMock()
+	other, _ := NewClient("")
+	assert.False(t, other.enabledMocks)
+	assert.Empty(t, mocked.mocks)
+}
@@ -0,0 +1,62 @@
+package gitea
+
+type CreateRepoRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+}
+
+type EditRepoRequest struct {
+	Name          *string `json:"name,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Website       *string `json:"website,omitempty"`
+	Private       *bool   `json:"private,omitempty"`
+	Archived      *bool   `json:"archived,omitempty"`
+	DefaultBranch *string `json:"default_branch,omitempty"`
+}
+
+type TransferRepoRequest struct {
+	NewOwner string  `json:"new_owner"`
+	TeamIds  []int64 `json:"team_ids,omitempty"`
+}
+
+type HookConfig struct {
+	Url         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Secret      string `json:"secret,omitempty"`
+}
+
+type CreateHookRequest struct {
+	Type   string     `json:"type"`
+	Active bool       `json:"active"`
+	Events []string   `json:"events"`
+	Config HookConfig `json:"config"`
+}
+
+type Hook struct {
+	Id int64 `json:"id"`
+}
+
+type Repository struct {
+	Id            int64    `json:"id"`
+	Name          string   `json:"name"`
+	FullName      string   `json:"full_name"`
+	Description   string   `json:"description"`
+	Website       string   `json:"website"`
+	Private       bool     `json:"private"`
+	Archived      bool     `json:"archived"`
+	DefaultBranch string   `json:"default_branch"`
+	HtmlUrl       string   `json:"html_url"`
+	Topics        []string `json:"topics"`
+	Owner         User     `json:"owner"`
+}
+
+type User struct {
+	Id    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+type ErrorResponse struct {
+	Message string `json:"message"`
+	Url     string `json:"url"`
+}
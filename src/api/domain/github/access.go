@@ -0,0 +1,16 @@
+package github
+
+type PermissionRequest struct {
+	Permission string `json:"permission"`
+}
+
+type Team struct {
+	Id         int64  `json:"id"`
+	Slug       string `json:"slug"`
+	Permission string `json:"permission"`
+}
+
+type Invitation struct {
+	Id          int64  `json:"id"`
+	Permissions string `json:"permissions"`
+}
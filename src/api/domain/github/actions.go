@@ -0,0 +1,16 @@
+package github
+
+type ActionsPublicKey struct {
+	KeyId string `json:"key_id"`
+	Key   string `json:"key"`
+}
+
+type PutActionsSecretRequest struct {
+	EncryptedValue string `json:"encrypted_value"`
+	KeyId          string `json:"key_id"`
+}
+
+type ActionsVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
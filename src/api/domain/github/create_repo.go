@@ -8,14 +8,16 @@ type CreateRepoRequest struct {
 	HasIssues   bool   `json:"has_issues"`
 	HasProjects bool   `json:"has_projects"`
 	HasWiki     bool   `json:"has_wiki"`
+	AutoInit    bool   `json:"auto_init,omitempty"`
 }
 
 type CreateRepoResponse struct {
-	Id          int64           `json:"id"`
-	Name        string          `json:"name"`
-	FullName    string          `json:"full_name"`
-	Owner       RepoOwner       `json:"owner"`
-	Permissions RepoPermissions `json:"permissions"`
+	Id            int64           `json:"id"`
+	Name          string          `json:"name"`
+	FullName      string          `json:"full_name"`
+	DefaultBranch string          `json:"default_branch"`
+	Owner         RepoOwner       `json:"owner"`
+	Permissions   RepoPermissions `json:"permissions"`
 }
 
 type RepoOwner struct {
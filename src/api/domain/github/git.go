@@ -0,0 +1,51 @@
+package github
+
+type GitObject struct {
+	Sha  string `json:"sha"`
+	Type string `json:"type,omitempty"`
+}
+
+type Ref struct {
+	Ref    string    `json:"ref"`
+	Object GitObject `json:"object"`
+}
+
+type Commit struct {
+	Sha  string    `json:"sha"`
+	Tree GitObject `json:"tree"`
+}
+
+type TreeEntry struct {
+	Path    string `json:"path"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+type CreateTreeRequest struct {
+	BaseTree string      `json:"base_tree,omitempty"`
+	Tree     []TreeEntry `json:"tree"`
+}
+
+type Tree struct {
+	Sha string `json:"sha"`
+}
+
+type CreateCommitRequest struct {
+	Message string   `json:"message"`
+	Tree    string   `json:"tree"`
+	Parents []string `json:"parents"`
+}
+
+type UpdateRefRequest struct {
+	Sha   string `json:"sha"`
+	Force bool   `json:"force"`
+}
+
+// PutContentsRequest creates a file through the contents api, Content is
+// base64 encoded.
+type PutContentsRequest struct {
+	Message string `json:"message"`
+	Content string `json:"content"`
+	Branch  string `json:"branch,omitempty"`
+}
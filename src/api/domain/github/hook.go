@@ -0,0 +1,21 @@
+package github
+
+type HookConfig struct {
+	Url         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Secret      string `json:"secret,omitempty"`
+	InsecureSsl string `json:"insecure_ssl"`
+}
+
+type CreateHookRequest struct {
+	Name   string     `json:"name"`
+	Active bool       `json:"active"`
+	Events []string   `json:"events"`
+	Config HookConfig `json:"config"`
+}
+
+type Hook struct {
+	Id     int64    `json:"id"`
+	Active bool     `json:"active"`
+	Events []string `json:"events"`
+}
@@ -0,0 +1,17 @@
+package github
+
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type UpdateLabelRequest struct {
+	NewName     string `json:"new_name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type Topics struct {
+	Names []string `json:"names"`
+}
@@ -0,0 +1,87 @@
+package github
+
+import "encoding/json"
+
+type RequiredStatusChecks struct {
+	Strict   bool     `json:"strict"`
+	Contexts []string `json:"contexts"`
+}
+
+type RequiredPullRequestReviews struct {
+	DismissStaleReviews          bool `json:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews"`
+	RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+}
+
+// UpdateBranchProtectionRequest needs every field, nil objects are sent as
+// null to disable the matching rule.
+type UpdateBranchProtectionRequest struct {
+	RequiredStatusChecks       *RequiredStatusChecks       `json:"required_status_checks"`
+	EnforceAdmins              bool                        `json:"enforce_admins"`
+	RequiredPullRequestReviews *RequiredPullRequestReviews `json:"required_pull_request_reviews"`
+	Restrictions               interface{}                 `json:"restrictions"`
+	RequiredLinearHistory      bool                        `json:"required_linear_history"`
+	AllowForcePushes           bool                        `json:"allow_force_pushes"`
+	AllowDeletions             bool                        `json:"allow_deletions"`
+}
+
+type EnabledSetting struct {
+	Enabled bool `json:"enabled"`
+}
+
+type BranchProtection struct {
+	RequiredStatusChecks       *RequiredStatusChecks       `json:"required_status_checks"`
+	EnforceAdmins              EnabledSetting              `json:"enforce_admins"`
+	RequiredPullRequestReviews *RequiredPullRequestReviews `json:"required_pull_request_reviews"`
+	RequiredLinearHistory      EnabledSetting              `json:"required_linear_history"`
+	AllowForcePushes           EnabledSetting              `json:"allow_force_pushes"`
+	AllowDeletions             EnabledSetting              `json:"allow_deletions"`
+}
+
+type RulesetRefName struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+type RulesetConditions struct {
+	RefName RulesetRefName `json:"ref_name"`
+}
+
+type RulesetBypassActor struct {
+	ActorId    int64  `json:"actor_id"`
+	ActorType  string `json:"actor_type"`
+	BypassMode string `json:"bypass_mode"`
+}
+
+// RulesetRule keeps the parameters raw, their shape depends on the type.
+type RulesetRule struct {
+	Type       string          `json:"type"`
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+}
+
+type Ruleset struct {
+	Id           int64                `json:"id,omitempty"`
+	Name         string               `json:"name"`
+	Target       string               `json:"target"`
+	Enforcement  string               `json:"enforcement"`
+	BypassActors []RulesetBypassActor `json:"bypass_actors"`
+	Conditions   *RulesetConditions   `json:"conditions,omitempty"`
+	Rules        []RulesetRule        `json:"rules,omitempty"`
+}
+
+type PullRequestRuleParameters struct {
+	RequiredApprovingReviewCount   int  `json:"required_approving_review_count"`
+	DismissStaleReviewsOnPush      bool `json:"dismiss_stale_reviews_on_push"`
+	RequireCodeOwnerReview         bool `json:"require_code_owner_review"`
+	RequireLastPushApproval        bool `json:"require_last_push_approval"`
+	RequiredReviewThreadResolution bool `json:"required_review_thread_resolution"`
+}
+
+type StatusCheck struct {
+	Context string `json:"context"`
+}
+
+type StatusChecksRuleParameters struct {
+	StrictRequiredStatusChecksPolicy bool          `json:"strict_required_status_checks_policy"`
+	RequiredStatusChecks             []StatusCheck `json:"required_status_checks"`
+}
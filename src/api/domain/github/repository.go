@@ -0,0 +1,30 @@
+package github
+
+type Repository struct {
+	Id            int64     `json:"id"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	Description   string    `json:"description"`
+	Homepage      string    `json:"homepage"`
+	Private       bool      `json:"private"`
+	Archived      bool      `json:"archived"`
+	DefaultBranch string    `json:"default_branch"`
+	HtmlUrl       string    `json:"html_url"`
+	Topics        []string  `json:"topics"`
+	Owner         RepoOwner `json:"owner"`
+}
+
+type TransferRepoRequest struct {
+	NewOwner string  `json:"new_owner"`
+	NewName  string  `json:"new_name,omitempty"`
+	TeamIds  []int64 `json:"team_ids,omitempty"`
+}
+
+type UpdateRepoRequest struct {
+	Name          *string `json:"name,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Homepage      *string `json:"homepage,omitempty"`
+	Private       *bool   `json:"private,omitempty"`
+	Archived      *bool   `json:"archived,omitempty"`
+	DefaultBranch *string `json:"default_branch,omitempty"`
+}
@@ -0,0 +1,7 @@
+package github
+
+type User struct {
+	Id    int64  `json:"id"`
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
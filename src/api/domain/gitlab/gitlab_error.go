@@ -0,0 +1,38 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrorResponse covers the shapes GitLab uses for errors: a plain message, a
+// map of field validation errors, or an OAuth style error.
+type ErrorResponse struct {
+	Message          json.RawMessage `json:"message"`
+	Error            string          `json:"error"`
+	ErrorDescription string          `json:"error_description"`
+}
+
+func (e ErrorResponse) Text() string {
+	var message string
+	if err := json.Unmarshal(e.Message, &message); err == nil && message != "" {
+		return message
+	}
+
+	var fields map[string][]string
+	if err := json.Unmarshal(e.Message, &fields); err == nil && len(fields) > 0 {
+		problems := make([]string, 0, len(fields))
+		for field, messages := range fields {
+			problems = append(problems, fmt.Sprintf("%s %s", field, strings.Join(messages, ", ")))
+		}
+		sort.Strings(problems)
+		return strings.Join(problems, "; ")
+	}
+
+	if e.ErrorDescription != "" {
+		return e.ErrorDescription
+	}
+	return e.Error
+}
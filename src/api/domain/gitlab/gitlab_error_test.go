@@ -0,0 +1,22 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestErrorResponseText(t *testing.T) {
+	cases := map[string]string{
+		`{"message": "404 Project Not Found"}`: "404 Project Not Found",
+		`{"message": {"path": ["has already been taken"], "name": ["has already been taken", "is too long"]}}`: "name has already been taken, is too long; path has already been taken",
+		`{"error": "invalid_token", "error_description": "Token was revoked."}`:                                "Token was revoked.",
+		`{"error": "insufficient_scope"}`: "insufficient_scope",
+	}
+
+	for body, expected := range cases {
+		var response ErrorResponse
+		assert.Nil(t, json.Unmarshal([]byte(body), &response))
+		assert.EqualValues(t, expected, response.Text())
+	}
+}
@@ -0,0 +1,46 @@
+package gitlab
+
+type CreateProjectRequest struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility"`
+	NamespaceId int64  `json:"namespace_id,omitempty"`
+}
+
+type UpdateProjectRequest struct {
+	Name          *string `json:"name,omitempty"`
+	Path          *string `json:"path,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Visibility    *string `json:"visibility,omitempty"`
+	DefaultBranch *string `json:"default_branch,omitempty"`
+}
+
+type TransferProjectRequest struct {
+	Namespace string `json:"namespace"`
+}
+
+type Project struct {
+	Id                int64     `json:"id"`
+	Name              string    `json:"name"`
+	Path              string    `json:"path"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	Description       string    `json:"description"`
+	Visibility        string    `json:"visibility"`
+	Archived          bool      `json:"archived"`
+	DefaultBranch     string    `json:"default_branch"`
+	WebUrl            string    `json:"web_url"`
+	Topics            []string  `json:"topics"`
+	Namespace         Namespace `json:"namespace"`
+}
+
+type Namespace struct {
+	Id       int64  `json:"id"`
+	Path     string `json:"path"`
+	FullPath string `json:"full_path"`
+}
+
+type User struct {
+	Id       int64  `json:"id"`
+	Username string `json:"username"`
+}
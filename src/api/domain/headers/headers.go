@@ -0,0 +1,13 @@
+// Package headers names the http headers the service and its clients agree
+// on, both sides import it so they cannot drift apart.
+package headers
+
+const ApiKey = "X-Api-Key"
+
+// ConfirmDelete carries the confirmation token of deletes, archives and
+// reconciles that archive.
+const ConfirmDelete = "X-Confirm-Delete"
+
+// RetryAfter is set on rate limited answers only, quota errors do not clear
+// soon enough to be retried.
+const RetryAfter = "Retry-After"
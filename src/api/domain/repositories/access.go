@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"golang-microservices/src/api/utils/errors"
+	"regexp"
+	"strings"
+)
+
+const PermissionPull = "pull"
+const PermissionTriage = "triage"
+const PermissionPush = "push"
+const PermissionMaintain = "maintain"
+const PermissionAdmin = "admin"
+
+const AccessKindTeam = "team"
+const AccessKindCollaborator = "collaborator"
+
+var teamSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+type TeamAccess struct {
+	Slug       string `json:"slug"`
+	Permission string `json:"permission,omitempty"`
+}
+
+type CollaboratorAccess struct {
+	Username   string `json:"username"`
+	Permission string `json:"permission,omitempty"`
+}
+
+// AccessRequest grants teams and collaborators access to a repository, it is
+// also accepted as part of a creation request.
+type AccessRequest struct {
+	Teams         []TeamAccess         `json:"teams,omitempty"`
+	Collaborators []CollaboratorAccess `json:"collaborators,omitempty"`
+}
+
+// AccessGrant is one applied grant. InvitationId is set for collaborators
+// who still have to accept an invitation before they get access.
+type AccessGrant struct {
+	Kind         string `json:"kind"`
+	Name         string `json:"name"`
+	Permission   string `json:"permission"`
+	InvitationId int64  `json:"invitation_id,omitempty"`
+}
+
+// AccessResponse separates the grants that are effective immediately from
+// the pending invitations.
+type AccessResponse struct {
+	Granted []AccessGrant `json:"granted"`
+	Invited []AccessGrant `json:"invited"`
+}
+
+func validatePermission(permission string) (string, errors.ApiError) {
+	permission = strings.ToLower(strings.TrimSpace(permission))
+	switch permission {
+	case "":
+		return PermissionPull, nil
+	case PermissionPull, PermissionTriage, PermissionPush, PermissionMaintain, PermissionAdmin:
+		return permission, nil
+	}
+	return "", errors.NewBadRequestApiError("invalid permission " + permission + ", expected pull, triage, push, maintain or admin")
+}
+
+func (r *AccessRequest) IsEmpty() bool {
+	return len(r.Teams) == 0 && len(r.Collaborators) == 0
+}
+
+func (r *AccessRequest) Validate() errors.ApiError {
+	var err errors.ApiError
+	for i := range r.Teams {
+		r.Teams[i].Slug = strings.ToLower(strings.TrimSpace(r.Teams[i].Slug))
+		if !teamSlugPattern.MatchString(r.Teams[i].Slug) {
+			return errors.NewBadRequestApiError("invalid team slug " + r.Teams[i].Slug)
+		}
+		if r.Teams[i].Permission, err = validatePermission(r.Teams[i].Permission); err != nil {
+			return err
+		}
+	}
+	for i := range r.Collaborators {
+		r.Collaborators[i].Username = strings.TrimSpace(r.Collaborators[i].Username)
+		if !usernamePattern.MatchString(r.Collaborators[i].Username) {
+			return errors.NewBadRequestApiError("invalid username " + r.Collaborators[i].Username)
+		}
+		if r.Collaborators[i].Permission, err = validatePermission(r.Collaborators[i].Permission); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAccessRequestValidate(t *testing.T) {
+	request := AccessRequest{
+		Teams:         []TeamAccess{{Slug: " Platform-Core "}},
+		Collaborators: []CollaboratorAccess{{Username: "octocat", Permission: "Maintain"}},
+	}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, AccessRequest{
+		Teams:         []TeamAccess{{Slug: "platform-core", Permission: PermissionPull}},
+		Collaborators: []CollaboratorAccess{{Username: "octocat", Permission: PermissionMaintain}},
+	}, request)
+	assert.False(t, request.IsEmpty())
+	assert.True(t, (&AccessRequest{}).IsEmpty())
+}
+
+func TestAccessRequestValidateErrors(t *testing.T) {
+	request := AccessRequest{Teams: []TeamAccess{{Slug: "platform core"}}}
+	assert.EqualValues(t, "invalid team slug platform core", request.Validate().Message())
+
+	request = AccessRequest{Teams: []TeamAccess{{Slug: ""}}}
+	assert.EqualValues(t, "invalid team slug ", request.Validate().Message())
+
+	request = AccessRequest{Collaborators: []CollaboratorAccess{{Username: "-octocat"}}}
+	assert.EqualValues(t, "invalid username -octocat", request.Validate().Message())
+
+	request = AccessRequest{Collaborators: []CollaboratorAccess{{Username: "octocat", Permission: "owner"}}}
+	assert.EqualValues(t, "invalid permission owner, expected pull, triage, push, maintain or admin", request.Validate().Message())
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"golang-microservices/src/api/utils/errors"
+	"regexp"
+	"strings"
+)
+
+const reservedActionsPrefix = "GITHUB_"
+
+var actionsNamePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// ActionsSecret sets the Actions secret Name from the secret Secret of the
+// server side store, the value never travels in a request.
+type ActionsSecret struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+type ActionsVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// validateActionsName applies the GitHub naming rules, names are case
+// insensitive there and stored upper case.
+func validateActionsName(name string) (string, errors.ApiError) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !actionsNamePattern.MatchString(name) || strings.HasPrefix(name, reservedActionsPrefix) {
+		return "", errors.NewBadRequestApiError("invalid actions name " + name)
+	}
+	return name, nil
+}
+
+func (s *ActionsSecret) Validate() errors.ApiError {
+	name, err := validateActionsName(s.Name)
+	if err != nil {
+		return err
+	}
+	s.Name = name
+	s.Secret = strings.TrimSpace(s.Secret)
+	if s.Secret == "" {
+		return errors.NewBadRequestApiError("invalid secret reference for " + s.Name)
+	}
+	return nil
+}
+
+func (v *ActionsVariable) Validate() errors.ApiError {
+	name, err := validateActionsName(v.Name)
+	if err != nil {
+		return err
+	}
+	v.Name = name
+	return nil
+}
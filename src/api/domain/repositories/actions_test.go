@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestActionsSecretValidate(t *testing.T) {
+	secret := ActionsSecret{Name: " deploy_key ", Secret: " prod-deploy "}
+	assert.Nil(t, secret.Validate())
+	assert.EqualValues(t, ActionsSecret{Name: "DEPLOY_KEY", Secret: "prod-deploy"}, secret)
+
+	secret = ActionsSecret{Name: "DEPLOY_KEY"}
+	assert.EqualValues(t, "invalid secret reference for DEPLOY_KEY", secret.Validate().Message())
+
+	for _, name := range []string{"", "1TOKEN", "NPM-TOKEN", "github_token"} {
+		secret = ActionsSecret{Name: name, Secret: "npm"}
+		assert.NotNil(t, secret.Validate(), name)
+	}
+}
+
+func TestActionsVariableValidate(t *testing.T) {
+	variable := ActionsVariable{Name: "go_version", Value: "1.17"}
+	assert.Nil(t, variable.Validate())
+	assert.EqualValues(t, "GO_VERSION", variable.Name)
+
+	variable = ActionsVariable{Name: "GITHUB_SHA"}
+	assert.EqualValues(t, "invalid actions name GITHUB_SHA", variable.Validate().Message())
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import "time"
+
+const AuditActionCreate = "create"
+const AuditActionDelete = "delete"
+const AuditActionArchive = "archive"
+const AuditActionTransfer = "transfer"
+
+// AuditRemoval is the payload of delete and archive entries.
+type AuditRemoval struct {
+	Provider string `json:"provider"`
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	Archive  bool   `json:"archive"`
+}
+
+// AuditEntry records one operation, Request is set on creations, Removal on
+// deletes and archives and Transfer on transfers.
+type AuditEntry struct {
+	Timestamp time.Time            `json:"timestamp"`
+	Action    string               `json:"action,omitempty"`
+	Caller    string               `json:"caller"`
+	BatchId   string               `json:"batch_id,omitempty"`
+	Request   *CreateRepoRequest   `json:"request,omitempty"`
+	Removal   *AuditRemoval        `json:"removal,omitempty"`
+	Transfer  *TransferRepoRequest `json:"transfer,omitempty"`
+	Owner     string               `json:"owner"`
+	RepoId    int64                `json:"repo_id,omitempty"`
+	FullName  string               `json:"full_name,omitempty"`
+	Status    int                  `json:"status"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// IsCreation tells whether the entry records a repository creation, entries
+// written before actions were recorded are all creations.
+func (e AuditEntry) IsCreation() bool {
+	return e.Action == "" || e.Action == AuditActionCreate
+}
+
+type AuditFilter struct {
+	Owner string
+	Since time.Time
+	Limit int
+}
+
+func (f AuditFilter) Matches(entry AuditEntry) bool {
+	if f.Owner != "" && f.Owner != entry.Owner {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	return true
+}
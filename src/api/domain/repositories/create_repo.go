@@ -1,32 +1,111 @@
 package repositories
 
 import (
+	"encoding/json"
 	"golang-microservices/src/api/utils/errors"
 	"strings"
 )
 
 type CreateRepoRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Org         string            `json:"org,omitempty"`
+	Provider    string            `json:"provider,omitempty"`
+	Team        string            `json:"team,omitempty"`
+	Templates   []string          `json:"templates,omitempty"`
+	Webhooks    []Webhook         `json:"webhooks,omitempty"`
+	Secrets     []ActionsSecret   `json:"secrets,omitempty"`
+	Variables   []ActionsVariable `json:"variables,omitempty"`
+	Access      *AccessRequest    `json:"access,omitempty"`
+	Protection  string            `json:"protection,omitempty"`
+	Labels      *LabelSync        `json:"labels,omitempty"`
+	Topics      []string          `json:"topics,omitempty"`
+
+	// AutoInit asks the provider for an initial commit so the default branch
+	// exists right away, the service sets it when the branch gets protected.
+	AutoInit bool `json:"-"`
+}
+
+// validateName holds the naming rules shared by every request that sets a
+// repository name.
+func validateName(name string) errors.ApiError {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+		return errors.NewBadRequestApiError("invalid repository name")
+	}
+	return nil
 }
 
 func (r *CreateRepoRequest) Validate() errors.ApiError {
 	r.Name = strings.TrimSpace(r.Name)
-	if r.Name == "" {
-		return errors.NewBadRequestApiError("invalid repository name")
+	r.Org = strings.TrimSpace(r.Org)
+	r.Provider = strings.ToLower(strings.TrimSpace(r.Provider))
+	r.Team = strings.TrimSpace(r.Team)
+	r.Protection = strings.TrimSpace(r.Protection)
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	for i := range r.Templates {
+		r.Templates[i] = strings.Trim(strings.TrimSpace(r.Templates[i]), "/")
+		if r.Templates[i] == "" {
+			return errors.NewBadRequestApiError("invalid template")
+		}
+	}
+	for i := range r.Webhooks {
+		if err := r.Webhooks[i].Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range r.Secrets {
+		if err := r.Secrets[i].Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range r.Variables {
+		if err := r.Variables[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Labels != nil {
+		if err := r.Labels.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Topics != nil {
+		topics, err := ValidateTopics(r.Topics)
+		if err != nil {
+			return err
+		}
+		r.Topics = topics
+	}
+	if r.Access != nil {
+		if err := r.Access.Validate(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 type CreateRepoResponse struct {
-	Id    int64  `json:"id"`
-	Owner string `json:"owner"`
-	Name  string `json:"name"`
+	Id        int64            `json:"id"`
+	Owner     string           `json:"owner"`
+	Name      string           `json:"name"`
+	FullName  string           `json:"full_name,omitempty"`
+	Provider  string           `json:"provider,omitempty"`
+	Files     []string         `json:"files,omitempty"`
+	HookIds   []int64          `json:"hook_ids,omitempty"`
+	Secrets   []string         `json:"secrets,omitempty"`
+	Variables []string         `json:"variables,omitempty"`
+	Access    *AccessResponse  `json:"access,omitempty"`
+	Protected string           `json:"protected,omitempty"`
+	Labels    *LabelSyncResult `json:"labels,omitempty"`
+	Topics    []string         `json:"topics,omitempty"`
+	Warnings  []string         `json:"warnings,omitempty"`
 }
 
 type CreateReposResponse struct {
 	StatusCode int                       `json:"status"`
+	BatchId    string                    `json:"batch_id,omitempty"`
 	Results    []CreateRepositoresResult `json:"results"`
 }
 
@@ -34,3 +113,29 @@ type CreateRepositoresResult struct {
 	Response *CreateRepoResponse `json:"response"`
 	Error    errors.ApiError     `json:"error"`
 }
+
+// decodeResultError reads the error of a batch result, the interface field
+// cannot be decoded on its own.
+func decodeResultError(raw json.RawMessage) (errors.ApiError, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	return errors.NewApiErrorFromBytes(raw)
+}
+
+func (r *CreateRepositoresResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Response *CreateRepoResponse `json:"response"`
+		Error    json.RawMessage     `json:"error"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	apiErr, err := decodeResultError(raw.Error)
+	if err != nil {
+		return err
+	}
+	r.Response, r.Error = raw.Response, apiErr
+	return nil
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+	"testing"
+)
+
+func TestCreateReposResponseRoundTrip(t *testing.T) {
+	response := CreateReposResponse{
+		StatusCode: http.StatusPartialContent,
+		BatchId:    "abc",
+		Results: []CreateRepositoresResult{
+			{Response: &CreateRepoResponse{Id: 1, Owner: "my-org", Name: "one"}},
+			{Error: errors.NewBadRequestApiError("invalid repository name")},
+		},
+	}
+	bytes, err := json.Marshal(response)
+	assert.Nil(t, err)
+
+	var decoded CreateReposResponse
+	assert.Nil(t, json.Unmarshal(bytes, &decoded))
+	assert.EqualValues(t, http.StatusPartialContent, decoded.StatusCode)
+	assert.EqualValues(t, "one", decoded.Results[0].Response.Name)
+	assert.Nil(t, decoded.Results[0].Error)
+	assert.EqualValues(t, http.StatusBadRequest, decoded.Results[1].Error.Status())
+	assert.EqualValues(t, "invalid repository name", decoded.Results[1].Error.Message())
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"encoding/json"
+	"golang-microservices/src/api/utils/errors"
+	"strings"
+)
+
+const ActionDeleted = "deleted"
+const ActionArchived = "archived"
+
+type DeleteRepoRequest struct {
+	Provider string
+	Owner    string
+	Name     string
+	Archive  bool
+	Confirm  string
+}
+
+func (r *DeleteRepoRequest) Validate() errors.ApiError {
+	r.Owner = strings.TrimSpace(r.Owner)
+	r.Name = strings.TrimSpace(r.Name)
+	r.Confirm = strings.TrimSpace(r.Confirm)
+	if r.Owner == "" || r.Name == "" {
+		return errors.NewBadRequestApiError("invalid repository owner or name")
+	}
+
+	return nil
+}
+
+// ConfirmationToken is the value callers have to echo back to prove they
+// meant to remove this very repository.
+func (r *DeleteRepoRequest) ConfirmationToken() string {
+	return r.Owner + "/" + r.Name
+}
+
+// DeleteReposRequest removes every repository of Owner whose name starts
+// with Prefix.
+type DeleteReposRequest struct {
+	Provider string
+	Owner    string
+	Prefix   string
+	Archive  bool
+	Confirm  string
+}
+
+func (r *DeleteReposRequest) Validate() errors.ApiError {
+	r.Owner = strings.TrimSpace(r.Owner)
+	r.Prefix = strings.TrimSpace(r.Prefix)
+	r.Confirm = strings.TrimSpace(r.Confirm)
+	if r.Owner == "" {
+		return errors.NewBadRequestApiError("invalid repository owner")
+	}
+	if r.Prefix == "" {
+		return errors.NewBadRequestApiError("invalid prefix")
+	}
+
+	return nil
+}
+
+func (r *DeleteReposRequest) ConfirmationToken() string {
+	return r.Owner + "/" + r.Prefix + "*"
+}
+
+type DeleteRepoResponse struct {
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Provider string `json:"provider,omitempty"`
+	Action   string `json:"action"`
+}
+
+type DeleteReposResponse struct {
+	StatusCode int                        `json:"status"`
+	Results    []DeleteRepositoriesResult `json:"results"`
+}
+
+type DeleteRepositoriesResult struct {
+	Response *DeleteRepoResponse `json:"response"`
+	Error    errors.ApiError     `json:"error"`
+}
+
+func (r *DeleteRepositoriesResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Response *DeleteRepoResponse `json:"response"`
+		Error    json.RawMessage     `json:"error"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	apiErr, err := decodeResultError(raw.Error)
+	if err != nil {
+		return err
+	}
+	r.Response, r.Error = raw.Response, apiErr
+	return nil
+}
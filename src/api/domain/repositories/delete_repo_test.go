@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestDeleteRepoRequestValidate(t *testing.T) {
+	request := DeleteRepoRequest{Owner: " my-org ", Name: "", Confirm: "my-org/one"}
+	err := request.Validate()
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "invalid repository owner or name", err.Message())
+
+	request.Name = " one "
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "my-org/one", request.ConfirmationToken())
+}
+
+func TestDeleteReposRequestValidate(t *testing.T) {
+	request := DeleteReposRequest{Owner: "my-org", Prefix: "  "}
+	err := request.Validate()
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "invalid prefix", err.Message())
+
+	request.Prefix = "ci-"
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "my-org/ci-*", request.ConfirmationToken())
+}
+
+func TestDeleteReposResponseUnmarshal(t *testing.T) {
+	var response DeleteReposResponse
+	err := json.Unmarshal([]byte(`{"status": 206, "results": [
+		{"response": {"owner": "my-org", "name": "one", "full_name": "my-org/one", "action": "deleted"}, "error": null},
+		{"response": null, "error": {"status": 403, "message": "Must have admin rights to Repository."}}
+	]}`), &response)
+	assert.Nil(t, err)
+	assert.EqualValues(t, ActionDeleted, response.Results[0].Response.Action)
+	assert.Nil(t, response.Results[0].Error)
+	assert.EqualValues(t, http.StatusForbidden, response.Results[1].Error.Status())
+}
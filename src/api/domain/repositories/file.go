@@ -0,0 +1,8 @@
+package repositories
+
+// File is a file committed to a repository, Path is slash separated and
+// relative to the repository root.
+type File struct {
+	Path    string
+	Content []byte
+}
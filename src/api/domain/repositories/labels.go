@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"fmt"
+	"golang-microservices/src/api/utils/errors"
+	"regexp"
+	"strings"
+)
+
+const MaxTopics = 20
+const maxTopicLength = 50
+const maxLabelLength = 50
+
+var topicPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+var colorPattern = regexp.MustCompile(`^[0-9a-f]{6}$`)
+
+// DefaultLabels are the labels GitHub adds to every new repository, they are
+// the only ones a sync removes without being asked for by name.
+var DefaultLabels = []string{
+	"bug", "documentation", "duplicate", "enhancement", "good first issue",
+	"help wanted", "invalid", "question", "wontfix",
+}
+
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description,omitempty"`
+}
+
+func (l *Label) Validate() errors.ApiError {
+	l.Name = strings.TrimSpace(l.Name)
+	l.Color = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(l.Color), "#"))
+	l.Description = strings.TrimSpace(l.Description)
+	if l.Name == "" || len(l.Name) > maxLabelLength {
+		return errors.NewBadRequestApiError("invalid label name " + l.Name)
+	}
+	if !colorPattern.MatchString(l.Color) {
+		return errors.NewBadRequestApiError("invalid color " + l.Color + " of label " + l.Name + ", expected 6 hex digits")
+	}
+	return nil
+}
+
+// LabelSync selects a label set of the configuration. RemoveDefaults also
+// deletes the GitHub default labels missing from the set, other labels are
+// always kept.
+type LabelSync struct {
+	Set            string `json:"set"`
+	RemoveDefaults bool   `json:"remove_defaults,omitempty"`
+}
+
+type LabelSyncResult struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+}
+
+// SyncRequest updates labels and topics of an existing repository. A nil
+// Topics leaves them untouched, an empty list removes them all.
+type SyncRequest struct {
+	Labels *LabelSync `json:"labels,omitempty"`
+	Topics []string   `json:"topics"`
+}
+
+type SyncResponse struct {
+	Labels *LabelSyncResult `json:"labels,omitempty"`
+	Topics []string         `json:"topics,omitempty"`
+}
+
+func (r *SyncRequest) Validate() errors.ApiError {
+	if r.Labels == nil && r.Topics == nil {
+		return errors.NewBadRequestApiError("nothing to sync")
+	}
+	if r.Labels != nil {
+		if err := r.Labels.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Topics != nil {
+		topics, err := ValidateTopics(r.Topics)
+		if err != nil {
+			return err
+		}
+		r.Topics = topics
+	}
+	return nil
+}
+
+func (s *LabelSync) Validate() errors.ApiError {
+	s.Set = strings.TrimSpace(s.Set)
+	if s.Set == "" {
+		return errors.NewBadRequestApiError("invalid label set")
+	}
+	return nil
+}
+
+// ValidateTopics lower cases and deduplicates topics, then applies the
+// GitHub rules: letters, digits and hyphens, at most 50 characters each and
+// 20 topics.
+func ValidateTopics(topics []string) ([]string, errors.ApiError) {
+	result := make([]string, 0, len(topics))
+	seen := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		topic = strings.ToLower(strings.TrimSpace(topic))
+		if !topicPattern.MatchString(topic) || len(topic) > maxTopicLength {
+			return nil, errors.NewBadRequestApiError("invalid topic " + topic)
+		}
+		if !seen[topic] {
+			seen[topic] = true
+			result = append(result, topic)
+		}
+	}
+	if len(result) > MaxTopics {
+		return nil, errors.NewBadRequestApiError(fmt.Sprintf("too many topics, at most %d are allowed", MaxTopics))
+	}
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLabelValidate(t *testing.T) {
+	label := Label{Name: " needs triage ", Color: "#D73A4A"}
+	assert.Nil(t, label.Validate())
+	assert.EqualValues(t, Label{Name: "needs triage", Color: "d73a4a"}, label)
+
+	label = Label{Name: "bug", Color: "red"}
+	assert.EqualValues(t, "invalid color red of label bug, expected 6 hex digits", label.Validate().Message())
+
+	label = Label{Color: "d73a4a"}
+	assert.EqualValues(t, "invalid label name ", label.Validate().Message())
+}
+
+func TestValidateTopics(t *testing.T) {
+	topics, err := ValidateTopics([]string{" Go ", "payments", "go"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go", "payments"}, topics)
+
+	_, err = ValidateTopics([]string{"go lang"})
+	assert.EqualValues(t, "invalid topic go lang", err.Message())
+
+	_, err = ValidateTopics([]string{strings.Repeat("a", 51)})
+	assert.NotNil(t, err)
+
+	tooMany := make([]string, 0, 21)
+	for i := 0; i < 21; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("topic-%d", i))
+	}
+	_, err = ValidateTopics(tooMany)
+	assert.EqualValues(t, "too many topics, at most 20 are allowed", err.Message())
+}
+
+func TestSyncRequestValidate(t *testing.T) {
+	request := SyncRequest{}
+	assert.EqualValues(t, "nothing to sync", request.Validate().Message())
+
+	request = SyncRequest{Topics: []string{}}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, []string{}, request.Topics)
+
+	request = SyncRequest{Labels: &LabelSync{Set: " "}}
+	assert.EqualValues(t, "invalid label set", request.Validate().Message())
+}
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"fmt"
+	"golang-microservices/src/api/utils/errors"
+	"gopkg.in/yaml.v2"
+	"strings"
+)
+
+const ReconcileCreate = "create"
+const ReconcileUpdate = "update"
+const ReconcileArchive = "archive"
+
+// ManifestRepository is the declared state of one repository. Nil fields are
+// not managed by the manifest and never reported as drift.
+type ManifestRepository struct {
+	Name        string       `yaml:"name" json:"name"`
+	Owner       string       `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Description *string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Private     *bool        `yaml:"private,omitempty" json:"private,omitempty"`
+	Teams       []TeamAccess `yaml:"teams,omitempty" json:"teams,omitempty"`
+	Topics      []string     `yaml:"topics,omitempty" json:"topics,omitempty"`
+	Protection  string       `yaml:"protection,omitempty" json:"protection,omitempty"`
+	Templates   []string     `yaml:"templates,omitempty" json:"templates,omitempty"`
+}
+
+// Manifest lists every repository of its owners. Owner is the default owner
+// of the repositories not naming one, ArchiveMissing archives the live
+// repositories of those owners missing from the manifest. Confirm comes from
+// the request headers, not the manifest.
+type Manifest struct {
+	Provider       string               `yaml:"provider,omitempty" json:"provider,omitempty"`
+	Owner          string               `yaml:"owner,omitempty" json:"owner,omitempty"`
+	ArchiveMissing bool                 `yaml:"archive_missing,omitempty" json:"archive_missing,omitempty"`
+	Repositories   []ManifestRepository `yaml:"repositories" json:"repositories"`
+	Confirm        string               `yaml:"-" json:"-"`
+}
+
+// ParseManifest reads a yaml manifest, json being valid yaml it accepts both.
+func ParseManifest(content []byte) (*Manifest, errors.ApiError) {
+	var manifest Manifest
+	if err := yaml.UnmarshalStrict(content, &manifest); err != nil {
+		return nil, errors.NewBadRequestApiError("invalid manifest: " + err.Error())
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+func (m *Manifest) Validate() errors.ApiError {
+	m.Provider = strings.ToLower(strings.TrimSpace(m.Provider))
+	m.Owner = strings.TrimSpace(m.Owner)
+	m.Confirm = strings.TrimSpace(m.Confirm)
+
+	seen := make(map[string]bool, len(m.Repositories))
+	for i := range m.Repositories {
+		repo := &m.Repositories[i]
+		repo.Name = strings.TrimSpace(repo.Name)
+		if repo.Owner = strings.TrimSpace(repo.Owner); repo.Owner == "" {
+			repo.Owner = m.Owner
+		}
+		if err := validateName(repo.Name); err != nil {
+			return err
+		}
+		if repo.Owner == "" {
+			return errors.NewBadRequestApiError("repository " + repo.Name + " has no owner")
+		}
+
+		key := strings.ToLower(repo.Owner + "/" + repo.Name)
+		if seen[key] {
+			return errors.NewBadRequestApiError(fmt.Sprintf("duplicate repository %s/%s", repo.Owner, repo.Name))
+		}
+		seen[key] = true
+
+		if repo.Description != nil {
+			description := strings.TrimSpace(*repo.Description)
+			repo.Description = &description
+		}
+		access := AccessRequest{Teams: repo.Teams}
+		if err := access.Validate(); err != nil {
+			return err
+		}
+		if repo.Topics != nil {
+			topics, err := ValidateTopics(repo.Topics)
+			if err != nil {
+				return err
+			}
+			repo.Topics = topics
+		}
+		repo.Protection = strings.TrimSpace(repo.Protection)
+		for j := range repo.Templates {
+			repo.Templates[j] = strings.Trim(strings.TrimSpace(repo.Templates[j]), "/")
+		}
+	}
+
+	// Archiving the live repositories of an owner with nothing declared
+	// would archive all of them.
+	if m.ArchiveMissing && m.Owner != "" {
+		for _, repo := range m.Repositories {
+			if strings.EqualFold(repo.Owner, m.Owner) {
+				return nil
+			}
+		}
+		return errors.NewBadRequestApiError("archive_missing requires repositories declared for owner " + m.Owner)
+	}
+	return nil
+}
+
+// ConfirmationToken is the value callers have to echo back when applying the
+// manifest archives repositories, every owner is matched as a whole like a
+// DeleteReposRequest without prefix.
+func (m *Manifest) ConfirmationToken() string {
+	owners := m.Owners()
+	for i := range owners {
+		owners[i] += "/*"
+	}
+	return strings.Join(owners, ",")
+}
+
+// Owners lists the distinct owners of the manifest in order of appearance.
+func (m *Manifest) Owners() []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, repo := range m.Repositories {
+		if key := strings.ToLower(repo.Owner); !seen[key] {
+			seen[key] = true
+			result = append(result, repo.Owner)
+		}
+	}
+	if m.Owner != "" && !seen[strings.ToLower(m.Owner)] {
+		result = append(result, m.Owner)
+	}
+	return result
+}
+
+// ReconcileAction is one step of a plan, Status and Error are only set once
+// the plan is applied.
+type ReconcileAction struct {
+	Action   string   `json:"action"`
+	Owner    string   `json:"owner"`
+	Name     string   `json:"name"`
+	Changes  []string `json:"changes,omitempty"`
+	Status   int      `json:"status,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ReconcileResponse struct {
+	StatusCode int               `json:"status"`
+	BatchId    string            `json:"batch_id,omitempty"`
+	PlanOnly   bool              `json:"plan_only"`
+	Actions    []ReconcileAction `json:"actions"`
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	manifest, err := ParseManifest([]byte(`
+owner: my-org
+archive_missing: true
+repositories:
+  - name: payments
+    description: " Payments API "
+    private: false
+    teams:
+      - slug: Core
+        permission: push
+    topics: [Go, payments]
+    protection: strict
+    templates: [go-service/]
+  - name: tools
+    owner: platform
+`))
+	assert.Nil(t, err)
+	assert.True(t, manifest.ArchiveMissing)
+	assert.EqualValues(t, 2, len(manifest.Repositories))
+
+	payments := manifest.Repositories[0]
+	assert.EqualValues(t, "my-org", payments.Owner)
+	assert.EqualValues(t, "Payments API", *payments.Description)
+	assert.False(t, *payments.Private)
+	assert.EqualValues(t, []TeamAccess{{Slug: "core", Permission: PermissionPush}}, payments.Teams)
+	assert.EqualValues(t, []string{"go", "payments"}, payments.Topics)
+	assert.EqualValues(t, []string{"go-service"}, payments.Templates)
+
+	tools := manifest.Repositories[1]
+	assert.Nil(t, tools.Description)
+	assert.Nil(t, tools.Private)
+	assert.Nil(t, tools.Topics)
+	assert.EqualValues(t, []string{"my-org", "platform"}, manifest.Owners())
+	assert.EqualValues(t, "my-org/*,platform/*", manifest.ConfirmationToken())
+}
+
+func TestParseManifestJson(t *testing.T) {
+	manifest, err := ParseManifest([]byte(`{"owner": "my-org", "repositories": [{"name": "payments", "topics": []}]}`))
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{}, manifest.Repositories[0].Topics)
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	_, err := ParseManifest([]byte(`repositories: [{name: payments, colour: red}]`))
+	assert.Contains(t, err.Message(), "invalid manifest: ")
+
+	_, err = ParseManifest([]byte(`repositories: [{name: payments}]`))
+	assert.EqualValues(t, "repository payments has no owner", err.Message())
+
+	_, err = ParseManifest([]byte(`{owner: my-org, repositories: [{name: payments}, {name: Payments, owner: My-Org}]}`))
+	assert.EqualValues(t, "duplicate repository My-Org/Payments", err.Message())
+
+	_, err = ParseManifest([]byte(`{owner: my-org, repositories: [{name: payments, topics: ["not valid"]}]}`))
+	assert.EqualValues(t, "invalid topic not valid", err.Message())
+
+	_, err = ParseManifest([]byte(`{owner: my-org, archive_missing: true, repositories: [{name: tools, owner: platform}]}`))
+	assert.EqualValues(t, "archive_missing requires repositories declared for owner my-org", err.Message())
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"golang-microservices/src/api/utils/errors"
+	"sort"
+	"strings"
+)
+
+// BranchProtection is the provider neutral view of the rules on a branch,
+// the zero value stands for an unprotected branch.
+type BranchProtection struct {
+	RequiredReviews         int      `json:"required_reviews"`
+	DismissStaleReviews     bool     `json:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews bool     `json:"require_code_owner_reviews"`
+	RequiredStatusChecks    []string `json:"required_status_checks"`
+	StrictStatusChecks      bool     `json:"strict_status_checks"`
+	EnforceAdmins           bool     `json:"enforce_admins"`
+	RequireLinearHistory    bool     `json:"require_linear_history"`
+	BlockForcePushes        bool     `json:"block_force_pushes"`
+	BlockDeletions          bool     `json:"block_deletions"`
+}
+
+// ProtectionPolicy is a named protection from the configuration. Ruleset
+// selects repository rulesets instead of the classic branch protection on
+// providers having both.
+type ProtectionPolicy struct {
+	Name    string `json:"name"`
+	Ruleset bool   `json:"ruleset,omitempty"`
+	BranchProtection
+}
+
+type ProtectionRequest struct {
+	Policy string `json:"policy,omitempty"`
+	Branch string `json:"branch,omitempty"`
+}
+
+func (r *ProtectionRequest) Validate() errors.ApiError {
+	r.Policy = strings.TrimSpace(r.Policy)
+	r.Branch = strings.TrimSpace(r.Branch)
+	if strings.Contains(r.Branch, "..") || strings.ContainsAny(r.Branch, " ~^:?*[\\") {
+		return errors.NewBadRequestApiError("invalid branch name")
+	}
+	return nil
+}
+
+type ProtectionDrift struct {
+	Field    string      `json:"field"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual"`
+}
+
+type ProtectionReport struct {
+	Owner  string            `json:"owner"`
+	Name   string            `json:"name"`
+	Branch string            `json:"branch"`
+	Policy string            `json:"policy"`
+	InSync bool              `json:"in_sync"`
+	Drift  []ProtectionDrift `json:"drift"`
+}
+
+func sortedChecks(checks []string) []string {
+	result := append(make([]string, 0, len(checks)), checks...)
+	sort.Strings(result)
+	return result
+}
+
+// Diff lists the fields of actual differing from p, status checks are
+// compared regardless of their order.
+func (p BranchProtection) Diff(actual BranchProtection) []ProtectionDrift {
+	drift := make([]ProtectionDrift, 0)
+	add := func(field string, expected interface{}, current interface{}, equal bool) {
+		if !equal {
+			drift = append(drift, ProtectionDrift{Field: field, Expected: expected, Actual: current})
+		}
+	}
+
+	add("required_reviews", p.RequiredReviews, actual.RequiredReviews, p.RequiredReviews == actual.RequiredReviews)
+	add("dismiss_stale_reviews", p.DismissStaleReviews, actual.DismissStaleReviews, p.DismissStaleReviews == actual.DismissStaleReviews)
+	add("require_code_owner_reviews", p.RequireCodeOwnerReviews, actual.RequireCodeOwnerReviews, p.RequireCodeOwnerReviews == actual.RequireCodeOwnerReviews)
+
+	expectedChecks, actualChecks := sortedChecks(p.RequiredStatusChecks), sortedChecks(actual.RequiredStatusChecks)
+	add("required_status_checks", expectedChecks, actualChecks, strings.Join(expectedChecks, "\n") == strings.Join(actualChecks, "\n"))
+	add("strict_status_checks", p.StrictStatusChecks, actual.StrictStatusChecks, p.StrictStatusChecks == actual.StrictStatusChecks)
+
+	add("enforce_admins", p.EnforceAdmins, actual.EnforceAdmins, p.EnforceAdmins == actual.EnforceAdmins)
+	add("require_linear_history", p.RequireLinearHistory, actual.RequireLinearHistory, p.RequireLinearHistory == actual.RequireLinearHistory)
+	add("block_force_pushes", p.BlockForcePushes, actual.BlockForcePushes, p.BlockForcePushes == actual.BlockForcePushes)
+	add("block_deletions", p.BlockDeletions, actual.BlockDeletions, p.BlockDeletions == actual.BlockDeletions)
+
+	return drift
+}
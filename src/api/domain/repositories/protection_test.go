@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBranchProtectionDiff(t *testing.T) {
+	policy := BranchProtection{
+		RequiredReviews:      2,
+		RequiredStatusChecks: []string{"test", "lint"},
+		BlockForcePushes:     true,
+	}
+
+	assert.EqualValues(t, []ProtectionDrift{}, policy.Diff(BranchProtection{
+		RequiredReviews:      2,
+		RequiredStatusChecks: []string{"lint", "test"},
+		BlockForcePushes:     true,
+	}))
+
+	assert.EqualValues(t, []ProtectionDrift{
+		{Field: "required_reviews", Expected: 2, Actual: 0},
+		{Field: "required_status_checks", Expected: []string{"lint", "test"}, Actual: []string{}},
+		{Field: "block_force_pushes", Expected: true, Actual: false},
+	}, policy.Diff(BranchProtection{}))
+}
+
+func TestProtectionRequestValidate(t *testing.T) {
+	request := ProtectionRequest{Policy: " strict ", Branch: " release/1.x "}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, ProtectionRequest{Policy: "strict", Branch: "release/1.x"}, request)
+
+	for _, branch := range []string{"a..b", "feature x", "main:other"} {
+		request := ProtectionRequest{Branch: branch}
+		err := request.Validate()
+		if assert.NotNil(t, err, branch) {
+			assert.EqualValues(t, "invalid branch name", err.Message())
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"golang-microservices/src/api/utils/errors"
+	"strings"
+)
+
+const MaxPerPage = 100
+const visibilityAll = "all"
+
+type Repository struct {
+	Id            int64    `json:"id"`
+	Owner         string   `json:"owner"`
+	Name          string   `json:"name"`
+	FullName      string   `json:"full_name"`
+	Description   string   `json:"description"`
+	Homepage      string   `json:"homepage,omitempty"`
+	Private       bool     `json:"private"`
+	Archived      bool     `json:"archived"`
+	DefaultBranch string   `json:"default_branch,omitempty"`
+	HtmlUrl       string   `json:"html_url,omitempty"`
+	Topics        []string `json:"topics,omitempty"`
+	Provider      string   `json:"provider,omitempty"`
+}
+
+type ListReposOptions struct {
+	Owner      string
+	Visibility string
+	Page       int
+	PerPage    int
+}
+
+func (o *ListReposOptions) Validate() errors.ApiError {
+	o.Owner = strings.TrimSpace(o.Owner)
+	o.Visibility = strings.ToLower(strings.TrimSpace(o.Visibility))
+
+	switch o.Visibility {
+	case "", "public", "private":
+	case visibilityAll:
+		o.Visibility = ""
+	default:
+		return errors.NewBadRequestApiError("invalid visibility, expected public, private or all")
+	}
+	if o.Page < 0 {
+		return errors.NewBadRequestApiError("invalid page")
+	}
+	if o.PerPage < 0 || o.PerPage > MaxPerPage {
+		return errors.NewBadRequestApiError("invalid per_page, expected at most 100")
+	}
+
+	return nil
+}
+
+type RepositoryPage struct {
+	Repositories []Repository `json:"repositories"`
+	Page         int          `json:"page"`
+	NextPage     int          `json:"next_page,omitempty"`
+	LastPage     int          `json:"last_page,omitempty"`
+}
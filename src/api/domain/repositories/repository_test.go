@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestListReposOptionsValidate(t *testing.T) {
+	options := ListReposOptions{Owner: " my-org ", Visibility: "All"}
+	assert.Nil(t, options.Validate())
+	assert.EqualValues(t, ListReposOptions{Owner: "my-org"}, options)
+
+	options = ListReposOptions{Visibility: "internal"}
+	assert.EqualValues(t, "invalid visibility, expected public, private or all", options.Validate().Message())
+
+	options = ListReposOptions{Page: -1}
+	assert.EqualValues(t, "invalid page", options.Validate().Message())
+
+	options = ListReposOptions{PerPage: 101}
+	assert.EqualValues(t, "invalid per_page, expected at most 100", options.Validate().Message())
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"golang-microservices/src/api/utils/errors"
+	"strings"
+)
+
+type TransferRepoRequest struct {
+	NewOwner string  `json:"new_owner"`
+	NewName  string  `json:"new_name,omitempty"`
+	TeamIds  []int64 `json:"team_ids,omitempty"`
+}
+
+func (r *TransferRepoRequest) Validate() errors.ApiError {
+	r.NewOwner = strings.TrimSpace(r.NewOwner)
+	r.NewName = strings.TrimSpace(r.NewName)
+	if r.NewOwner == "" {
+		return errors.NewBadRequestApiError("invalid new owner")
+	}
+	if r.NewName != "" {
+		if err := validateName(r.NewName); err != nil {
+			return err
+		}
+	}
+	for _, teamId := range r.TeamIds {
+		if teamId <= 0 {
+			return errors.NewBadRequestApiError("invalid team id")
+		}
+	}
+
+	return nil
+}
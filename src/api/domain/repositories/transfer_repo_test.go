@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransferRepoRequestValidate(t *testing.T) {
+	cases := map[string]string{
+		`{}`:                  "invalid new owner",
+		`{"new_owner": "  "}`: "invalid new owner",
+		`{"new_owner": "new-org", "team_ids": [0]}`:   "invalid team id",
+		`{"new_owner": "new-org", "new_name": " "}`:   "",
+		`{"new_owner": "new-org", "new_name": "a/b"}`: "invalid repository name",
+		`{"new_owner": "new-org", "new_name": ".."}`:  "invalid repository name",
+		`{"new_owner": "new-org", "team_ids": [12]}`:  "",
+	}
+
+	for body, expected := range cases {
+		var request TransferRepoRequest
+		assert.Nil(t, json.Unmarshal([]byte(body), &request))
+
+		err := request.Validate()
+		if expected == "" {
+			assert.Nil(t, err, body)
+			continue
+		}
+		assert.EqualValues(t, expected, err.Message(), body)
+	}
+}
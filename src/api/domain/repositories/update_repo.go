@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"golang-microservices/src/api/utils/errors"
+	"net/url"
+	"strings"
+)
+
+// UpdateRepoRequest is a partial update, nil fields are left untouched.
+type UpdateRepoRequest struct {
+	Name          *string `json:"name,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Homepage      *string `json:"homepage,omitempty"`
+	Private       *bool   `json:"private,omitempty"`
+	Archived      *bool   `json:"archived,omitempty"`
+	DefaultBranch *string `json:"default_branch,omitempty"`
+}
+
+func trimField(field *string) {
+	if field != nil {
+		*field = strings.TrimSpace(*field)
+	}
+}
+
+func (r *UpdateRepoRequest) Validate() errors.ApiError {
+	trimField(r.Name)
+	trimField(r.Description)
+	trimField(r.Homepage)
+	trimField(r.DefaultBranch)
+
+	if r.Name == nil && r.Description == nil && r.Homepage == nil &&
+		r.Private == nil && r.Archived == nil && r.DefaultBranch == nil {
+		return errors.NewBadRequestApiError("nothing to update")
+	}
+	if r.Name != nil {
+		if err := validateName(*r.Name); err != nil {
+			return err
+		}
+	}
+	if r.DefaultBranch != nil && *r.DefaultBranch == "" {
+		return errors.NewBadRequestApiError("invalid default branch")
+	}
+	if r.Homepage != nil && *r.Homepage != "" {
+		homepage, err := url.Parse(*r.Homepage)
+		if err != nil || (homepage.Scheme != "http" && homepage.Scheme != "https") || homepage.Host == "" {
+			return errors.NewBadRequestApiError("invalid homepage, expected an http or https url")
+		}
+	}
+
+	return nil
+}
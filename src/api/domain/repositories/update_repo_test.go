@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUpdateRepoRequestValidate(t *testing.T) {
+	cases := map[string]string{
+		`{}`:                                   "nothing to update",
+		`{"name": "  "}`:                       "invalid repository name",
+		`{"default_branch": ""}`:               "invalid default branch",
+		`{"homepage": "ftp://example.com"}`:    "invalid homepage, expected an http or https url",
+		`{"homepage": "https://"}`:             "invalid homepage, expected an http or https url",
+		`{"archived": true}`:                   "",
+		`{"homepage": ""}`:                     "",
+		`{"description": " new description "}`: "",
+	}
+
+	for body, expected := range cases {
+		var request UpdateRepoRequest
+		assert.Nil(t, json.Unmarshal([]byte(body), &request))
+
+		err := request.Validate()
+		if expected == "" {
+			assert.Nil(t, err, body)
+			continue
+		}
+		assert.EqualValues(t, expected, err.Message(), body)
+	}
+}
+
+func TestUpdateRepoRequestValidateTrims(t *testing.T) {
+	description, branch := " new description ", " main "
+	request := UpdateRepoRequest{Description: &description, DefaultBranch: &branch}
+
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "new description", *request.Description)
+	assert.EqualValues(t, "main", *request.DefaultBranch)
+	assert.Nil(t, request.Private)
+}
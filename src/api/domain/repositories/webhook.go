@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"golang-microservices/src/api/utils/errors"
+	"net/url"
+	"strings"
+)
+
+const WebhookContentTypeJson = "json"
+const WebhookContentTypeForm = "form"
+const defaultWebhookEvent = "push"
+
+// Webhook describes a hook to create on a repository. Secret is the name of a
+// secret of the server side store, never the secret value itself.
+type Webhook struct {
+	Url         string   `json:"url"`
+	ContentType string   `json:"content_type,omitempty"`
+	Events      []string `json:"events,omitempty"`
+	Secret      string   `json:"secret,omitempty"`
+}
+
+// Redacted keeps only the scheme and host of the url, chat receivers often
+// carry their token in the path. It is used wherever a hook is logged or
+// stored.
+func (w Webhook) Redacted() Webhook {
+	if hookUrl, err := url.Parse(w.Url); err == nil {
+		w.Url = hookUrl.Scheme + "://" + hookUrl.Host
+	}
+	return w
+}
+
+func (w *Webhook) Validate() errors.ApiError {
+	w.Url = strings.TrimSpace(w.Url)
+	w.ContentType = strings.ToLower(strings.TrimSpace(w.ContentType))
+	w.Secret = strings.TrimSpace(w.Secret)
+
+	hookUrl, err := url.Parse(w.Url)
+	if err != nil || (hookUrl.Scheme != "http" && hookUrl.Scheme != "https") || hookUrl.Host == "" {
+		return errors.NewBadRequestApiError("invalid webhook url, expected an http or https url")
+	}
+	switch w.ContentType {
+	case "":
+		w.ContentType = WebhookContentTypeJson
+	case WebhookContentTypeJson, WebhookContentTypeForm:
+	default:
+		return errors.NewBadRequestApiError("invalid webhook content type, expected json or form")
+	}
+
+	if len(w.Events) == 0 {
+		w.Events = []string{defaultWebhookEvent}
+	}
+	for i := range w.Events {
+		w.Events[i] = strings.TrimSpace(w.Events[i])
+		if w.Events[i] == "" {
+			return errors.NewBadRequestApiError("invalid webhook event")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWebhookValidate(t *testing.T) {
+	cases := map[string]string{
+		`{"url": "ftp://ci.example.com"}`:                            "invalid webhook url, expected an http or https url",
+		`{"url": "https://ci.example.com", "content_type": "xml"}`:   "invalid webhook content type, expected json or form",
+		`{"url": "https://ci.example.com", "events": ["push", " "]}`: "invalid webhook event",
+		`{"url": "https://ci.example.com", "content_type": "Form"}`:  "",
+		`{"url": "https://ci.example.com", "secret": "ci-hook-key"}`: "",
+	}
+
+	for body, expected := range cases {
+		var hook Webhook
+		assert.Nil(t, json.Unmarshal([]byte(body), &hook))
+
+		err := hook.Validate()
+		if expected == "" {
+			assert.Nil(t, err, body)
+			continue
+		}
+		assert.EqualValues(t, expected, err.Message(), body)
+	}
+}
+
+func TestWebhookValidateDefaults(t *testing.T) {
+	hook := Webhook{Url: " https://ci.example.com/hooks "}
+
+	assert.Nil(t, hook.Validate())
+	assert.EqualValues(t, Webhook{Url: "https://ci.example.com/hooks", ContentType: "json", Events: []string{"push"}}, hook)
+}
+
+func TestWebhookRedacted(t *testing.T) {
+	hook := Webhook{Url: "https://hooks.slack.com/services/T000/B000/XXXX", Secret: "chat-hook"}
+
+	assert.EqualValues(t, Webhook{Url: "https://hooks.slack.com", Secret: "chat-hook"}, hook.Redacted())
+	assert.EqualValues(t, "https://hooks.slack.com/services/T000/B000/XXXX", hook.Url)
+}
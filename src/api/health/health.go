@@ -0,0 +1,170 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const StatusOk = "ok"
+const StatusFailing = "failing"
+
+type Check interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkFunc struct {
+	name     string
+	function func(ctx context.Context) error
+}
+
+func NewCheck(name string, function func(ctx context.Context) error) Check {
+	return &checkFunc{name: name, function: function}
+}
+
+func (c *checkFunc) Name() string {
+	return c.name
+}
+
+func (c *checkFunc) Check(ctx context.Context) error {
+	return c.function(ctx)
+}
+
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+func (r Report) Ok() bool {
+	return r.Status == StatusOk
+}
+
+type CheckStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// PublicReport is the report served to unauthenticated probes, check errors
+// can carry upstream messages and stay in the logs.
+type PublicReport struct {
+	Status string        `json:"status"`
+	Checks []CheckStatus `json:"checks"`
+}
+
+func (r Report) Public() PublicReport {
+	result := PublicReport{Status: r.Status, Checks: make([]CheckStatus, len(r.Checks))}
+	for i, check := range r.Checks {
+		result.Checks[i] = CheckStatus{Name: check.Name, Status: check.Status}
+	}
+	return result
+}
+
+func Run(ctx context.Context, checks []Check) Report {
+	report := Report{Status: StatusOk, Checks: make([]Result, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check.Check(ctx)
+			result := Result{
+				Name:      check.Name(),
+				Status:    StatusOk,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = StatusFailing
+				result.Error = err.Error()
+			}
+			report.Checks[i] = result
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOk {
+			report.Status = StatusFailing
+		}
+	}
+
+	return report
+}
+
+type timeoutCheck struct {
+	check   Check
+	timeout time.Duration
+}
+
+// WithTimeout bounds the wrapped check, a dependency that hangs is reported
+// as failing instead of holding the probe until the caller gives up.
+func WithTimeout(check Check, timeout time.Duration) Check {
+	return &timeoutCheck{check: check, timeout: timeout}
+}
+
+func (c *timeoutCheck) Name() string {
+	return c.check.Name()
+}
+
+func (c *timeoutCheck) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.check.Check(ctx)
+}
+
+type cachedCheck struct {
+	mutex      sync.Mutex
+	check      Check
+	ttl        time.Duration
+	failureTtl time.Duration
+	checked    time.Time
+	err        error
+	now        func() time.Time
+}
+
+// Cached remembers a successful check for ttl and a failure for failureTtl,
+// failures are kept briefly so probes do not hammer a failing dependency and
+// a recovered one is still reported soon.
+func Cached(check Check, ttl time.Duration, failureTtl time.Duration) Check {
+	return &cachedCheck{check: check, ttl: ttl, failureTtl: failureTtl, now: time.Now}
+}
+
+func (c *cachedCheck) Name() string {
+	return c.check.Name()
+}
+
+// Check runs the wrapped check without holding the lock, a slow dependency
+// does not queue the concurrent probes behind it.
+func (c *cachedCheck) Check(ctx context.Context) error {
+	c.mutex.Lock()
+	now := c.now()
+	ttl := c.ttl
+	if c.err != nil {
+		ttl = c.failureTtl
+	}
+	fresh := !c.checked.IsZero() && now.Sub(c.checked) < ttl
+	cached := c.err
+	c.mutex.Unlock()
+	if fresh {
+		return cached
+	}
+
+	err := c.check.Check(ctx)
+
+	c.mutex.Lock()
+	if now.After(c.checked) {
+		c.checked, c.err = now, err
+	}
+	c.mutex.Unlock()
+	return err
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(), []Check{
+		NewCheck("config", func(ctx context.Context) error { return nil }),
+		NewCheck("github_token", func(ctx context.Context) error { return errors.New("Bad credentials") }),
+	})
+
+	assert.False(t, report.Ok())
+	assert.EqualValues(t, StatusFailing, report.Status)
+	assert.EqualValues(t, 2, len(report.Checks))
+	assert.EqualValues(t, "config", report.Checks[0].Name)
+	assert.EqualValues(t, StatusOk, report.Checks[0].Status)
+	assert.EqualValues(t, "github_token", report.Checks[1].Name)
+	assert.EqualValues(t, StatusFailing, report.Checks[1].Status)
+	assert.EqualValues(t, "Bad credentials", report.Checks[1].Error)
+}
+
+func TestRunWithoutChecks(t *testing.T) {
+	report := Run(context.Background(), nil)
+	assert.True(t, report.Ok())
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	check := Cached(NewCheck("github_token", func(ctx context.Context) error {
+		calls++
+		return nil
+	}), time.Minute, time.Second).(*cachedCheck)
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	check.now = func() time.Time { return now }
+
+	assert.Nil(t, check.Check(context.Background()))
+	assert.Nil(t, check.Check(context.Background()))
+	assert.EqualValues(t, 1, calls)
+
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, check.Check(context.Background()))
+	assert.EqualValues(t, 2, calls)
+	assert.EqualValues(t, "github_token", check.Name())
+}
+
+func TestCachedFailuresBriefly(t *testing.T) {
+	calls := 0
+	failing := true
+	check := Cached(NewCheck("github_token", func(ctx context.Context) error {
+		calls++
+		if failing {
+			return errors.New("bad credentials")
+		}
+		return nil
+	}), time.Minute, 10*time.Second).(*cachedCheck)
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	check.now = func() time.Time { return now }
+
+	assert.NotNil(t, check.Check(context.Background()))
+	failing = false
+	assert.NotNil(t, check.Check(context.Background()))
+	assert.EqualValues(t, 1, calls)
+
+	now = now.Add(11 * time.Second)
+	assert.Nil(t, check.Check(context.Background()))
+	assert.Nil(t, check.Check(context.Background()))
+	assert.EqualValues(t, 2, calls)
+}
+
+func TestWithTimeout(t *testing.T) {
+	check := WithTimeout(NewCheck("audit_sink", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}), time.Millisecond)
+
+	assert.EqualValues(t, "audit_sink", check.Name())
+	assert.EqualValues(t, context.DeadlineExceeded, check.Check(context.Background()))
+}
+
+func TestReportPublicDropsErrors(t *testing.T) {
+	report := Run(context.Background(), []Check{
+		NewCheck("github_token", func(ctx context.Context) error { return errors.New("Bad credentials") }),
+	})
+
+	public := report.Public()
+	assert.EqualValues(t, StatusFailing, public.Status)
+	assert.EqualValues(t, []CheckStatus{{Name: "github_token", Status: StatusFailing}}, public.Checks)
+}
+
+func TestCachedDoesNotHoldLockDuringCheck(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	check := Cached(NewCheck("github_token", func(ctx context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}), time.Minute, time.Second)
+
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- check.Check(context.Background()) }()
+	}
+	<-started
+	<-started
+	close(release)
+
+	assert.Nil(t, <-done)
+	assert.Nil(t, <-done)
+}
@@ -0,0 +1,61 @@
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang-microservices/src/api/domain/repositories"
+	"io/ioutil"
+	"sort"
+)
+
+// Store holds the named label sets teams apply to their repositories.
+type Store struct {
+	sets map[string][]repositories.Label
+}
+
+func NewStore(sets map[string][]repositories.Label) *Store {
+	store := &Store{sets: make(map[string][]repositories.Label, len(sets))}
+	for name, set := range sets {
+		store.sets[name] = append(make([]repositories.Label, 0, len(set)), set...)
+	}
+	return store
+}
+
+// Load reads a json object of set names to label lists, an empty path gives
+// an empty store. Every label is validated up front.
+func Load(path string) (*Store, error) {
+	if path == "" {
+		return NewStore(nil), nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sets := make(map[string][]repositories.Label)
+	if err := json.Unmarshal(content, &sets); err != nil {
+		return nil, fmt.Errorf("invalid label sets file %s: %w", path, err)
+	}
+	for name, set := range sets {
+		for i := range set {
+			if err := set[i].Validate(); err != nil {
+				return nil, fmt.Errorf("invalid label set %s: %s", name, err.Message())
+			}
+		}
+	}
+	return NewStore(sets), nil
+}
+
+func (s *Store) Get(name string) ([]repositories.Label, bool) {
+	set, ok := s.sets[name]
+	return set, ok
+}
+
+func (s *Store) Names() []string {
+	result := make([]string, 0, len(s.sets))
+	for name := range s.sets {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,42 @@
+package labels
+
+import (
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/repositories"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{
+		"platform": [{"name": "bug", "color": "#D73A4A"}, {"name": "incident", "color": "b60205", "description": "Production incident"}],
+		"docs": []
+	}`), 0600))
+
+	store, err := Load(path)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"docs", "platform"}, store.Names())
+
+	set, ok := store.Get("platform")
+	assert.True(t, ok)
+	assert.EqualValues(t, []repositories.Label{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "incident", Color: "b60205", Description: "Production incident"},
+	}, set)
+}
+
+func TestLoadErrors(t *testing.T) {
+	store, err := Load("")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{}, store.Names())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+
+	path := filepath.Join(t.TempDir(), "labels.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"platform": [{"name": "bug", "color": "red"}]}`), 0600))
+	_, err = Load(path)
+	assert.EqualValues(t, "invalid label set platform: invalid color red of label bug, expected 6 hex digits", err.Error())
+}
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+type CounterVec struct {
+	*vec
+}
+
+func (r *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	result := &CounterVec{newVec(name, help, "counter", labels)}
+	r.register(result)
+	return result
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.get(labelValues).value += value
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.writeHeader(w)
+	for _, current := range c.sortedSeries() {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, current.labelValues), formatValue(current.value))
+	}
+}
+
+type GaugeVec struct {
+	*vec
+}
+
+func (r *Registry) NewGaugeVec(name string, help string, labels ...string) *GaugeVec {
+	result := &GaugeVec{newVec(name, help, "gauge", labels)}
+	r.register(result)
+	return result
+}
+
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.get(labelValues).value = value
+}
+
+func (g *GaugeVec) Add(value float64, labelValues ...string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.get(labelValues).value += value
+}
+
+func (g *GaugeVec) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+func (g *GaugeVec) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+func (g *GaugeVec) write(w io.Writer) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.writeHeader(w)
+	for _, current := range g.sortedSeries() {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, formatLabels(g.labels, current.labelValues), formatValue(current.value))
+	}
+}
+
+type gaugeFunc struct {
+	name     string
+	help     string
+	function func() float64
+}
+
+func (r *Registry) NewGaugeFunc(name string, help string, function func() float64) {
+	r.register(&gaugeFunc{name: name, help: help, function: function})
+}
+
+func (g *gaugeFunc) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", g.name, g.help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", g.name)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.function()))
+}
+
+type HistogramVec struct {
+	*vec
+	bounds []float64
+}
+
+func (r *Registry) NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+	result := &HistogramVec{vec: newVec(name, help, "histogram", labels), bounds: bounds}
+	r.register(result)
+	return result
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	current := h.get(labelValues)
+	if current.buckets == nil {
+		current.buckets = make([]uint64, len(h.bounds))
+	}
+	for i, bound := range h.bounds {
+		if value <= bound {
+			current.buckets[i]++
+		}
+	}
+	current.sum += value
+	current.count++
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.writeHeader(w)
+	for _, current := range h.sortedSeries() {
+		for i, bound := range h.bounds {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name,
+				formatLabels(h.labels, current.labelValues, "le", formatValue(bound)), current.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name,
+			formatLabels(h.labels, current.labelValues, "le", "+Inf"), current.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, current.labelValues), formatValue(current.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, current.labelValues), current.count)
+	}
+}
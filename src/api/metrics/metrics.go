@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"net/http"
+	"runtime"
+)
+
+var registry = NewRegistry()
+
+var HttpRequests = registry.NewCounterVec(
+	"http_requests_total", "Number of HTTP requests handled by route and status.",
+	"method", "route", "status",
+)
+
+var HttpRequestDuration = registry.NewHistogramVec(
+	"http_request_duration_seconds", "Latency of HTTP requests handled by route.",
+	DefaultBuckets, "method", "route",
+)
+
+var OutboundRequests = registry.NewCounterVec(
+	"outbound_requests_total", "Number of outbound requests by endpoint and status.",
+	"endpoint", "status",
+)
+
+var OutboundRequestDuration = registry.NewHistogramVec(
+	"outbound_request_duration_seconds", "Latency of outbound requests by endpoint.",
+	DefaultBuckets, "endpoint",
+)
+
+var RepositoryCreations = registry.NewCounterVec(
+	"repository_creations_total", "Number of repository creation attempts by status.",
+	"status",
+)
+
+var RepositoryDeletions = registry.NewCounterVec(
+	"repository_deletions_total", "Number of repository deletion and archival attempts by action and status.",
+	"action", "status",
+)
+
+var BatchSize = registry.NewHistogramVec(
+	"batch_size", "Number of repositories requested per batch.",
+	[]float64{1, 2, 5, 10, 20, 50, 100},
+)
+
+var BatchItems = registry.NewCounterVec(
+	"batch_items_total", "Outcome of every item of a repository batch.",
+	"outcome", "status",
+)
+
+var InFlightCreations = registry.NewGaugeVec(
+	"repository_creations_in_flight", "Repository creation goroutines currently running.",
+)
+
+var GithubRateLimitRemaining = registry.NewGaugeVec(
+	"github_rate_limit_remaining", "Last X-RateLimit-Remaining value returned by GitHub.",
+)
+
+func init() {
+	registry.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+}
+
+func Handler() http.Handler {
+	return registry.Handler()
+}
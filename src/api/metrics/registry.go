@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(w io.Writer)
+}
+
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mutex.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mutex.Unlock()
+
+	var buffer bytes.Buffer
+	for _, c := range collectors {
+		c.write(&buffer)
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_ = r.WriteText(w)
+	})
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	buckets     []uint64
+	sum         float64
+	count       uint64
+}
+
+type vec struct {
+	mutex      sync.Mutex
+	name       string
+	help       string
+	metricType string
+	labels     []string
+	series     map[string]*series
+}
+
+func newVec(name string, help string, metricType string, labels []string) *vec {
+	return &vec{name: name, help: help, metricType: metricType, labels: labels, series: make(map[string]*series)}
+}
+
+func (v *vec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	current := v.series[key]
+	if current == nil {
+		current = &series{labelValues: append([]string(nil), labelValues...)}
+		v.series[key] = current
+	}
+	return current
+}
+
+func (v *vec) sortedSeries() []*series {
+	result := make([]*series, 0, len(v.series))
+	for _, current := range v.series {
+		result = append(result, current)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Join(result[i].labelValues, "\xff") < strings.Join(result[j].labelValues, "\xff")
+	})
+	return result
+}
+
+func (v *vec) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.metricType)
+}
+
+func formatLabels(names []string, values []string, extra ...string) string {
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		pairs = append(pairs, name+`="`+escapeLabelValue(values[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabelValue(extra[i+1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return strings.ReplaceAll(value, `"`, `\"`)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounterVec("requests_total", "Requests.", "route", "status")
+	inFlight := registry.NewGaugeVec("in_flight", "In flight.")
+	latency := registry.NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	registry.NewGaugeFunc("answer", "Answer.", func() float64 { return 42 })
+
+	requests.Inc("/repository", "201")
+	requests.Add(2, "/repositories", "206")
+	requests.Inc("/repository", "201")
+	inFlight.Inc()
+	inFlight.Inc()
+	inFlight.Dec()
+	latency.Observe(0.05, "/repository")
+	latency.Observe(0.5, "/repository")
+
+	var buffer bytes.Buffer
+	assert.Nil(t, registry.WriteText(&buffer))
+
+	assert.EqualValues(t, `# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{route="/repositories",status="206"} 2
+requests_total{route="/repository",status="201"} 2
+# HELP in_flight In flight.
+# TYPE in_flight gauge
+in_flight 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/repository",le="0.1"} 1
+latency_seconds_bucket{route="/repository",le="1"} 2
+latency_seconds_bucket{route="/repository",le="+Inf"} 2
+latency_seconds_sum{route="/repository"} 0.55
+latency_seconds_count{route="/repository"} 2
+# HELP answer Answer.
+# TYPE answer gauge
+answer 42
+`, buffer.String())
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.EqualValues(t, `a\"b\\c\nd`, escapeLabelValue("a\"b\\c\nd"))
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	request, _ := http.NewRequestWithContext(
+		WithEndpoint(context.Background(), "POST /user/repos"),
+		http.MethodPost, server.URL, nil,
+	)
+	response, err := client.Do(request)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+
+	var buffer bytes.Buffer
+	assert.Nil(t, registry.WriteText(&buffer))
+	assert.Contains(t, buffer.String(), `outbound_requests_total{endpoint="POST /user/repos",status="201"} 1`)
+	assert.Contains(t, buffer.String(), `outbound_request_duration_seconds_count{endpoint="POST /user/repos"} 1`)
+	assert.Contains(t, buffer.String(), "github_rate_limit_remaining 4999")
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const headerRateLimitRemaining = "X-RateLimit-Remaining"
+
+type endpointKey struct{}
+
+func WithEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+func endpointOf(request *http.Request) string {
+	if endpoint, ok := request.Context().Value(endpointKey{}).(string); ok {
+		return endpoint
+	}
+	return request.Method + " " + request.URL.Host
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	endpoint := endpointOf(request)
+	start := time.Now()
+
+	response, err := t.base.RoundTrip(request)
+
+	OutboundRequestDuration.Observe(time.Since(start).Seconds(), endpoint)
+	if err != nil {
+		OutboundRequests.Inc(endpoint, "error")
+		return response, err
+	}
+
+	OutboundRequests.Inc(endpoint, strconv.Itoa(response.StatusCode))
+	if remaining, parseErr := strconv.ParseFloat(response.Header.Get(headerRateLimitRemaining), 64); parseErr == nil {
+		GithubRateLimitRemaining.Set(remaining)
+	}
+
+	return response, nil
+}
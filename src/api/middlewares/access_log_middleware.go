@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/utils/logger"
+	"time"
+)
+
+func AccessLog() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		fields := []logger.Field{
+			logger.Any("method", ctx.Request.Method),
+			logger.Any("path", ctx.Request.URL.Path),
+			logger.Any("route", ctx.FullPath()),
+			logger.Any("status", ctx.Writer.Status()),
+			logger.Any("latency_ms", time.Since(start).Milliseconds()),
+			logger.Any("client_ip", ctx.ClientIP()),
+		}
+		if caller := GetCaller(ctx); caller != nil {
+			fields = append(fields, logger.Any("caller", caller.Id))
+		}
+		if len(ctx.Errors) > 0 {
+			fields = append(fields, logger.Any("errors", ctx.Errors.String()))
+		}
+
+		log := logger.FromContext(ctx.Request.Context())
+		switch {
+		case ctx.Writer.Status() >= 500:
+			log.Error("request completed", fields...)
+		case ctx.Writer.Status() >= 400:
+			log.Warn("request completed", fields...)
+		default:
+			log.Info("request completed", fields...)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/utils/errors"
+)
+
+const callerKey = "caller"
+
+func Authenticate(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		caller, err := authenticator.Authenticate(ctx.Request)
+		if err != nil {
+			ctx.Header("WWW-Authenticate", `Bearer realm="api"`)
+			ctx.AbortWithStatusJSON(err.Status(), err)
+			return
+		}
+
+		ctx.Set(callerKey, caller)
+		ctx.Request = ctx.Request.WithContext(auth.WithCaller(ctx.Request.Context(), caller))
+		ctx.Next()
+	}
+}
+
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		caller := GetCaller(ctx)
+		if caller == nil || !caller.HasScope(scope) {
+			apiErr := errors.NewForbiddenApiError("missing required scope " + scope)
+			ctx.AbortWithStatusJSON(apiErr.Status(), apiErr)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func GetCaller(ctx *gin.Context) *auth.Caller {
+	value, exists := ctx.Get(callerKey)
+	if !exists {
+		return nil
+	}
+	caller, _ := value.(*auth.Caller)
+	return caller
+}
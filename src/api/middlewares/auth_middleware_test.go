@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/utils/errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func newAuthRouter(t *testing.T) *gin.Engine {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`[
+{"id": "team-a", "key": "secret-a", "scopes": ["repos:create"]}
+]`), 0600))
+	authenticator, err := auth.NewAuthenticator(auth.Options{ApiKeysFile: path})
+	assert.Nil(t, err)
+
+	router := gin.New()
+	router.Use(Authenticate(authenticator))
+	router.POST("/repository", RequireScope(auth.ScopeReposCreate), func(ctx *gin.Context) {
+		ctx.String(http.StatusCreated, auth.CallerFromContext(ctx.Request.Context()).Id)
+	})
+	router.POST("/repositories", RequireScope(auth.ScopeReposBatch), func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+	return router
+}
+
+func TestAuthenticateUnauthorized(t *testing.T) {
+	response := httptest.NewRecorder()
+	newAuthRouter(t).ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/repository", nil))
+
+	apiErr, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusUnauthorized, response.Code)
+	assert.EqualValues(t, "missing credentials", apiErr.Message())
+	assert.NotEmpty(t, response.Header().Get("WWW-Authenticate"))
+}
+
+func TestAuthenticateOk(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/repository", nil)
+	request.Header.Set("X-Api-Key", "secret-a")
+	response := httptest.NewRecorder()
+	newAuthRouter(t).ServeHTTP(response, request)
+
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	assert.EqualValues(t, "team-a", response.Body.String())
+}
+
+func TestRequireScopeForbidden(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/repositories", nil)
+	request.Header.Set("X-Api-Key", "secret-a")
+	response := httptest.NewRecorder()
+	newAuthRouter(t).ServeHTTP(response, request)
+
+	apiErr, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusForbidden, response.Code)
+	assert.EqualValues(t, "missing required scope repos:batch", apiErr.Message())
+}
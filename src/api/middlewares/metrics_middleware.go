@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/metrics"
+	"strconv"
+	"time"
+)
+
+const unmatchedRoute = "unmatched"
+
+func Metrics() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.HttpRequests.Inc(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status()))
+		metrics.HttpRequestDuration.Observe(time.Since(start).Seconds(), ctx.Request.Method, route)
+	}
+}
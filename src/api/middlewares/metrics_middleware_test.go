@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/marco", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "polo")
+	})
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/marco", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, response.Body.String(), `http_requests_total{method="GET",route="/marco",status="200"} 1`)
+	assert.Contains(t, response.Body.String(), `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, response.Body.String(), `http_request_duration_seconds_count{method="GET",route="/marco"} 1`)
+	assert.Contains(t, response.Body.String(), "go_goroutines ")
+}
@@ -0,0 +1,96 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/domain/headers"
+	"golang-microservices/src/api/ratelimit"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const headerRateLimitLimit = "X-RateLimit-Limit"
+const headerRateLimitRemaining = "X-RateLimit-Remaining"
+const headerRateLimitReset = "X-RateLimit-Reset"
+const headerQuotaLimit = "X-RateLimit-Quota-Limit"
+const headerQuotaRemaining = "X-RateLimit-Quota-Remaining"
+const headerQuotaReset = "X-RateLimit-Quota-Reset"
+
+func ipKey(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+func rateLimitKey(ctx *gin.Context) string {
+	if caller := GetCaller(ctx); caller != nil {
+		return "caller:" + caller.Id
+	}
+	return ipKey(ctx)
+}
+
+// RateLimit limits requests per authenticated caller, requests without a
+// caller share the bucket of their ip.
+func RateLimit(store ratelimit.Store, limit ratelimit.Limit) gin.HandlerFunc {
+	return rateLimit(store, limit, rateLimitKey)
+}
+
+// RateLimitByIp limits requests per client ip whoever the caller is, mounted
+// before Authenticate it also throttles requests with invalid credentials.
+func RateLimitByIp(store ratelimit.Store, limit ratelimit.Limit) gin.HandlerFunc {
+	return rateLimit(store, limit, ipKey)
+}
+
+func rateLimit(store ratelimit.Store, limit ratelimit.Limit, key func(ctx *gin.Context) string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		now := time.Now()
+		result, err := store.Take(key(ctx), limit, now)
+		if err != nil {
+			logger.FromContext(ctx.Request.Context()).Error("error when checking rate limit, letting the request through", logger.Err(err))
+			ctx.Next()
+			return
+		}
+
+		ctx.Header(headerRateLimitLimit, strconv.Itoa(result.Limit))
+		ctx.Header(headerRateLimitRemaining, strconv.Itoa(result.Remaining))
+		ctx.Header(headerRateLimitReset, strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+		if !result.Allowed {
+			retryAfter := int(result.ResetAt.Sub(now).Seconds()) + 1
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			ctx.Header(headers.RetryAfter, strconv.Itoa(retryAfter))
+			apiErr := errors.NewTooManyRequestsApiError("rate limit exceeded")
+			ctx.AbortWithStatusJSON(apiErr.Status(), apiErr)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// QuotaHeaders reports the daily creation quota the service consumes for the
+// request. Batches consume it from several goroutines, the lowest remaining
+// count is the one reported.
+func QuotaHeaders() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var mutex sync.Mutex
+		reported := false
+		lowest := 0
+		reporter := func(result ratelimit.Result) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if reported && result.Remaining >= lowest {
+				return
+			}
+			reported, lowest = true, result.Remaining
+			ctx.Header(headerQuotaLimit, strconv.Itoa(result.Limit))
+			ctx.Header(headerQuotaRemaining, strconv.Itoa(result.Remaining))
+			ctx.Header(headerQuotaReset, strconv.FormatInt(result.ResetAt.Unix(), 10))
+		}
+		ctx.Request = ctx.Request.WithContext(ratelimit.WithQuotaReporter(ctx.Request.Context(), reporter))
+
+		ctx.Next()
+	}
+}
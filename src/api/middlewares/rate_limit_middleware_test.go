@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/ratelimit"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	router := gin.New()
+	router.Use(RateLimit(ratelimit.NewMemoryStore(), ratelimit.Limit{Rate: 0.001, Burst: 1}))
+	router.GET("/marco", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "polo")
+	})
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/marco", nil))
+
+	assert.EqualValues(t, http.StatusOK, first.Code)
+	assert.EqualValues(t, "1", first.Header().Get("X-RateLimit-Limit"))
+	assert.EqualValues(t, "0", first.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, first.Header().Get("X-RateLimit-Reset"))
+
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/marco", nil))
+
+	apiErr, _ := errors.NewApiErrorFromBytes(second.Body.Bytes())
+	assert.EqualValues(t, http.StatusTooManyRequests, second.Code)
+	assert.EqualValues(t, "rate limit exceeded", apiErr.Message())
+	assert.NotEmpty(t, second.Header().Get("Retry-After"))
+}
+
+func TestRateLimitByIpIgnoresCaller(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(callerKey, &auth.Caller{Id: ctx.GetHeader("X-Caller")})
+	}, RateLimitByIp(store, ratelimit.Limit{Rate: 0.001, Burst: 1}))
+	router.GET("/marco", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "polo")
+	})
+
+	first := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/marco", nil)
+	request.Header.Set("X-Caller", "team-a")
+	router.ServeHTTP(first, request)
+
+	second := httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/marco", nil)
+	request.Header.Set("X-Caller", "team-b")
+	router.ServeHTTP(second, request)
+
+	assert.EqualValues(t, http.StatusOK, first.Code)
+	assert.EqualValues(t, http.StatusTooManyRequests, second.Code)
+	assert.NotEmpty(t, second.Header().Get("Retry-After"))
+}
+
+func TestQuotaHeadersReportLowestRemaining(t *testing.T) {
+	resetAt := time.Unix(1700000000, 0)
+	router := gin.New()
+	router.POST("/repositories", QuotaHeaders(), func(ctx *gin.Context) {
+		ratelimit.ReportQuota(ctx.Request.Context(), ratelimit.Result{Allowed: true, Limit: 5, Remaining: 2, ResetAt: resetAt})
+		ratelimit.ReportQuota(ctx.Request.Context(), ratelimit.Result{Allowed: true, Limit: 5, Remaining: 4, ResetAt: resetAt})
+		ctx.Status(http.StatusCreated)
+	})
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/repositories", nil))
+
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	assert.EqualValues(t, "5", response.Header().Get("X-RateLimit-Quota-Limit"))
+	assert.EqualValues(t, "2", response.Header().Get("X-RateLimit-Quota-Remaining"))
+	assert.EqualValues(t, "1700000000", response.Header().Get("X-RateLimit-Quota-Reset"))
+}
+
+func TestQuotaHeadersWithoutQuota(t *testing.T) {
+	router := gin.New()
+	router.POST("/repositories", QuotaHeaders(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/repositories", nil))
+
+	assert.Empty(t, response.Header().Get("X-RateLimit-Quota-Remaining"))
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/utils/logger"
+	"golang-microservices/src/api/utils/requestid"
+)
+
+func RequestId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+
+		ctx.Header(requestid.Header, id)
+
+		requestCtx := requestid.WithRequestId(ctx.Request.Context(), id)
+		requestCtx = logger.WithContext(requestCtx, logger.Default().With(logger.Any("request_id", id)))
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+
+		ctx.Next()
+	}
+}
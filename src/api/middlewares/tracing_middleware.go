@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/tracing"
+	"golang-microservices/src/api/utils/requestid"
+	"strconv"
+)
+
+func Tracing() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		requestCtx := tracing.Extract(ctx.Request.Context(), ctx.Request.Header)
+		requestCtx, span := tracing.Start(requestCtx, ctx.Request.Method+" "+route)
+		defer span.End()
+
+		span.SetAttribute("http.method", ctx.Request.Method)
+		span.SetAttribute("http.route", route)
+		if id := requestid.FromContext(requestCtx); id != "" {
+			span.SetAttribute("request_id", id)
+		}
+
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+		ctx.Header(tracing.HeaderTraceparent, span.Context().Traceparent())
+
+		ctx.Next()
+
+		span.SetAttribute("http.status_code", strconv.Itoa(ctx.Writer.Status()))
+		if caller := GetCaller(ctx); caller != nil {
+			span.SetAttribute("caller", caller.Id)
+		}
+		if len(ctx.Errors) > 0 {
+			span.SetError(ctx.Errors.String())
+		} else if ctx.Writer.Status() >= 500 {
+			span.SetError(strconv.Itoa(ctx.Writer.Status()))
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/tracing"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	var buffer bytes.Buffer
+	tracing.Default().SetExporter(tracing.NewStdoutExporter(&buffer))
+	defer tracing.Default().SetExporter(nil)
+
+	router := gin.New()
+	router.Use(Tracing())
+	router.GET("/marco", func(ctx *gin.Context) {
+		_, span := tracing.Start(ctx.Request.Context(), "child")
+		span.End()
+		ctx.String(http.StatusOK, "polo")
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/marco", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.EqualValues(t, 2, len(lines))
+
+	var child, handler tracing.SpanData
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &child))
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &handler))
+
+	assert.EqualValues(t, "GET /marco", handler.Name)
+	assert.EqualValues(t, "4bf92f3577b34da6a3ce929d0e0e4736", handler.TraceId)
+	assert.EqualValues(t, "00f067aa0ba902b7", handler.ParentSpanId)
+	assert.EqualValues(t, "200", handler.Attributes["http.status_code"])
+	assert.EqualValues(t, handler.SpanId, child.ParentSpanId)
+	assert.EqualValues(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+handler.SpanId+"-01", response.Header().Get("traceparent"))
+}
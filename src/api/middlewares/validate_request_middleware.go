@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"golang-microservices/src/api/openapi"
+	"golang-microservices/src/api/utils/errors"
+	"io"
+	"net/http"
+)
+
+// maxBodySize bounds the request bodies read for validation.
+const maxBodySize = 1 << 20
+
+// ValidateRequest rejects requests whose query parameters or json body do
+// not match the operation of the matched route in the OpenAPI document. The
+// body is put back for the handler. It goes on each route after RequireScope
+// so callers without the scope get 403 rather than schema details.
+func ValidateRequest(validator *openapi.Validator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body []byte
+		if ctx.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBodySize)); err != nil {
+				apiErr := errors.NewBadRequestApiError("invalid request body")
+				ctx.AbortWithStatusJSON(apiErr.Status(), apiErr)
+				return
+			}
+			ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		apiErr := validator.Validate(ctx.Request.Method, openapi.PathTemplate(ctx.FullPath()),
+			ctx.Request.URL.Query(), ctx.GetHeader("Content-Type"), body)
+		if apiErr != nil {
+			ctx.AbortWithStatusJSON(apiErr.Status(), apiErr)
+			return
+		}
+
+		ctx.Next()
+	}
+}
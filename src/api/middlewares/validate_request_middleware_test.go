@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/openapi"
+	"golang-microservices/src/api/utils/errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newValidatedRouter(t *testing.T) *gin.Engine {
+	document, err := openapi.Load()
+	assert.Nil(t, err)
+
+	router := gin.New()
+	router.Use(ValidateRequest(openapi.NewValidator(document)))
+	router.POST("/repository", func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.String(http.StatusCreated, string(body))
+	})
+	router.GET("/repositories", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "listed")
+	})
+	return router
+}
+
+func TestValidateRequest(t *testing.T) {
+	router := newValidatedRouter(t)
+
+	valid := httptest.NewRecorder()
+	router.ServeHTTP(valid, httptest.NewRequest(http.MethodPost, "/repository", strings.NewReader(`{"name": "repo"}`)))
+	assert.EqualValues(t, http.StatusCreated, valid.Code)
+	assert.EqualValues(t, `{"name": "repo"}`, valid.Body.String())
+
+	unknown := httptest.NewRecorder()
+	router.ServeHTTP(unknown, httptest.NewRequest(http.MethodPost, "/repository", strings.NewReader(`{"name": "repo", "privat": true}`)))
+	assert.EqualValues(t, http.StatusCreated, unknown.Code)
+
+	invalid := httptest.NewRecorder()
+	router.ServeHTTP(invalid, httptest.NewRequest(http.MethodPost, "/repository", strings.NewReader(`{"name": 12}`)))
+	apiErr, _ := errors.NewApiErrorFromBytes(invalid.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, invalid.Code)
+	assert.EqualValues(t, "invalid request body: name: expected string", apiErr.Message())
+}
+
+func TestValidateRequestBodyTooLarge(t *testing.T) {
+	router := newValidatedRouter(t)
+
+	response := httptest.NewRecorder()
+	body := `{"name": "repo", "description": "` + strings.Repeat("x", maxBodySize) + `"}`
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/repository", strings.NewReader(body)))
+	apiErr, _ := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "invalid request body", apiErr.Message())
+}
+
+func TestValidateRequestQuery(t *testing.T) {
+	router := newValidatedRouter(t)
+
+	valid := httptest.NewRecorder()
+	router.ServeHTTP(valid, httptest.NewRequest(http.MethodGet, "/repositories?page=2", nil))
+	assert.EqualValues(t, http.StatusOK, valid.Code)
+
+	invalid := httptest.NewRecorder()
+	router.ServeHTTP(invalid, httptest.NewRequest(http.MethodGet, "/repositories?per_page=500", nil))
+	apiErr, _ := errors.NewApiErrorFromBytes(invalid.Body.Bytes())
+	assert.EqualValues(t, http.StatusBadRequest, invalid.Code)
+	assert.EqualValues(t, "invalid query parameter per_page: expected at most 100", apiErr.Message())
+}
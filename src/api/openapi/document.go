@@ -0,0 +1,157 @@
+// Package openapi describes the service API as an OpenAPI 3 document. The
+// document is generated from the route table and the domain types, committed
+// as openapi.json and embedded, requests are validated against it.
+package openapi
+
+import (
+	"encoding/json"
+)
+
+const Version = "3.0.3"
+
+const TypeObject = "object"
+const TypeArray = "array"
+const TypeString = "string"
+const TypeInteger = "integer"
+const TypeNumber = "number"
+const TypeBoolean = "boolean"
+
+const MediaTypeJson = "application/json"
+const MediaTypeYaml = "application/yaml"
+const MediaTypeText = "text/plain"
+const MediaTypeHtml = "text/html"
+
+type Document struct {
+	OpenApi    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// PathItem maps lower case http methods to their operation.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Scope       string                `json:"x-required-scope,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string                `json:"$ref,omitempty"`
+	Type                 string                `json:"type,omitempty"`
+	Format               string                `json:"format,omitempty"`
+	Description          string                `json:"description,omitempty"`
+	Enum                 []string              `json:"enum,omitempty"`
+	Minimum              *float64              `json:"minimum,omitempty"`
+	Maximum              *float64              `json:"maximum,omitempty"`
+	Nullable             bool                  `json:"nullable,omitempty"`
+	MinItems             int                   `json:"minItems,omitempty"`
+	Items                *Schema               `json:"items,omitempty"`
+	Properties           map[string]*Schema    `json:"properties,omitempty"`
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties,omitempty"`
+}
+
+// AdditionalProperties is either false, rejecting unknown properties, or the
+// schema of the values of a map.
+type AdditionalProperties struct {
+	Schema *Schema
+}
+
+func (a AdditionalProperties) MarshalJSON() ([]byte, error) {
+	if a.Schema == nil {
+		return []byte("false"), nil
+	}
+	return json.Marshal(a.Schema)
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		if allowed {
+			a.Schema = &Schema{}
+		}
+		return nil
+	}
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// Parse reads a document, the embedded one is read through Load.
+func Parse(content []byte) (*Document, error) {
+	var document Document
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// Operation returns the operation of a method on a path template, nil when
+// the document does not describe it.
+func (d *Document) Operation(method string, path string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil
+	}
+	return item[lowerMethod(method)]
+}
+
+// Resolve follows a reference to the components of the document.
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[schemaName(schema.Ref)]
+	}
+	return schema
+}
@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/repositories"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite openapi.json from the route table and the domain types")
+
+func generate(t *testing.T) []byte {
+	content, err := json.MarshalIndent(Build(), "", "  ")
+	assert.Nil(t, err)
+	return append(content, '\n')
+}
+
+// TestSpecUpToDate fails when a route or a field of a request or response
+// type changed without openapi.json, run go test ./openapi -update and
+// review the diff.
+func TestSpecUpToDate(t *testing.T) {
+	generated := generate(t)
+	if *update {
+		assert.Nil(t, ioutil.WriteFile("openapi.json", generated, 0644))
+		return
+	}
+	assert.Equal(t, string(generated), string(Spec()), "openapi.json is out of date, run go test ./openapi -update")
+}
+
+func TestLoad(t *testing.T) {
+	document, err := Load()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, Version, document.OpenApi)
+	assert.NotNil(t, document.Operation(http.MethodPost, "/repository"))
+	assert.NotNil(t, document.Operation(http.MethodDelete, "/repository/{owner}/{name}"))
+	assert.Nil(t, document.Operation(http.MethodPut, "/repository"))
+	assert.Nil(t, document.Operation(http.MethodGet, "/missing"))
+}
+
+func TestPathTemplate(t *testing.T) {
+	assert.EqualValues(t, "/repository/{owner}/{name}/sync", PathTemplate("/repository/:owner/:name/sync"))
+	assert.EqualValues(t, "/files/{path}", PathTemplate("/files/*path"))
+	assert.EqualValues(t, "/repositories", PathTemplate("/repositories"))
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	components := make(schemas)
+
+	ref := components.of(repositories.CreateReposResponse{})
+
+	assert.EqualValues(t, "#/components/schemas/CreateReposResponse", ref.Ref)
+	batch := components["CreateReposResponse"]
+	assert.EqualValues(t, TypeObject, batch.Type)
+	assert.EqualValues(t, TypeInteger, batch.Properties["status"].Type)
+	assert.EqualValues(t, TypeArray, batch.Properties["results"].Type)
+	assert.EqualValues(t, "#/components/schemas/CreateRepositoresResult", batch.Properties["results"].Items.Ref)
+
+	result := components["CreateRepositoresResult"]
+	assert.EqualValues(t, "#/components/schemas/ApiError", result.Properties["error"].Ref)
+	assert.EqualValues(t, "#/components/schemas/CreateRepoResponse", result.Properties["response"].Ref)
+
+	apiError := components["ApiError"]
+	assert.EqualValues(t, 3, len(apiError.Properties))
+	assert.EqualValues(t, TypeInteger, apiError.Properties["status"].Type)
+	assert.EqualValues(t, TypeString, apiError.Properties["message"].Type)
+	assert.EqualValues(t, TypeString, apiError.Properties["error"].Type)
+}
+
+func TestSchemaFlattensEmbeddedStructs(t *testing.T) {
+	components := make(schemas)
+
+	components.of(repositories.ProtectionPolicy{})
+
+	policy := components["ProtectionPolicy"]
+	assert.EqualValues(t, TypeString, policy.Properties["name"].Type)
+	assert.EqualValues(t, TypeInteger, policy.Properties["required_reviews"].Type)
+	assert.EqualValues(t, TypeArray, policy.Properties["required_status_checks"].Type)
+	_, ok := components["BranchProtection"]
+	assert.False(t, ok)
+}
+
+func TestSchemaPointersAreNullable(t *testing.T) {
+	components := make(schemas)
+
+	components.of(repositories.UpdateRepoRequest{})
+
+	update := components["UpdateRepoRequest"]
+	assert.EqualValues(t, TypeString, update.Properties["description"].Type)
+	assert.True(t, update.Properties["description"].Nullable)
+	assert.EqualValues(t, TypeBoolean, update.Properties["private"].Type)
+}
+
+func TestAdditionalPropertiesJson(t *testing.T) {
+	var schema Schema
+	assert.Nil(t, json.Unmarshal([]byte(`{"type": "object", "additionalProperties": false}`), &schema))
+	assert.NotNil(t, schema.AdditionalProperties)
+	assert.Nil(t, schema.AdditionalProperties.Schema)
+
+	assert.Nil(t, json.Unmarshal([]byte(`{"type": "object", "additionalProperties": {"type": "string"}}`), &schema))
+	assert.EqualValues(t, TypeString, schema.AdditionalProperties.Schema.Type)
+
+	content, err := json.Marshal(Schema{Type: TypeObject, AdditionalProperties: &AdditionalProperties{}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, `{"type":"object","additionalProperties":false}`, string(content))
+}
@@ -0,0 +1,312 @@
+package openapi
+
+import (
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/domain/headers"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/health"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const securityApiKey = "apiKey"
+const securityBearer = "bearerAuth"
+
+const tagRepositories = "repositories"
+const tagAudit = "audit"
+const tagService = "service"
+
+// route describes one entry of app.mapUrls. Body and response are zero
+// values of the go types, only their type is used.
+type route struct {
+	method      string
+	path        string
+	operationId string
+	summary     string
+	description string
+	tag         string
+	scope       string
+	parameters  []Parameter
+	body        interface{}
+	bodyTypes   []string
+	status      int
+	response    interface{}
+	mediaType   string
+	headers     map[string]Header
+	batch       bool
+}
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func pathParameter(name string, description string) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &Schema{Type: TypeString}}
+}
+
+func queryParameter(name string, schema *Schema, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+var ownerParameter = pathParameter("owner", "owner of the repository")
+var nameParameter = pathParameter("name", "name of the repository")
+var providerParameter = queryParameter("provider", &Schema{Type: TypeString},
+	"repository provider, defaults to the provider of the owner")
+var archiveParameter = queryParameter("archive", &Schema{Type: TypeBoolean},
+	"archive instead of deleting")
+var confirmParameter = Parameter{Name: headers.ConfirmDelete, In: "header", Schema: &Schema{Type: TypeString},
+	Description: "confirmation token, required when the service is configured to ask for one"}
+
+func repositoryParameters(extra ...Parameter) []Parameter {
+	return append([]Parameter{ownerParameter, nameParameter, providerParameter}, extra...)
+}
+
+// routes lists every route of app.mapUrls, a test compares both.
+var routes = []route{
+	{
+		method: http.MethodGet, path: "/marco", operationId: "marco", tag: tagService,
+		summary: "Answers polo", status: http.StatusOK, response: "", mediaType: MediaTypeText,
+	},
+	{
+		method: http.MethodGet, path: "/metrics", operationId: "metrics", tag: tagService,
+		summary: "Prometheus metrics", status: http.StatusOK, response: "", mediaType: MediaTypeText,
+	},
+	{
+		method: http.MethodGet, path: "/healthz", operationId: "healthz", tag: tagService,
+		summary: "Liveness report, 503 when a check fails", status: http.StatusOK, response: health.PublicReport{},
+	},
+	{
+		method: http.MethodGet, path: "/readyz", operationId: "readyz", tag: tagService,
+		summary: "Readiness report, 503 when a check fails", status: http.StatusOK, response: health.PublicReport{},
+	},
+	{
+		method: http.MethodGet, path: "/openapi.json", operationId: "openapi", tag: tagService,
+		summary: "This document", status: http.StatusOK, response: map[string]interface{}{},
+	},
+	{
+		method: http.MethodGet, path: "/docs", operationId: "docs", tag: tagService,
+		summary: "Documentation page rendering this document", status: http.StatusOK, response: "", mediaType: MediaTypeHtml,
+	},
+	{
+		method: http.MethodPost, path: "/repository", operationId: "createRepo", tag: tagRepositories,
+		summary: "Creates a repository", scope: auth.ScopeReposCreate,
+		body: repositories.CreateRepoRequest{}, status: http.StatusCreated, response: repositories.CreateRepoResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/repositories", operationId: "createRepos", tag: tagRepositories,
+		summary: "Creates a batch of repositories", scope: auth.ScopeReposBatch, batch: true,
+		description: "The batch is answered with 201 when every repository was created, 206 when some failed and the status of the first failure when all did.",
+		body:        []repositories.CreateRepoRequest{}, status: http.StatusCreated, response: repositories.CreateReposResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/repository/:owner/:name", operationId: "getRepo", tag: tagRepositories,
+		summary: "Gets a repository", scope: auth.ScopeReposRead, parameters: repositoryParameters(),
+		status: http.StatusOK, response: repositories.Repository{},
+	},
+	{
+		method: http.MethodPatch, path: "/repository/:owner/:name", operationId: "updateRepo", tag: tagRepositories,
+		summary: "Updates a repository, missing fields are left untouched", scope: auth.ScopeReposUpdate,
+		parameters: repositoryParameters(), body: repositories.UpdateRepoRequest{},
+		status: http.StatusOK, response: repositories.Repository{},
+	},
+	{
+		method: http.MethodDelete, path: "/repository/:owner/:name", operationId: "deleteRepo", tag: tagRepositories,
+		summary: "Deletes or archives a repository", scope: auth.ScopeReposDelete,
+		description: "Answers 428 until X-Confirm-Delete carries owner/name when confirmation is required.",
+		parameters:  repositoryParameters(archiveParameter, confirmParameter),
+		status:      http.StatusOK, response: repositories.DeleteRepoResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/repository/:owner/:name/transfer", operationId: "transferRepo", tag: tagRepositories,
+		summary: "Transfers a repository to another owner", scope: auth.ScopeReposTransfer,
+		parameters: repositoryParameters(), body: repositories.TransferRepoRequest{},
+		status: http.StatusAccepted, response: repositories.Repository{},
+	},
+	{
+		method: http.MethodGet, path: "/repository/:owner/:name/protection", operationId: "getProtection", tag: tagRepositories,
+		summary: "Reports the drift of a branch from a protection policy", scope: auth.ScopeReposRead,
+		parameters: repositoryParameters(
+			queryParameter("policy", &Schema{Type: TypeString}, "policy to compare with, defaults to the configured one"),
+			queryParameter("branch", &Schema{Type: TypeString}, "branch to compare, defaults to the default branch"),
+		),
+		status: http.StatusOK, response: repositories.ProtectionReport{},
+	},
+	{
+		method: http.MethodPut, path: "/repository/:owner/:name/protection", operationId: "applyProtection", tag: tagRepositories,
+		summary: "Applies a protection policy to a branch", scope: auth.ScopeReposUpdate,
+		parameters: repositoryParameters(), body: repositories.ProtectionRequest{},
+		status: http.StatusOK, response: repositories.ProtectionReport{},
+	},
+	{
+		method: http.MethodPost, path: "/repository/:owner/:name/sync", operationId: "syncRepo", tag: tagRepositories,
+		summary: "Syncs the labels and topics of a repository", scope: auth.ScopeReposUpdate,
+		parameters: repositoryParameters(), body: repositories.SyncRequest{},
+		status: http.StatusOK, response: repositories.SyncResponse{},
+	},
+	{
+		method: http.MethodPut, path: "/repository/:owner/:name/access", operationId: "grantAccess", tag: tagRepositories,
+		summary: "Grants teams and collaborators access to a repository", scope: auth.ScopeReposAccess,
+		parameters: repositoryParameters(), body: repositories.AccessRequest{},
+		status: http.StatusOK, response: repositories.AccessResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/repositories", operationId: "listRepos", tag: tagRepositories,
+		summary: "Lists the repositories of an owner", scope: auth.ScopeReposRead,
+		parameters: []Parameter{
+			queryParameter("owner", &Schema{Type: TypeString}, "owner of the repositories"),
+			queryParameter("visibility", &Schema{Type: TypeString}, "all, public or private"),
+			queryParameter("page", &Schema{Type: TypeInteger, Minimum: float(0)}, "page to list, starting at 1"),
+			queryParameter("per_page", &Schema{Type: TypeInteger, Minimum: float(0), Maximum: float(repositories.MaxPerPage)}, "repositories per page"),
+			providerParameter,
+		},
+		status: http.StatusOK, response: repositories.RepositoryPage{},
+		headers: map[string]Header{
+			"Link": {Description: "RFC 8288 links to the next and last pages", Schema: &Schema{Type: TypeString}},
+		},
+	},
+	{
+		method: http.MethodDelete, path: "/repositories", operationId: "deleteRepos", tag: tagRepositories,
+		summary: "Deletes or archives the repositories of an owner matching a prefix", scope: auth.ScopeReposDelete, batch: true,
+		description: "Answers 428 until X-Confirm-Delete carries owner/prefix* when confirmation is required.",
+		parameters: []Parameter{
+			queryParameter("owner", &Schema{Type: TypeString}, "owner of the repositories"),
+			queryParameter("prefix", &Schema{Type: TypeString}, "prefix of the repository names"),
+			archiveParameter,
+			providerParameter,
+			confirmParameter,
+		},
+		status: http.StatusOK, response: repositories.DeleteReposResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/reconcile", operationId: "reconcile", tag: tagRepositories,
+		summary: "Reconciles the repositories of the manifest owners with the manifest", scope: auth.ScopeReposReconcile, batch: true,
+		description: "The manifest is accepted as yaml or json, the plan query parameter only reports the plan. " +
+			"Applying a plan that archives answers 428 until X-Confirm-Delete carries owner/* for every owner, comma separated, when confirmation is required.",
+		parameters: []Parameter{queryParameter("plan", &Schema{Type: TypeBoolean}, "only report the plan"), confirmParameter},
+		body:       repositories.Manifest{}, bodyTypes: []string{MediaTypeJson, MediaTypeYaml},
+		status: http.StatusOK, response: repositories.ReconcileResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/audit", operationId: "getAudit", tag: tagAudit,
+		summary: "Queries the audit log", scope: auth.ScopeAuditRead,
+		parameters: []Parameter{
+			queryParameter("owner", &Schema{Type: TypeString}, "owner of the audited repositories"),
+			queryParameter("since", &Schema{Type: TypeString}, "RFC3339 time or duration before now"),
+			queryParameter("limit", &Schema{Type: TypeInteger, Minimum: float(1)}, "maximum number of entries, 100 by default"),
+		},
+		status: http.StatusOK, response: []repositories.AuditEntry{},
+	},
+}
+
+// PathTemplate turns a gin route into an OpenAPI path template.
+func PathTemplate(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func lowerMethod(method string) string {
+	return strings.ToLower(method)
+}
+
+func jsonContent(schema *Schema, mediaTypes ...string) map[string]MediaType {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{MediaTypeJson}
+	}
+	content := make(map[string]MediaType, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = MediaType{Schema: schema}
+	}
+	return content
+}
+
+func (r route) operation(components schemas) *Operation {
+	operation := &Operation{
+		OperationId: r.operationId,
+		Summary:     r.summary,
+		Description: r.description,
+		Tags:        []string{r.tag},
+		Parameters:  r.parameters,
+		Responses:   make(map[string]Response),
+	}
+
+	mediaType := r.mediaType
+	if mediaType == "" {
+		mediaType = MediaTypeJson
+	}
+	operation.Responses[strconv.Itoa(r.status)] = Response{
+		Description: http.StatusText(r.status),
+		Headers:     r.headers,
+		Content:     jsonContent(components.of(r.response), mediaType),
+	}
+	if r.batch {
+		operation.Responses[strconv.Itoa(http.StatusPartialContent)] = Response{
+			Description: "Some items of the batch failed",
+			Content:     jsonContent(components.of(r.response)),
+		}
+	}
+
+	if r.body != nil {
+		body := components.of(r.body)
+		// a batch body lists at least one item, null or [] is no batch.
+		if body.Type == TypeArray {
+			body.Nullable, body.MinItems = false, 1
+		}
+		operation.RequestBody = &RequestBody{Required: true, Content: jsonContent(body, r.bodyTypes...)}
+	}
+
+	if r.scope != "" {
+		operation.Scope = r.scope
+		operation.Security = []map[string][]string{{securityApiKey: {}}, {securityBearer: {}}}
+		operation.Description = strings.TrimSpace("Requires the " + r.scope + " scope. " + r.description)
+		operation.Responses[strconv.Itoa(http.StatusTooManyRequests)] = Response{
+			Description: "Rate limit exceeded",
+			Headers: map[string]Header{
+				headers.RetryAfter: {Description: "seconds to wait before retrying", Schema: &Schema{Type: TypeInteger}},
+			},
+			Content: jsonContent(refTo(apiErrorSchema)),
+		}
+	}
+	if mediaType == MediaTypeJson {
+		operation.Responses["default"] = Response{Description: "Error", Content: jsonContent(refTo(apiErrorSchema))}
+	}
+	return operation
+}
+
+// Build generates the document from the route table, openapi.json is its
+// committed output.
+func Build() *Document {
+	components := make(schemas)
+	document := &Document{
+		OpenApi: Version,
+		Info: Info{
+			Title:       "Repositories service",
+			Description: "Creates and manages repositories across GitHub, GitLab and Gitea. Errors are answered with an ApiError body.",
+			Version:     "1.0.0",
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			Schemas: components,
+			SecuritySchemes: map[string]SecurityScheme{
+				securityApiKey: {Type: "apiKey", In: "header", Name: headers.ApiKey},
+				securityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	// the error schema is referenced by every operation, register it first.
+	components.forType(apiErrorType)
+
+	for _, r := range routes {
+		path := PathTemplate(r.path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(PathItem)
+		}
+		document.Paths[path][lowerMethod(r.method)] = r.operation(components)
+	}
+	return document
+}
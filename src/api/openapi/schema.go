@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"encoding/json"
+	"golang-microservices/src/api/utils/errors"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const refPrefix = "#/components/schemas/"
+
+const apiErrorSchema = "ApiError"
+const domainPackage = "repositories"
+
+var timeType = reflect.TypeOf(time.Time{})
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+var apiErrorType = reflect.TypeOf((*errors.ApiError)(nil)).Elem()
+
+func schemaName(ref string) string {
+	return strings.TrimPrefix(ref, refPrefix)
+}
+
+func refTo(name string) *Schema {
+	return &Schema{Ref: refPrefix + name}
+}
+
+// componentName is the type name, prefixed with its package outside of the
+// domain so health.PublicReport reads HealthPublicReport.
+func componentName(t reflect.Type) string {
+	pkg := path.Base(t.PkgPath())
+	if pkg == domainPackage {
+		return t.Name()
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+}
+
+// nullable marks what encoding/json writes as null when nil, a reference
+// cannot carry the flag in OpenAPI 3.0 and is left as is.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref == "" {
+		schema.Nullable = true
+	}
+	return schema
+}
+
+// schemas derives component schemas from go types through their json tags,
+// every named struct becomes a component referenced by its type name.
+type schemas map[string]*Schema
+
+// of returns the schema of the type of value, the value itself is not read.
+func (s schemas) of(value interface{}) *Schema {
+	return s.forType(reflect.TypeOf(value))
+}
+
+func (s schemas) forType(t reflect.Type) *Schema {
+	if t == apiErrorType {
+		// the interface is always backed by the unexported apiError.
+		if _, ok := s[apiErrorSchema]; !ok {
+			s[apiErrorSchema] = s.object(reflect.TypeOf(errors.NewApiError(0, "")).Elem())
+		}
+		return refTo(apiErrorSchema)
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: TypeString, Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(s.forType(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: TypeInteger, Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: TypeInteger, Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}
+	case reflect.String:
+		return &Schema{Type: TypeString}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: TypeArray, Items: s.forType(t.Elem()), Nullable: true}
+	case reflect.Map:
+		return &Schema{Type: TypeObject, AdditionalProperties: &AdditionalProperties{Schema: s.forType(t.Elem())}, Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		name := componentName(t)
+		if _, ok := s[name]; !ok {
+			// registered before the fields so recursive types terminate.
+			s[name] = &Schema{}
+			*s[name] = *s.object(t)
+		}
+		return refTo(name)
+	}
+	// interfaces and anything else accept any value.
+	return &Schema{}
+}
+
+// object lists the json fields of a struct, embedded structs without a tag
+// are flattened the way encoding/json does.
+func (s schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       TypeObject,
+		Properties: make(map[string]*Schema),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for property, value := range s.object(field.Type).Properties {
+				schema.Properties[property] = value
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = s.forType(field.Type)
+	}
+	return schema
+}
@@ -0,0 +1,27 @@
+package openapi
+
+import (
+	_ "embed"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// Spec returns the committed document as served at /openapi.json.
+func Spec() []byte {
+	return spec
+}
+
+// DocsPage returns the documentation page, it renders /openapi.json in the
+// browser without any external asset.
+func DocsPage() []byte {
+	return docsPage
+}
+
+// Load parses the committed document.
+func Load() (*Document, error) {
+	return Parse(spec)
+}
@@ -0,0 +1,229 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"golang-microservices/src/api/utils/errors"
+	"mime"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validator checks requests against the query parameters and json bodies of
+// a document. Malformed json is left to the handlers, only well formed
+// values of the wrong shape are reported.
+type Validator struct {
+	document *Document
+}
+
+func NewValidator(document *Document) *Validator {
+	return &Validator{document: document}
+}
+
+// Validate checks a request on a path template. Operations missing from the
+// document are not validated, routing already answers them.
+func (v *Validator) Validate(method string, path string, query url.Values, contentType string, body []byte) errors.ApiError {
+	operation := v.document.Operation(method, path)
+	if operation == nil {
+		return nil
+	}
+
+	for _, parameter := range operation.Parameters {
+		if parameter.In != "query" {
+			continue
+		}
+		values, ok := query[parameter.Name]
+		if !ok {
+			if parameter.Required {
+				return errors.NewBadRequestApiError("missing query parameter " + parameter.Name)
+			}
+			continue
+		}
+		for _, value := range values {
+			if err := v.validateParameter(parameter.Schema, value); err != nil {
+				return errors.NewBadRequestApiError("invalid query parameter " + parameter.Name + ": " + err.Error())
+			}
+		}
+	}
+
+	if operation.RequestBody == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	// bodies of other media types, such as yaml manifests, are read by the
+	// handler only.
+	if contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || mediaType != MediaTypeJson {
+			return nil
+		}
+	}
+	content, ok := operation.RequestBody.Content[MediaTypeJson]
+	if !ok {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	if schema := v.document.Resolve(content.Schema); value == nil && schema != nil && schema.Type != "" && !schema.Nullable {
+		return errors.NewBadRequestApiError("invalid request body: expected " + schema.Type)
+	}
+	if err := v.validateValue(content.Schema, value, ""); err != nil {
+		return errors.NewBadRequestApiError("invalid request body: " + err.Error())
+	}
+	return nil
+}
+
+func checkBounds(schema *Schema, number float64) error {
+	if schema.Minimum != nil && number < *schema.Minimum {
+		return fmt.Errorf("expected at least %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && number > *schema.Maximum {
+		return fmt.Errorf("expected at most %v", *schema.Maximum)
+	}
+	return nil
+}
+
+func checkEnum(schema *Schema, value string) error {
+	if len(schema.Enum) == 0 {
+		return nil
+	}
+	for _, allowed := range schema.Enum {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected one of %s", strings.Join(schema.Enum, ", "))
+}
+
+func (v *Validator) validateParameter(schema *Schema, value string) error {
+	schema = v.document.Resolve(schema)
+	if schema == nil {
+		return nil
+	}
+	switch schema.Type {
+	case TypeInteger:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected integer")
+		}
+		return checkBounds(schema, float64(number))
+	case TypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("expected number")
+		}
+		return checkBounds(schema, number)
+	case TypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+	case TypeString:
+		return checkEnum(schema, value)
+	}
+	return nil
+}
+
+func fieldPath(parent string, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
+
+// validateValue checks a decoded json value. Null is accepted below the top
+// level, the handlers decode it to the zero value.
+func (v *Validator) validateValue(schema *Schema, value interface{}, path string) error {
+	schema = v.document.Resolve(schema)
+	if schema == nil || value == nil {
+		return nil
+	}
+	fail := func(message string) error {
+		if path == "" {
+			return fmt.Errorf("%s", message)
+		}
+		return fmt.Errorf("%s: %s", path, message)
+	}
+
+	switch schema.Type {
+	case TypeObject:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("expected object")
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, ok := schema.Properties[key]
+			if !ok {
+				if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema == nil {
+					return fmt.Errorf("unknown field %s", fieldPath(path, key))
+				}
+				if schema.AdditionalProperties != nil {
+					property = schema.AdditionalProperties.Schema
+				}
+			}
+			if err := v.validateValue(property, object[key], fieldPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case TypeArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			return fail("expected array")
+		}
+		if len(items) < schema.MinItems {
+			return fail(fmt.Sprintf("expected at least %d items", schema.MinItems))
+		}
+		for i, item := range items {
+			if err := v.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case TypeString:
+		text, ok := value.(string)
+		if !ok {
+			return fail("expected string")
+		}
+		if err := checkEnum(schema, text); err != nil {
+			return fail(err.Error())
+		}
+	case TypeInteger:
+		number, ok := value.(json.Number)
+		if !ok {
+			return fail("expected integer")
+		}
+		integer, err := number.Int64()
+		if err != nil {
+			return fail("expected integer")
+		}
+		if err := checkBounds(schema, float64(integer)); err != nil {
+			return fail(err.Error())
+		}
+	case TypeNumber:
+		number, ok := value.(json.Number)
+		if !ok {
+			return fail("expected number")
+		}
+		decimal, err := number.Float64()
+		if err != nil {
+			return fail("expected number")
+		}
+		if err := checkBounds(schema, decimal); err != nil {
+			return fail(err.Error())
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fail("expected boolean")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func newTestValidator(t *testing.T) *Validator {
+	document, err := Load()
+	assert.Nil(t, err)
+	return NewValidator(document)
+}
+
+func TestValidateBody(t *testing.T) {
+	validator := newTestValidator(t)
+
+	for _, test := range []struct {
+		body    string
+		message string
+	}{
+		{`{"name": "repo", "description": "", "topics": ["go"], "access": {"teams": [{"slug": "core"}]}}`, ""},
+		{`{"name": "repo", "description": null}`, ""},
+		{`{"name": 12}`, "invalid request body: name: expected string"},
+		{`{"name": "repo", "description": true}`, "invalid request body: description: expected string"},
+		{`{"name": "repo", "topics": "go"}`, "invalid request body: topics: expected array"},
+		{`{"name": "repo", "access": {"teams": [{"slug": 1}]}}`, "invalid request body: access.teams[0].slug: expected string"},
+		{`{"name": "repo", "organization": "my-org"}`, ""},
+		{`["repo"]`, "invalid request body: expected object"},
+		{`null`, "invalid request body: expected object"},
+		{`{"name": `, ""},
+		{``, ""},
+	} {
+		err := validator.Validate(http.MethodPost, "/repository", url.Values{}, "application/json", []byte(test.body))
+		if test.message == "" {
+			assert.Nil(t, err, test.body)
+			continue
+		}
+		assert.NotNil(t, err, test.body)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, test.message, err.Message())
+	}
+}
+
+func TestValidateBatchBody(t *testing.T) {
+	validator := newTestValidator(t)
+
+	err := validator.Validate(http.MethodPost, "/repositories", url.Values{}, "", []byte(`[{"name": "one", "team_ids": [1]}, {"name": "two", "description": true}]`))
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid request body: [1].description: expected string", err.Message())
+}
+
+func TestValidateEmptyBatchBody(t *testing.T) {
+	validator := newTestValidator(t)
+
+	err := validator.Validate(http.MethodPost, "/repositories", url.Values{}, "", []byte(`[]`))
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid request body: expected at least 1 items", err.Message())
+
+	err = validator.Validate(http.MethodPost, "/repositories", url.Values{}, "", []byte(`null`))
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid request body: expected array", err.Message())
+}
+
+func TestValidateBodyMediaType(t *testing.T) {
+	validator := newTestValidator(t)
+	manifest := []byte("owner: my-org\nrepositories:\n  - name: api\n")
+
+	assert.Nil(t, validator.Validate(http.MethodPost, "/reconcile", url.Values{}, "application/yaml", manifest))
+	assert.Nil(t, validator.Validate(http.MethodPost, "/reconcile", url.Values{}, "", manifest))
+
+	err := validator.Validate(http.MethodPost, "/reconcile", url.Values{}, "application/json; charset=utf-8", []byte(`{"repositories": [{"name": "api", "private": "no"}]}`))
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid request body: repositories[0].private: expected boolean", err.Message())
+}
+
+func TestValidateQuery(t *testing.T) {
+	validator := newTestValidator(t)
+
+	for _, test := range []struct {
+		method  string
+		path    string
+		query   string
+		message string
+	}{
+		{http.MethodGet, "/repositories", "owner=my-org&page=2&per_page=100", ""},
+		{http.MethodGet, "/repositories", "page=two", "invalid query parameter page: expected integer"},
+		{http.MethodGet, "/repositories", "per_page=101", "invalid query parameter per_page: expected at most 100"},
+		{http.MethodGet, "/repositories", "page=-1", "invalid query parameter page: expected at least 0"},
+		{http.MethodDelete, "/repository/{owner}/{name}", "archive=yes", "invalid query parameter archive: expected true or false"},
+		{http.MethodPost, "/reconcile", "plan=true", ""},
+		{http.MethodGet, "/audit", "limit=0", "invalid query parameter limit: expected at least 1"},
+		{http.MethodGet, "/audit", "unknown=1", ""},
+		{http.MethodGet, "/missing", "page=two", ""},
+	} {
+		query, _ := url.ParseQuery(test.query)
+		err := validator.Validate(test.method, test.path, query, "", nil)
+		if test.message == "" {
+			assert.Nil(t, err, test.query)
+			continue
+		}
+		assert.NotNil(t, err, test.query)
+		assert.EqualValues(t, test.message, err.Message())
+	}
+}
@@ -0,0 +1,61 @@
+package protection
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang-microservices/src/api/domain/repositories"
+	"io/ioutil"
+	"sort"
+)
+
+// Store holds the named branch protection policies, repositories are
+// protected by policy name so the rules live in one place.
+type Store struct {
+	policies map[string]repositories.ProtectionPolicy
+}
+
+func NewStore(policies map[string]repositories.ProtectionPolicy) *Store {
+	store := &Store{policies: make(map[string]repositories.ProtectionPolicy, len(policies))}
+	for name, policy := range policies {
+		policy.Name = name
+		store.policies[name] = policy
+	}
+	return store
+}
+
+// Load reads a json object of policy names to policies, an empty path gives
+// an empty store.
+func Load(path string) (*Store, error) {
+	if path == "" {
+		return NewStore(nil), nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[string]repositories.ProtectionPolicy)
+	if err := json.Unmarshal(content, &policies); err != nil {
+		return nil, fmt.Errorf("invalid protection policies file %s: %w", path, err)
+	}
+	for name, policy := range policies {
+		if policy.RequiredReviews < 0 || policy.RequiredReviews > 6 {
+			return nil, fmt.Errorf("invalid protection policy %s: required reviews must be between 0 and 6", name)
+		}
+	}
+	return NewStore(policies), nil
+}
+
+func (s *Store) Get(name string) (repositories.ProtectionPolicy, bool) {
+	policy, ok := s.policies[name]
+	return policy, ok
+}
+
+func (s *Store) Names() []string {
+	result := make([]string, 0, len(s.policies))
+	for name := range s.policies {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
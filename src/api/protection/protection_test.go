@@ -0,0 +1,45 @@
+package protection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{
+		"strict": {"required_reviews": 2, "required_status_checks": ["test"], "block_force_pushes": true},
+		"rules": {"ruleset": true, "block_deletions": true}
+	}`), 0600))
+
+	store, err := Load(path)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"rules", "strict"}, store.Names())
+
+	policy, ok := store.Get("strict")
+	assert.True(t, ok)
+	assert.EqualValues(t, "strict", policy.Name)
+	assert.EqualValues(t, 2, policy.RequiredReviews)
+	assert.EqualValues(t, []string{"test"}, policy.RequiredStatusChecks)
+	assert.False(t, policy.Ruleset)
+
+	policy, _ = store.Get("rules")
+	assert.True(t, policy.Ruleset)
+	assert.True(t, policy.BlockDeletions)
+}
+
+func TestLoadErrors(t *testing.T) {
+	store, err := Load("")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{}, store.Names())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+
+	path := filepath.Join(t.TempDir(), "policies.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"strict": {"required_reviews": 7}}`), 0600))
+	_, err = Load(path)
+	assert.EqualValues(t, "invalid protection policy strict: required reviews must be between 0 and 6", err.Error())
+}
@@ -0,0 +1,20 @@
+package gitea_provider
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/gitea"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/utils/errors"
+)
+
+// AccountLogin returns the Gitea user owning the access token.
+func (p *Provider) AccountLogin(ctx context.Context) (string, errors.ApiError) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetAuthenticatedUser)
+	response, err := p.client.Get(ctx, p.getUrl(pathAuthenticatedUser), p.getHeaders())
+
+	var user gitea.User
+	if apiErr := handleResponse(ctx, "get authenticated user", response, err, &user); apiErr != nil {
+		return "", apiErr
+	}
+	return user.Login, nil
+}
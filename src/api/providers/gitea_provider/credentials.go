@@ -0,0 +1,20 @@
+package gitea_provider
+
+import (
+	"context"
+	"errors"
+)
+
+const pathAuthenticatedUser = "/api/v1/user"
+const endpointGetAuthenticatedUser = "GET /api/v1/user"
+
+func (p *Provider) CheckCredentials(ctx context.Context) error {
+	if p.accessToken == "" {
+		return errors.New("gitea access token is not configured")
+	}
+
+	if _, apiErr := p.AccountLogin(ctx); apiErr != nil {
+		return errors.New("gitea access token is invalid: " + apiErr.Message())
+	}
+	return nil
+}
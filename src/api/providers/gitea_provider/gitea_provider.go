@@ -0,0 +1,274 @@
+package gitea_provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/gitea"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const headerAuthorization = "Authorization"
+const headerAuthorizationFormat = "token %s"
+const headerLink = "Link"
+
+const pathCreateUserRepo = "/api/v1/user/repos"
+const pathCreateOrgRepoFormat = "/api/v1/orgs/%s/repos"
+const pathRepoFormat = "/api/v1/repos/%s/%s"
+const pathTransferRepoFormat = "/api/v1/repos/%s/%s/transfer"
+const pathHooksFormat = "/api/v1/repos/%s/%s/hooks"
+const pathListUserRepos = "/api/v1/user/repos"
+const pathListOrgReposFormat = "/api/v1/orgs/%s/repos"
+
+const endpointCreateUserRepo = "POST /api/v1/user/repos"
+const endpointCreateOrgRepo = "POST /api/v1/orgs/{org}/repos"
+const endpointGetRepo = "GET /api/v1/repos/{owner}/{repo}"
+const endpointEditRepo = "PATCH /api/v1/repos/{owner}/{repo}"
+const endpointDeleteRepo = "DELETE /api/v1/repos/{owner}/{repo}"
+const endpointTransferRepo = "POST /api/v1/repos/{owner}/{repo}/transfer"
+const endpointCreateHook = "POST /api/v1/repos/{owner}/{repo}/hooks"
+
+const hookTypeGitea = "gitea"
+const endpointListUserRepos = "GET /api/v1/user/repos"
+const endpointListOrgRepos = "GET /api/v1/orgs/{org}/repos"
+
+const defaultPerPage = 30
+
+type Provider struct {
+	client      *restclient.Client
+	baseUrl     string
+	accessToken string
+}
+
+// New builds a Gitea provider, baseUrl is the instance root such as
+// https://gitea.example.com without the /api/v1 suffix.
+func New(client *restclient.Client, baseUrl string, accessToken string) *Provider {
+	return &Provider{
+		client:      client,
+		baseUrl:     strings.TrimRight(baseUrl, "/"),
+		accessToken: accessToken,
+	}
+}
+
+func (p *Provider) getHeaders() http.Header {
+	headers := http.Header{}
+	headers.Set(headerAuthorization, fmt.Sprintf(headerAuthorizationFormat, p.accessToken))
+	return headers
+}
+
+func (p *Provider) getUrl(path string) string {
+	return p.baseUrl + path
+}
+
+func (p *Provider) getRepoUrl(owner string, name string) string {
+	return p.getUrl(fmt.Sprintf(pathRepoFormat, url.PathEscape(owner), url.PathEscape(name)))
+}
+
+func toRepository(repo *gitea.Repository) *repositories.Repository {
+	return &repositories.Repository{
+		Id:            repo.Id,
+		Owner:         repo.Owner.Login,
+		Name:          repo.Name,
+		FullName:      repo.FullName,
+		Description:   repo.Description,
+		Homepage:      repo.Website,
+		Private:       repo.Private,
+		Archived:      repo.Archived,
+		DefaultBranch: repo.DefaultBranch,
+		HtmlUrl:       repo.HtmlUrl,
+		Topics:        repo.Topics,
+		Provider:      providers.Gitea,
+	}
+}
+
+func handleResponse(ctx context.Context, action string, response *http.Response, err error, result interface{}) errors.ApiError {
+	log := logger.FromContext(ctx)
+
+	if err != nil {
+		log.Error(fmt.Sprintf("error when trying to %s in gitea", action), logger.Err(err))
+		return errors.NewInternalServerError(err.Error())
+	}
+
+	responseBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		log.Error("error when trying to read bytes from response body", logger.Err(err))
+		return errors.NewInternalServerError("invalid response body")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 {
+		var errResponse gitea.ErrorResponse
+		if err := json.Unmarshal(responseBytes, &errResponse); err != nil {
+			return errors.NewInternalServerError("invalid json response body")
+		}
+
+		log.Warn(fmt.Sprintf("gitea rejected request to %s", action),
+			logger.Any("status", response.StatusCode),
+			logger.Any("message", errResponse.Message),
+		)
+		return errors.NewApiError(response.StatusCode, errResponse.Message)
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBytes, result); err != nil {
+		log.Error("error unmarshalling response body", logger.Err(err))
+		return errors.NewInternalServerError("error unmarshalling response body")
+	}
+
+	return nil
+}
+
+func (p *Provider) CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	endpoint, path := endpointCreateUserRepo, pathCreateUserRepo
+	if request.Org != "" {
+		endpoint, path = endpointCreateOrgRepo, fmt.Sprintf(pathCreateOrgRepoFormat, url.PathEscape(request.Org))
+	}
+	ctx = metrics.WithEndpoint(ctx, endpoint)
+
+	body := gitea.CreateRepoRequest{
+		Name:        request.Name,
+		Description: request.Description,
+		Private:     true,
+	}
+	response, err := p.client.Post(ctx, p.getUrl(path), body, p.getHeaders())
+
+	var result gitea.Repository
+	if apiErr := handleResponse(ctx, "create repository", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+	return toRepository(&result), nil
+}
+
+func (p *Provider) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetRepo)
+
+	response, err := p.client.Get(ctx, p.getRepoUrl(owner, name), p.getHeaders())
+
+	var result gitea.Repository
+	if apiErr := handleResponse(ctx, "get repository", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+	return toRepository(&result), nil
+}
+
+func (p *Provider) UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	ctx = metrics.WithEndpoint(ctx, endpointEditRepo)
+
+	body := gitea.EditRepoRequest{
+		Name:          request.Name,
+		Description:   request.Description,
+		Website:       request.Homepage,
+		Private:       request.Private,
+		Archived:      request.Archived,
+		DefaultBranch: request.DefaultBranch,
+	}
+	response, err := p.client.Patch(ctx, p.getRepoUrl(owner, name), body, p.getHeaders())
+
+	var result gitea.Repository
+	if apiErr := handleResponse(ctx, "edit repository", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+	return toRepository(&result), nil
+}
+
+// TransferRepo moves the repository, Gitea cannot rename while transferring so
+// a new name is applied with an edit on the new owner afterwards.
+func (p *Provider) TransferRepo(ctx context.Context, owner string, name string, request repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	transferCtx := metrics.WithEndpoint(ctx, endpointTransferRepo)
+
+	body := gitea.TransferRepoRequest{NewOwner: request.NewOwner, TeamIds: request.TeamIds}
+	transferUrl := p.getUrl(fmt.Sprintf(pathTransferRepoFormat, url.PathEscape(owner), url.PathEscape(name)))
+	response, err := p.client.Post(transferCtx, transferUrl, body, p.getHeaders())
+
+	var result gitea.Repository
+	if apiErr := handleResponse(transferCtx, "transfer repository", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if request.NewName == "" || request.NewName == name {
+		return toRepository(&result), nil
+	}
+	return p.UpdateRepo(ctx, request.NewOwner, name, repositories.UpdateRepoRequest{Name: &request.NewName})
+}
+
+func (p *Provider) CreateHook(ctx context.Context, owner string, name string, hook repositories.Webhook, secret string) (int64, errors.ApiError) {
+	ctx = metrics.WithEndpoint(ctx, endpointCreateHook)
+
+	body := gitea.CreateHookRequest{
+		Type:   hookTypeGitea,
+		Active: true,
+		Events: hook.Events,
+		Config: gitea.HookConfig{Url: hook.Url, ContentType: hook.ContentType, Secret: secret},
+	}
+	hooksUrl := p.getUrl(fmt.Sprintf(pathHooksFormat, url.PathEscape(owner), url.PathEscape(name)))
+	response, err := p.client.Post(ctx, hooksUrl, body, p.getHeaders())
+
+	var result gitea.Hook
+	if apiErr := handleResponse(ctx, "create hook", response, err, &result); apiErr != nil {
+		return 0, apiErr
+	}
+	return result.Id, nil
+}
+
+func (p *Provider) DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError {
+	ctx = metrics.WithEndpoint(ctx, endpointDeleteRepo)
+
+	response, err := p.client.Delete(ctx, p.getRepoUrl(owner, name), p.getHeaders())
+
+	return handleResponse(ctx, "delete repository", response, err, nil)
+}
+
+// ListRepos lists the repositories of an org, or of the token user when no
+// owner is given. Gitea cannot filter by visibility on these endpoints so the
+// filter is applied on the returned page, pages may hold fewer items.
+func (p *Provider) ListRepos(ctx context.Context, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	endpoint, path := endpointListUserRepos, pathListUserRepos
+	if options.Owner != "" {
+		endpoint, path = endpointListOrgRepos, fmt.Sprintf(pathListOrgReposFormat, url.PathEscape(options.Owner))
+	}
+	ctx = metrics.WithEndpoint(ctx, endpoint)
+
+	if options.Page <= 0 {
+		options.Page = 1
+	}
+	if options.PerPage <= 0 {
+		options.PerPage = defaultPerPage
+	}
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(options.Page))
+	query.Set("limit", strconv.Itoa(options.PerPage))
+
+	response, err := p.client.Get(ctx, p.getUrl(path)+"?"+query.Encode(), p.getHeaders())
+
+	repos := make([]gitea.Repository, 0)
+	if apiErr := handleResponse(ctx, "list repositories", response, err, &repos); apiErr != nil {
+		return nil, apiErr
+	}
+
+	result := repositories.RepositoryPage{
+		Repositories: make([]repositories.Repository, 0, len(repos)),
+		Page:         options.Page,
+	}
+	result.NextPage, result.LastPage = providers.ParseLinkHeader(response.Header.Get(headerLink))
+	for i := range repos {
+		repo := toRepository(&repos[i])
+		if options.Visibility == providers.VisibilityPrivate && !repo.Private ||
+			options.Visibility == providers.VisibilityPublic && repo.Private {
+			continue
+		}
+		result.Repositories = append(result.Repositories, *repo)
+	}
+	return &result, nil
+}
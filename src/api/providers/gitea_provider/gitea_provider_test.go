@@ -0,0 +1,153 @@
+package gitea_provider
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const repoJson = `{
+  "id": 12,
+  "name": "my-repo",
+  "full_name": "my-org/my-repo",
+  "description": "testing",
+  "private": true,
+  "archived": false,
+  "default_branch": "main",
+  "html_url": "https://gitea.example.com/my-org/my-repo",
+  "owner": {"id": 3, "login": "my-org"}
+}`
+
+var expectedRepository = repositories.Repository{
+	Id:            12,
+	Owner:         "my-org",
+	Name:          "my-repo",
+	FullName:      "my-org/my-repo",
+	Description:   "testing",
+	Private:       true,
+	DefaultBranch: "main",
+	HtmlUrl:       "https://gitea.example.com/my-org/my-repo",
+	Provider:      "gitea",
+}
+
+func newMockedProvider() (*Provider, *restclient.Client) {
+	client, _ := restclient.NewClient("")
+	client.StartMock()
+	return New(client, "https://gitea.example.com/", "abc123"), client
+}
+
+func mockResponse(client *restclient.Client, method string, url string, statusCode int, header http.Header, body string) {
+	client.AddMock(&restclient.Mock{
+		Url:        url,
+		HttpMethod: method,
+		Response: &http.Response{
+			StatusCode: statusCode,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		},
+	})
+}
+
+func TestGetHeaders(t *testing.T) {
+	provider, _ := newMockedProvider()
+	assert.EqualValues(t, "token abc123", provider.getHeaders().Get("Authorization"))
+}
+
+func TestCreateUserRepo(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPost, "https://gitea.example.com/api/v1/user/repos", http.StatusCreated, nil, repoJson)
+
+	repo, err := provider.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedRepository, *repo)
+}
+
+func TestCreateOrgRepoConflict(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPost, "https://gitea.example.com/api/v1/orgs/my-org/repos", http.StatusConflict, nil,
+		`{"message": "The repository with the same name already exists.", "url": "https://gitea.example.com/api/swagger"}`)
+
+	repo, err := provider.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo", Org: "my-org"})
+
+	assert.Nil(t, repo)
+	assert.EqualValues(t, http.StatusConflict, err.Status())
+	assert.EqualValues(t, "The repository with the same name already exists.", err.Message())
+}
+
+func TestUpdateRepoOk(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPatch, "https://gitea.example.com/api/v1/repos/my-org/my-repo", http.StatusOK, nil, repoJson)
+
+	description := "testing"
+	repo, err := provider.UpdateRepo(context.Background(), "my-org", "my-repo", repositories.UpdateRepoRequest{Description: &description})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedRepository, *repo)
+}
+
+func TestTransferRepoOk(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPost, "https://gitea.example.com/api/v1/repos/my-org/my-repo/transfer", http.StatusAccepted, nil,
+		strings.Replace(repoJson, "my-org", "new-org", -1))
+
+	repo, err := provider.TransferRepo(context.Background(), "my-org", "my-repo",
+		repositories.TransferRepoRequest{NewOwner: "new-org", TeamIds: []int64{4}})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "new-org/my-repo", repo.FullName)
+}
+
+func TestCreateHookOk(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPost, "https://gitea.example.com/api/v1/repos/my-org/my-repo/hooks", http.StatusCreated, nil,
+		`{"id": 7, "type": "gitea", "active": true}`)
+
+	id, err := provider.CreateHook(context.Background(), "my-org", "my-repo",
+		repositories.Webhook{Url: "https://ci.example.com/hooks", ContentType: "json", Events: []string{"push"}}, "s3cr3t")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 7, id)
+}
+
+func TestDeleteRepoNotFound(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodDelete, "https://gitea.example.com/api/v1/repos/my-org/missing", http.StatusNotFound, nil,
+		`{"message": "repository does not exist"}`)
+
+	err := provider.DeleteRepo(context.Background(), "my-org", "missing")
+
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "repository does not exist", err.Message())
+}
+
+func TestListReposFiltersVisibility(t *testing.T) {
+	provider, client := newMockedProvider()
+	header := http.Header{}
+	header.Set("Link", `<https://gitea.example.com/api/v1/orgs/my-org/repos?limit=30&page=2>; rel="next",`+
+		`<https://gitea.example.com/api/v1/orgs/my-org/repos?limit=30&page=2>; rel="last"`)
+	public := strings.Replace(strings.Replace(repoJson, `"private": true`, `"private": false`, 1), `"id": 12`, `"id": 13`, 1)
+	mockResponse(client, http.MethodGet, "https://gitea.example.com/api/v1/orgs/my-org/repos?limit=30&page=1", http.StatusOK, header,
+		"["+repoJson+","+public+"]")
+
+	page, err := provider.ListRepos(context.Background(), repositories.ListReposOptions{Owner: "my-org", Visibility: "private"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, page.NextPage)
+	assert.EqualValues(t, 2, page.LastPage)
+	assert.EqualValues(t, []repositories.Repository{expectedRepository}, page.Repositories)
+}
+
+func TestCheckCredentials(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodGet, "https://gitea.example.com/api/v1/user", http.StatusOK, nil, `{"id": 1, "login": "bot"}`)
+	assert.Nil(t, provider.CheckCredentials(context.Background()))
+
+	unconfigured := New(client, "https://gitea.example.com", "")
+	assert.EqualValues(t, "gitea access token is not configured", unconfigured.CheckCredentials(context.Background()).Error())
+}
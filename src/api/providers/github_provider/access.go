@@ -0,0 +1,64 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/metrics"
+	"net/http"
+	"net/url"
+)
+
+const pathTeamRepoFormat = "/orgs/%s/teams/%s/repos/%s/%s"
+const pathCollaboratorFormat = "/repos/%s/%s/collaborators/%s"
+const pathRepoTeamsFormat = "/repos/%s/%s/teams"
+
+const endpointAddTeamRepo = "PUT /orgs/{org}/teams/{team_slug}/repos/{owner}/{repo}"
+const endpointAddCollaborator = "PUT /repos/{owner}/{repo}/collaborators/{username}"
+const endpointListRepoTeams = "GET /repos/{owner}/{repo}/teams"
+
+const teamsPerPage = 100
+
+// ListRepoTeams returns the teams having access to owner/name, a repository
+// rarely has more than a page of them.
+func (p *Provider) ListRepoTeams(ctx context.Context, owner string, name string) ([]github.Team, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointListRepoTeams)
+
+	teamsUrl := fmt.Sprintf("%s?per_page=%d", p.getGitUrl(pathRepoTeamsFormat, owner, name), teamsPerPage)
+	response, err := p.client.Get(ctx, teamsUrl, p.getHeaders())
+
+	result := make([]github.Team, 0)
+	if errResponse := handleResponse(ctx, "list repository teams", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return result, nil
+}
+
+// AddTeamRepo grants the team slug of org permission on owner/name.
+func (p *Provider) AddTeamRepo(ctx context.Context, org string, slug string, owner string, name string, permission string) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointAddTeamRepo)
+
+	teamUrl := p.getUrl(fmt.Sprintf(pathTeamRepoFormat,
+		url.PathEscape(org), url.PathEscape(slug), url.PathEscape(owner), url.PathEscape(name)))
+	response, err := p.client.Put(ctx, teamUrl, github.PermissionRequest{Permission: permission}, p.getHeaders())
+
+	return handleResponse(ctx, "add team repository", response, err, nil)
+}
+
+// AddCollaborator answers 201 with an invitation for users outside the
+// organization and 204 when the user got access right away.
+func (p *Provider) AddCollaborator(ctx context.Context, owner string, name string, username string, permission string) (*github.Invitation, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointAddCollaborator)
+
+	response, err := p.client.Put(ctx, p.getGitUrl(pathCollaboratorFormat, owner, name, url.PathEscape(username)),
+		github.PermissionRequest{Permission: permission}, p.getHeaders())
+	if err == nil && response.StatusCode == http.StatusNoContent {
+		return nil, handleResponse(ctx, "add collaborator", response, err, nil)
+	}
+
+	var result github.Invitation
+	if errResponse := handleResponse(ctx, "add collaborator", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
@@ -0,0 +1,55 @@
+package github_provider
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/repositories"
+	"net/http"
+	"testing"
+)
+
+func TestAddTeamRepo(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodPut, "https://api.github.com/orgs/my-org/teams/core/repos/my-org/my-repo", http.StatusNoContent, "")
+
+	err := NewRepoProvider(provider).(*repoProvider).AddTeam(context.Background(), "my-org", "my-repo", "core", "push")
+	assert.Nil(t, err)
+}
+
+func TestAddTeamRepoNotFound(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodPut, "https://api.github.com/orgs/my-org/teams/missing/repos/my-org/my-repo", http.StatusNotFound,
+		`{"message": "Not Found"}`)
+
+	err := provider.AddTeamRepo(context.Background(), "my-org", "missing", "my-org", "my-repo", "push")
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+}
+
+func TestAddCollaboratorInvited(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodPut, "https://api.github.com/repos/my-org/my-repo/collaborators/octocat", http.StatusCreated,
+		`{"id": 42, "permissions": "write"}`)
+
+	id, err := NewRepoProvider(provider).(*repoProvider).AddCollaborator(context.Background(), "my-org", "my-repo", "octocat", "push")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 42, id)
+}
+
+func TestAddCollaboratorGranted(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodPut, "https://api.github.com/repos/my-org/my-repo/collaborators/octocat", http.StatusNoContent, "")
+
+	id, err := NewRepoProvider(provider).(*repoProvider).AddCollaborator(context.Background(), "my-org", "my-repo", "octocat", "push")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, id)
+}
+
+func TestListTeams(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/teams?per_page=100", http.StatusOK,
+		`[{"id": 1, "slug": "core", "permission": "push"}]`)
+
+	teams, err := NewRepoProvider(provider).(*repoProvider).ListTeams(context.Background(), "my-org", "my-repo")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []repositories.TeamAccess{{Slug: "core", Permission: "push"}}, teams)
+}
@@ -0,0 +1,72 @@
+package github_provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/metrics"
+	"golang.org/x/crypto/nacl/box"
+	"net/http"
+	"net/url"
+)
+
+const pathActionsPublicKeyFormat = "/repos/%s/%s/actions/secrets/public-key"
+const pathActionsSecretFormat = "/repos/%s/%s/actions/secrets/%s"
+const pathActionsVariablesFormat = "/repos/%s/%s/actions/variables"
+
+const endpointGetActionsPublicKey = "GET /repos/{owner}/{repo}/actions/secrets/public-key"
+const endpointPutActionsSecret = "PUT /repos/{owner}/{repo}/actions/secrets/{secret_name}"
+const endpointCreateActionsVariable = "POST /repos/{owner}/{repo}/actions/variables"
+
+// sealSecret encrypts value for the repository public key with a libsodium
+// sealed box, the only format GitHub accepts for Actions secrets.
+func sealSecret(publicKey string, value string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != 32 {
+		return "", fmt.Errorf("invalid actions public key")
+	}
+
+	var recipient [32]byte
+	copy(recipient[:], key)
+	sealed, err := box.SealAnonymous(nil, []byte(value), &recipient, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (p *Provider) GetActionsPublicKey(ctx context.Context, owner string, name string) (*github.ActionsPublicKey, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetActionsPublicKey)
+
+	response, err := p.client.Get(ctx, p.getGitUrl(pathActionsPublicKeyFormat, owner, name), p.getHeaders())
+
+	var result github.ActionsPublicKey
+	if errResponse := handleResponse(ctx, "get actions public key", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
+
+// PutActionsSecret creates or replaces secretName with value sealed for key.
+func (p *Provider) PutActionsSecret(ctx context.Context, owner string, name string, key *github.ActionsPublicKey, secretName string, value string) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointPutActionsSecret)
+
+	encrypted, err := sealSecret(key.Key, value)
+	if err != nil {
+		return &github.GithubErrorResponse{StatusCode: http.StatusInternalServerError, Message: err.Error()}
+	}
+	body := github.PutActionsSecretRequest{EncryptedValue: encrypted, KeyId: key.KeyId}
+	response, err := p.client.Put(ctx, p.getGitUrl(pathActionsSecretFormat, owner, name, url.PathEscape(secretName)), body, p.getHeaders())
+
+	return handleResponse(ctx, "set actions secret", response, err, nil)
+}
+
+func (p *Provider) CreateActionsVariable(ctx context.Context, owner string, name string, variable github.ActionsVariable) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointCreateActionsVariable)
+
+	response, err := p.client.Post(ctx, p.getGitUrl(pathActionsVariablesFormat, owner, name), variable, p.getHeaders())
+
+	return handleResponse(ctx, "create actions variable", response, err, nil)
+}
@@ -0,0 +1,89 @@
+package github_provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/github"
+	"golang.org/x/crypto/nacl/box"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestSealSecret(t *testing.T) {
+	publicKey, privateKey, _ := box.GenerateKey(rand.Reader)
+
+	sealed, err := sealSecret(base64.StdEncoding.EncodeToString(publicKey[:]), "s3cr3t")
+	assert.Nil(t, err)
+
+	decoded, _ := base64.StdEncoding.DecodeString(sealed)
+	value, ok := box.OpenAnonymous(nil, decoded, publicKey, privateKey)
+	assert.True(t, ok)
+	assert.EqualValues(t, "s3cr3t", string(value))
+
+	_, err = sealSecret("bm90IGEga2V5", "s3cr3t")
+	assert.EqualValues(t, "invalid actions public key", err.Error())
+}
+
+func TestRepoProviderSetActionsSecrets(t *testing.T) {
+	publicKey, privateKey, _ := box.GenerateKey(rand.Reader)
+
+	var mutex sync.Mutex
+	values := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"key_id": "568250167242549743", "key": "` + base64.StdEncoding.EncodeToString(publicKey[:]) + `"}`))
+			return
+		}
+
+		var request github.PutActionsSecretRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		assert.EqualValues(t, "568250167242549743", request.KeyId)
+		decoded, _ := base64.StdEncoding.DecodeString(request.EncryptedValue)
+		value, _ := box.OpenAnonymous(nil, decoded, publicKey, privateKey)
+
+		mutex.Lock()
+		values[r.URL.Path] = string(value)
+		mutex.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, _ := restclient.NewClient("")
+	provider := NewRepoProvider(New(client, server.URL, "2022-11-28", "abc123")).(*repoProvider)
+
+	err := provider.SetActionsSecrets(context.Background(), "my-org", "my-repo", map[string]string{
+		"DEPLOY_KEY": "ssh-ed25519 AAAA",
+		"NPM_TOKEN":  "npm_abc",
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, map[string]string{
+		"/repos/my-org/my-repo/actions/secrets/DEPLOY_KEY": "ssh-ed25519 AAAA",
+		"/repos/my-org/my-repo/actions/secrets/NPM_TOKEN":  "npm_abc",
+	}, values)
+}
+
+func TestRepoProviderSetActionsSecretsNoAccess(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/actions/secrets/public-key", http.StatusForbidden,
+		`{"message": "Resource not accessible by integration"}`)
+
+	err := NewRepoProvider(provider).(*repoProvider).SetActionsSecrets(context.Background(), "my-org", "my-repo", map[string]string{"NPM_TOKEN": "npm_abc"})
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "Resource not accessible by integration", err.Message())
+}
+
+func TestCreateActionsVariable(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodPost, "https://api.github.com/repos/my-org/my-repo/actions/variables", http.StatusConflict,
+		`{"message": "Already exists - Variable already exists"}`)
+
+	err := provider.CreateActionsVariable(context.Background(), "my-org", "my-repo", github.ActionsVariable{Name: "GO_VERSION", Value: "1.17"})
+	assert.EqualValues(t, http.StatusConflict, err.StatusCode)
+	assert.EqualValues(t, "Already exists - Variable already exists", err.Message)
+}
@@ -0,0 +1,177 @@
+package github_provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/metrics"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const pathContentsFormat = "/repos/%s/%s/contents/%s"
+const pathGitRefFormat = "/repos/%s/%s/git/ref/heads/%s"
+const pathGitUpdateRefFormat = "/repos/%s/%s/git/refs/heads/%s"
+const pathGitCommitFormat = "/repos/%s/%s/git/commits/%s"
+const pathGitCreateCommitFormat = "/repos/%s/%s/git/commits"
+const pathGitTreesFormat = "/repos/%s/%s/git/trees"
+
+const endpointPutContents = "PUT /repos/{owner}/{repo}/contents/{path}"
+const endpointGetRef = "GET /repos/{owner}/{repo}/git/ref/{ref}"
+const endpointUpdateRef = "PATCH /repos/{owner}/{repo}/git/refs/{ref}"
+const endpointGetCommit = "GET /repos/{owner}/{repo}/git/commits/{sha}"
+const endpointCreateCommit = "POST /repos/{owner}/{repo}/git/commits"
+const endpointCreateTree = "POST /repos/{owner}/{repo}/git/trees"
+
+const fileModeBlob = "100644"
+const treeEntryBlob = "blob"
+
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
+func (p *Provider) getGitUrl(format string, owner string, name string, args ...string) string {
+	values := []interface{}{url.PathEscape(owner), url.PathEscape(name)}
+	for _, arg := range args {
+		values = append(values, arg)
+	}
+	return p.getUrl(fmt.Sprintf(format, values...))
+}
+
+// PutContents creates a single file, it is the only write GitHub accepts on an
+// empty repository.
+func (p *Provider) PutContents(ctx context.Context, owner string, name string, branch string, message string, file repositories.File) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointPutContents)
+
+	body := github.PutContentsRequest{
+		Message: message,
+		Content: base64.StdEncoding.EncodeToString(file.Content),
+		Branch:  branch,
+	}
+	response, err := p.client.Put(ctx, p.getGitUrl(pathContentsFormat, owner, name, escapePath(file.Path)), body, p.getHeaders())
+
+	return handleResponse(ctx, "create file", response, err, nil)
+}
+
+func (p *Provider) getRef(ctx context.Context, owner string, name string, branch string) (*github.Ref, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetRef)
+
+	response, err := p.client.Get(ctx, p.getGitUrl(pathGitRefFormat, owner, name, escapePath(branch)), p.getHeaders())
+
+	var result github.Ref
+	if errResponse := handleResponse(ctx, "get branch", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
+
+func (p *Provider) getCommit(ctx context.Context, owner string, name string, sha string) (*github.Commit, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetCommit)
+
+	response, err := p.client.Get(ctx, p.getGitUrl(pathGitCommitFormat, owner, name, sha), p.getHeaders())
+
+	var result github.Commit
+	if errResponse := handleResponse(ctx, "get commit", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
+
+func (p *Provider) createTree(ctx context.Context, owner string, name string, baseTree string, files []repositories.File) (*github.Tree, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointCreateTree)
+
+	body := github.CreateTreeRequest{BaseTree: baseTree, Tree: make([]github.TreeEntry, 0, len(files))}
+	for _, file := range files {
+		body.Tree = append(body.Tree, github.TreeEntry{
+			Path:    file.Path,
+			Mode:    fileModeBlob,
+			Type:    treeEntryBlob,
+			Content: string(file.Content),
+		})
+	}
+	response, err := p.client.Post(ctx, p.getGitUrl(pathGitTreesFormat, owner, name), body, p.getHeaders())
+
+	var result github.Tree
+	if errResponse := handleResponse(ctx, "create tree", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
+
+func (p *Provider) createCommit(ctx context.Context, owner string, name string, request github.CreateCommitRequest) (*github.Commit, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointCreateCommit)
+
+	response, err := p.client.Post(ctx, p.getGitUrl(pathGitCreateCommitFormat, owner, name), request, p.getHeaders())
+
+	var result github.Commit
+	if errResponse := handleResponse(ctx, "create commit", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
+
+func (p *Provider) updateRef(ctx context.Context, owner string, name string, branch string, sha string) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointUpdateRef)
+
+	body := github.UpdateRefRequest{Sha: sha}
+	response, err := p.client.Patch(ctx, p.getGitUrl(pathGitUpdateRefFormat, owner, name, escapePath(branch)), body, p.getHeaders())
+
+	return handleResponse(ctx, "update branch", response, err, nil)
+}
+
+// CommitFiles adds files to branch in a single commit through the git data
+// api. The git data api rejects empty repositories, there the first file goes
+// through the contents api to create the branch and the rest follows in one
+// commit on top of it.
+func (p *Provider) CommitFiles(ctx context.Context, owner string, name string, branch string, message string, files []repositories.File) *github.GithubErrorResponse {
+	if len(files) == 0 {
+		return nil
+	}
+	if branch == "" {
+		repo, err := p.GetRepo(ctx, owner, name)
+		if err != nil {
+			return err
+		}
+		branch = repo.DefaultBranch
+	}
+
+	head, err := p.getRef(ctx, owner, name, branch)
+	if err != nil && (err.StatusCode == http.StatusConflict || err.StatusCode == http.StatusNotFound) {
+		if err := p.PutContents(ctx, owner, name, "", message, files[0]); err != nil {
+			return err
+		}
+		if files = files[1:]; len(files) == 0 {
+			return nil
+		}
+		head, err = p.getRef(ctx, owner, name, branch)
+	}
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.getCommit(ctx, owner, name, head.Object.Sha)
+	if err != nil {
+		return err
+	}
+	tree, err := p.createTree(ctx, owner, name, parent.Tree.Sha, files)
+	if err != nil {
+		return err
+	}
+	commit, err := p.createCommit(ctx, owner, name, github.CreateCommitRequest{
+		Message: message,
+		Tree:    tree.Sha,
+		Parents: []string{parent.Sha},
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.updateRef(ctx, owner, name, branch, commit.Sha)
+}
@@ -0,0 +1,145 @@
+package github_provider
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/domain/repositories"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func mockJson(client *restclient.Client, method string, url string, statusCode int, body string) {
+	client.AddMock(&restclient.Mock{
+		Url:        url,
+		HttpMethod: method,
+		Response: &http.Response{
+			StatusCode: statusCode,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		},
+	})
+}
+
+func TestEscapePath(t *testing.T) {
+	assert.EqualValues(t, ".github/workflows/ci%20build.yml", escapePath(".github/workflows/ci build.yml"))
+}
+
+func TestCommitFilesSingleCommit(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/git/ref/heads/main", http.StatusOK,
+		`{"ref": "refs/heads/main", "object": {"sha": "c1", "type": "commit"}}`)
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/git/commits/c1", http.StatusOK,
+		`{"sha": "c1", "tree": {"sha": "t1"}}`)
+	mockJson(client, http.MethodPost, "https://api.github.com/repos/my-org/my-repo/git/trees", http.StatusCreated, `{"sha": "t2"}`)
+	mockJson(client, http.MethodPost, "https://api.github.com/repos/my-org/my-repo/git/commits", http.StatusCreated,
+		`{"sha": "c2", "tree": {"sha": "t2"}}`)
+	mockJson(client, http.MethodPatch, "https://api.github.com/repos/my-org/my-repo/git/refs/heads/main", http.StatusOK,
+		`{"ref": "refs/heads/main", "object": {"sha": "c2"}}`)
+
+	err := provider.CommitFiles(context.Background(), "my-org", "my-repo", "main", "Add initial files", []repositories.File{
+		{Path: "README.md", Content: []byte("# my-repo")},
+		{Path: "CODEOWNERS", Content: []byte("* @my-org/core")},
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestCommitFilesEmptyRepositorySingleFile(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/git/ref/heads/main", http.StatusConflict,
+		`{"message": "Git Repository is empty."}`)
+	mockJson(client, http.MethodPut, "https://api.github.com/repos/my-org/my-repo/contents/README.md", http.StatusCreated,
+		`{"content": {"path": "README.md"}}`)
+
+	err := provider.CommitFiles(context.Background(), "my-org", "my-repo", "main", "Add initial files", []repositories.File{
+		{Path: "README.md", Content: []byte("# my-repo")},
+	})
+
+	assert.Nil(t, err)
+}
+
+// TestCommitFilesEmptyRepository follows the whole sequence against a fake
+// server since the ref lookup answers differently before and after the first
+// file.
+func TestCommitFilesEmptyRepository(t *testing.T) {
+	var mutex sync.Mutex
+	var calls []string
+	var contents github.PutContentsRequest
+	var tree github.CreateTreeRequest
+	initialized := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/my-org/my-repo/git/ref/heads/main":
+			if !initialized {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"message": "Git Repository is empty."}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"object": {"sha": "c1"}}`))
+		case "PUT /repos/my-org/my-repo/contents/.github/CODEOWNERS":
+			_ = json.NewDecoder(r.Body).Decode(&contents)
+			initialized = true
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{}`))
+		case "GET /repos/my-org/my-repo/git/commits/c1":
+			_, _ = w.Write([]byte(`{"sha": "c1", "tree": {"sha": "t1"}}`))
+		case "POST /repos/my-org/my-repo/git/trees":
+			_ = json.NewDecoder(r.Body).Decode(&tree)
+			_, _ = w.Write([]byte(`{"sha": "t2"}`))
+		case "POST /repos/my-org/my-repo/git/commits":
+			_, _ = w.Write([]byte(`{"sha": "c2"}`))
+		case "PATCH /repos/my-org/my-repo/git/refs/heads/main":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := restclient.NewClient("")
+	provider := New(client, server.URL, "2022-11-28", "abc123")
+
+	err := provider.CommitFiles(context.Background(), "my-org", "my-repo", "main", "Add initial files", []repositories.File{
+		{Path: ".github/CODEOWNERS", Content: []byte("* @my-org/core")},
+		{Path: "README.md", Content: []byte("# my-repo")},
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{
+		"GET /repos/my-org/my-repo/git/ref/heads/main",
+		"PUT /repos/my-org/my-repo/contents/.github/CODEOWNERS",
+		"GET /repos/my-org/my-repo/git/ref/heads/main",
+		"GET /repos/my-org/my-repo/git/commits/c1",
+		"POST /repos/my-org/my-repo/git/trees",
+		"POST /repos/my-org/my-repo/git/commits",
+		"PATCH /repos/my-org/my-repo/git/refs/heads/main",
+	}, calls)
+	assert.EqualValues(t, "KiBAbXktb3JnL2NvcmU=", contents.Content)
+	assert.EqualValues(t, "t1", tree.BaseTree)
+	assert.EqualValues(t, []github.TreeEntry{{Path: "README.md", Mode: "100644", Type: "blob", Content: "# my-repo"}}, tree.Tree)
+}
+
+func TestCommitFilesError(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/git/ref/heads/main", http.StatusForbidden,
+		`{"message": "Resource not accessible by integration"}`)
+
+	err := provider.CommitFiles(context.Background(), "my-org", "my-repo", "main", "Add initial files", []repositories.File{
+		{Path: "README.md", Content: []byte("# my-repo")},
+	})
+
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualValues(t, "Resource not accessible by integration", err.Message)
+}
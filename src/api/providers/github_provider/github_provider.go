@@ -1,38 +1,132 @@
 package github_provider
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"golang-microservices/src/api/domain/clients/restclient"
 	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/utils/logger"
 	"io/ioutil"
-	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 const headerAuthorization = "Authorization"
 const headerAuthorizationFormat = "token %s"
+const headerAccept = "Accept"
+const headerAcceptGithubJson = "application/vnd.github+json"
+const headerApiVersion = "X-GitHub-Api-Version"
+const headerOAuthScopes = "X-OAuth-Scopes"
 
-const urlCreateRepo = "https://api.github.com/user/repos"
+const pathCreateUserRepo = "/user/repos"
+const pathCreateOrgRepoFormat = "/orgs/%s/repos"
+const pathAuthenticatedUser = "/user"
+const pathRepoFormat = "/repos/%s/%s"
+const pathTransferRepoFormat = "/repos/%s/%s/transfer"
+const pathListOrgReposFormat = "/orgs/%s/repos"
+const pathListUserRepos = "/user/repos"
+
+const headerLink = "Link"
+const defaultPerPage = 30
+
+const endpointCreateUserRepo = "POST /user/repos"
+const endpointCreateOrgRepo = "POST /orgs/{org}/repos"
+const endpointGetAuthenticatedUser = "GET /user"
+const endpointGetRepo = "GET /repos/{owner}/{repo}"
+const endpointUpdateRepo = "PATCH /repos/{owner}/{repo}"
+const endpointDeleteRepo = "DELETE /repos/{owner}/{repo}"
+const endpointTransferRepo = "POST /repos/{owner}/{repo}/transfer"
+const endpointListOrgRepos = "GET /orgs/{org}/repos"
+const endpointListUserRepos = "GET /user/repos"
+
+var requiredTokenScopes = []string{"repo", "public_repo"}
+
+type Provider struct {
+	client      *restclient.Client
+	baseUrl     string
+	apiVersion  string
+	accessToken string
+}
+
+func New(client *restclient.Client, baseUrl string, apiVersion string, accessToken string) *Provider {
+	return &Provider{
+		client:      client,
+		baseUrl:     strings.TrimRight(baseUrl, "/"),
+		apiVersion:  apiVersion,
+		accessToken: accessToken,
+	}
+}
+
+func (p *Provider) HasAccessToken() bool {
+	return p.accessToken != ""
+}
 
 func getAuthHeader(accessToken string) string {
 	return fmt.Sprintf(headerAuthorizationFormat, accessToken)
 }
 
-func CreateRepo(accessToken string, request github.CreateRepoRequest) (*github.CreateRepoResponse, *github.GithubErrorResponse) {
+func (p *Provider) getHeaders() http.Header {
 	headers := http.Header{}
-	headers.Set(headerAuthorization, getAuthHeader(accessToken))
+	headers.Set(headerAuthorization, getAuthHeader(p.accessToken))
+	headers.Set(headerAccept, headerAcceptGithubJson)
+	headers.Set(headerApiVersion, p.apiVersion)
+	return headers
+}
+
+func (p *Provider) getUrl(path string) string {
+	return p.baseUrl + path
+}
+
+func (p *Provider) getCreateRepoUrl(org string) string {
+	if org == "" {
+		return p.getUrl(pathCreateUserRepo)
+	}
+	return p.getUrl(fmt.Sprintf(pathCreateOrgRepoFormat, url.PathEscape(org)))
+}
+
+func (p *Provider) getRepoUrl(owner string, name string) string {
+	return p.getUrl(fmt.Sprintf(pathRepoFormat, url.PathEscape(owner), url.PathEscape(name)))
+}
+
+func (p *Provider) getListReposUrl(owner string, visibility string, page int, perPage int) string {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	// without affiliation the user listing includes the repositories of every
+	// org the token account belongs to, not only the ones it owns.
+	if owner == "" {
+		query.Set("affiliation", "owner")
+		if visibility != "" {
+			query.Set("visibility", visibility)
+		}
+		return p.getUrl(pathListUserRepos) + "?" + query.Encode()
+	}
+
+	if visibility != "" {
+		query.Set("type", visibility)
+	}
+	return p.getUrl(fmt.Sprintf(pathListOrgReposFormat, url.PathEscape(owner))) + "?" + query.Encode()
+}
+
+func handleResponse(ctx context.Context, action string, response *http.Response, err error, result interface{}) *github.GithubErrorResponse {
+	log := logger.FromContext(ctx)
 
-	response, err := restclient.Post(urlCreateRepo, request, headers)
 	if err != nil {
-		log.Println("error when trying to create repository in github", err)
-		return nil, &github.GithubErrorResponse{StatusCode: http.StatusInternalServerError, Message: err.Error()}
+		log.Error(fmt.Sprintf("error when trying to %s in github", action), logger.Err(err))
+		return &github.GithubErrorResponse{StatusCode: http.StatusInternalServerError, Message: err.Error()}
 	}
 
 	responseBytes, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		log.Println("error when trying to read bytes from response body", err)
-		return nil, &github.GithubErrorResponse{
+		log.Error("error when trying to read bytes from response body", logger.Err(err))
+		return &github.GithubErrorResponse{
 			StatusCode: http.StatusInternalServerError,
 			Message:    "invalid response body",
 		}
@@ -42,24 +136,172 @@ func CreateRepo(accessToken string, request github.CreateRepoRequest) (*github.C
 	if response.StatusCode > 299 {
 		var errResponse github.GithubErrorResponse
 		if err := json.Unmarshal(responseBytes, &errResponse); err != nil {
-			return nil, &github.GithubErrorResponse{
+			return &github.GithubErrorResponse{
 				StatusCode: http.StatusInternalServerError,
 				Message:    "invalid json response body",
 			}
 		}
 
 		errResponse.StatusCode = response.StatusCode
-		return nil, &errResponse
+		log.Warn(fmt.Sprintf("github rejected request to %s", action),
+			logger.Any("status", response.StatusCode),
+			logger.Any("message", errResponse.Message),
+		)
+		return &errResponse
 	}
 
-	var result github.CreateRepoResponse
-	if err := json.Unmarshal(responseBytes, &result); err != nil {
-		log.Println("error unmarshalling response body", err)
-		return nil, &github.GithubErrorResponse{
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBytes, result); err != nil {
+		log.Error("error unmarshalling response body", logger.Err(err))
+		return &github.GithubErrorResponse{
 			StatusCode: http.StatusInternalServerError,
 			Message:    "error unmarshalling response body",
 		}
 	}
 
+	return nil
+}
+
+func (p *Provider) CreateRepo(ctx context.Context, org string, request github.CreateRepoRequest) (*github.CreateRepoResponse, *github.GithubErrorResponse) {
+	endpoint := endpointCreateUserRepo
+	if org != "" {
+		endpoint = endpointCreateOrgRepo
+	}
+	ctx = metrics.WithEndpoint(ctx, endpoint)
+
+	response, err := p.client.Post(ctx, p.getCreateRepoUrl(org), request, p.getHeaders())
+
+	var result github.CreateRepoResponse
+	if errResponse := handleResponse(ctx, "create repository", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+
+	return &result, nil
+}
+
+func parseScopes(header string) []string {
+	scopes := make([]string, 0)
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func (p *Provider) GetAuthenticatedUser(ctx context.Context) (*github.User, []string, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetAuthenticatedUser)
+
+	response, err := p.client.Get(ctx, p.getUrl(pathAuthenticatedUser), p.getHeaders())
+
+	var result github.User
+	if errResponse := handleResponse(ctx, "get authenticated user", response, err, &result); errResponse != nil {
+		return nil, nil, errResponse
+	}
+
+	return &result, parseScopes(response.Header.Get(headerOAuthScopes)), nil
+}
+
+func (p *Provider) CheckCredentials(ctx context.Context) error {
+	if p.accessToken == "" {
+		return errors.New("github access token is not configured")
+	}
+
+	_, scopes, err := p.GetAuthenticatedUser(ctx)
+	if err != nil {
+		return errors.New("github access token is invalid: " + err.Message)
+	}
+
+	// fine-grained tokens do not report scopes, only classic ones have to carry repo
+	if len(scopes) == 0 {
+		return nil
+	}
+	for _, scope := range scopes {
+		for _, required := range requiredTokenScopes {
+			if scope == required {
+				return nil
+			}
+		}
+	}
+	return errors.New("github access token lacks repo scope, has: " + strings.Join(scopes, ", "))
+}
+
+func (p *Provider) GetRepo(ctx context.Context, owner string, name string) (*github.Repository, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetRepo)
+
+	response, err := p.client.Get(ctx, p.getRepoUrl(owner, name), p.getHeaders())
+
+	var result github.Repository
+	if errResponse := handleResponse(ctx, "get repository", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+
+	return &result, nil
+}
+
+func (p *Provider) UpdateRepo(ctx context.Context, owner string, name string, request github.UpdateRepoRequest) (*github.Repository, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointUpdateRepo)
+
+	response, err := p.client.Patch(ctx, p.getRepoUrl(owner, name), request, p.getHeaders())
+
+	var result github.Repository
+	if errResponse := handleResponse(ctx, "update repository", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+
+	return &result, nil
+}
+
+// TransferRepo moves the repository to another user or organization. GitHub
+// answers 202 and finishes the transfer in the background, the returned
+// repository may still show the previous owner.
+func (p *Provider) TransferRepo(ctx context.Context, owner string, name string, request github.TransferRepoRequest) (*github.Repository, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointTransferRepo)
+
+	transferUrl := p.getUrl(fmt.Sprintf(pathTransferRepoFormat, url.PathEscape(owner), url.PathEscape(name)))
+	response, err := p.client.Post(ctx, transferUrl, request, p.getHeaders())
+
+	var result github.Repository
+	if errResponse := handleResponse(ctx, "transfer repository", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+
 	return &result, nil
 }
+
+func (p *Provider) DeleteRepo(ctx context.Context, owner string, name string) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointDeleteRepo)
+
+	response, err := p.client.Delete(ctx, p.getRepoUrl(owner, name), p.getHeaders())
+
+	return handleResponse(ctx, "delete repository", response, err, nil)
+}
+
+// ListRepos lists the repositories of an org, or the ones owned by the token
+// account when owner is empty, returning the next and last page numbers parsed from the Link header.
+func (p *Provider) ListRepos(ctx context.Context, owner string, visibility string, page int, perPage int) ([]github.Repository, int, int, *github.GithubErrorResponse) {
+	endpoint := endpointListUserRepos
+	if owner != "" {
+		endpoint = endpointListOrgRepos
+	}
+	ctx = metrics.WithEndpoint(ctx, endpoint)
+
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+
+	response, err := p.client.Get(ctx, p.getListReposUrl(owner, visibility, page, perPage), p.getHeaders())
+
+	result := make([]github.Repository, 0)
+	if errResponse := handleResponse(ctx, "list repositories", response, err, &result); errResponse != nil {
+		return nil, 0, 0, errResponse
+	}
+
+	next, last := providers.ParseLinkHeader(response.Header.Get(headerLink))
+	return result, next, last, nil
+}
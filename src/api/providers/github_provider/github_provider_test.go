@@ -1,10 +1,12 @@
 package github_provider
 
 import (
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"golang-microservices/src/api/domain/clients/restclient"
 	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/domain/repositories"
 	"io"
 	"net/http"
 	"os"
@@ -12,22 +14,46 @@ import (
 	"testing"
 )
 
+func newMockedProvider(accessToken string) (*Provider, *restclient.Client) {
+	client, _ := restclient.NewClient("")
+	client.StartMock()
+	return New(client, "https://api.github.com/", "2022-11-28", accessToken), client
+}
+
 func TestGetAuthHeader(t *testing.T) {
 	header := getAuthHeader("abc123")
 	assert.EqualValues(t, "token abc123", header)
 }
 
+func TestGetHeaders(t *testing.T) {
+	provider, _ := newMockedProvider("abc123")
+	headers := provider.getHeaders()
+	assert.EqualValues(t, "token abc123", headers.Get("Authorization"))
+	assert.EqualValues(t, "application/vnd.github+json", headers.Get("Accept"))
+	assert.EqualValues(t, "2022-11-28", headers.Get("X-GitHub-Api-Version"))
+}
+
+func TestGetUrl(t *testing.T) {
+	provider, _ := newMockedProvider("")
+	assert.EqualValues(t, "https://api.github.com/user/repos", provider.getUrl("/user/repos"))
+}
+
+func TestGetCreateRepoUrl(t *testing.T) {
+	provider, _ := newMockedProvider("")
+	assert.EqualValues(t, "https://api.github.com/user/repos", provider.getCreateRepoUrl(""))
+	assert.EqualValues(t, "https://api.github.com/orgs/my-org/repos", provider.getCreateRepoUrl("my-org"))
+}
+
 func TestCreateRepoErrorRestclient(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	provider, client := newMockedProvider("")
 	clientMock := &restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
 		HttpMethod: http.MethodPost,
 		Error:      errors.New("invalid restclient response"),
 	}
-	restclient.AddMock(clientMock)
+	client.AddMock(clientMock)
 
-	response, err := CreateRepo("", github.CreateRepoRequest{})
+	response, err := provider.CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, response)
 	assert.NotNil(t, err)
@@ -37,8 +63,7 @@ func TestCreateRepoErrorRestclient(t *testing.T) {
 }
 
 func TestCreateRepoInvalidResponseBody(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	provider, client := newMockedProvider("")
 
 	invalidBody, _ := os.Open("-;dlfaksd;fasdf")
 	clientMock := &restclient.Mock{
@@ -49,9 +74,9 @@ func TestCreateRepoInvalidResponseBody(t *testing.T) {
 			Body:       invalidBody,
 		},
 	}
-	restclient.AddMock(clientMock)
+	client.AddMock(clientMock)
 
-	response, err := CreateRepo("", github.CreateRepoRequest{})
+	response, err := provider.CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, response)
 	assert.NotNil(t, err)
@@ -61,8 +86,7 @@ func TestCreateRepoInvalidResponseBody(t *testing.T) {
 }
 
 func TestCreateRepoInvalidJsonResponseBody(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	provider, client := newMockedProvider("")
 
 	clientMock := &restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
@@ -72,9 +96,9 @@ func TestCreateRepoInvalidJsonResponseBody(t *testing.T) {
 			Body:       io.NopCloser(strings.NewReader(`{"message": 1}`)),
 		},
 	}
-	restclient.AddMock(clientMock)
+	client.AddMock(clientMock)
 
-	response, err := CreateRepo("", github.CreateRepoRequest{})
+	response, err := provider.CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, response)
 	assert.NotNil(t, err)
@@ -84,8 +108,7 @@ func TestCreateRepoInvalidJsonResponseBody(t *testing.T) {
 }
 
 func TestCreateRepoUnauthorized(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	provider, client := newMockedProvider("")
 
 	clientMock := &restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
@@ -99,9 +122,9 @@ func TestCreateRepoUnauthorized(t *testing.T) {
 			),
 		},
 	}
-	restclient.AddMock(clientMock)
+	client.AddMock(clientMock)
 
-	response, err := CreateRepo("", github.CreateRepoRequest{})
+	response, err := provider.CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, response)
 	assert.NotNil(t, err)
@@ -111,8 +134,7 @@ func TestCreateRepoUnauthorized(t *testing.T) {
 }
 
 func TestCreateRepoInvalidSuccessResponse(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	provider, client := newMockedProvider("")
 
 	clientMock := &restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
@@ -126,9 +148,9 @@ func TestCreateRepoInvalidSuccessResponse(t *testing.T) {
 			),
 		},
 	}
-	restclient.AddMock(clientMock)
+	client.AddMock(clientMock)
 
-	response, err := CreateRepo("", github.CreateRepoRequest{})
+	response, err := provider.CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, response)
 	assert.NotNil(t, err)
@@ -138,8 +160,7 @@ func TestCreateRepoInvalidSuccessResponse(t *testing.T) {
 }
 
 func TestCreateRepoOk(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	provider, client := newMockedProvider("")
 
 	clientMock := &restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
@@ -170,7 +191,7 @@ func TestCreateRepoOk(t *testing.T) {
 			),
 		},
 	}
-	restclient.AddMock(clientMock)
+	client.AddMock(clientMock)
 
 	expectedResponse := &github.CreateRepoResponse{
 		Id:       2304923,
@@ -188,10 +209,251 @@ func TestCreateRepoOk(t *testing.T) {
 			HasPush: false,
 		},
 	}
-	response, err := CreateRepo("", github.CreateRepoRequest{})
+	response, err := provider.CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, response)
 
 	assert.EqualValues(t, expectedResponse, response)
 }
+
+func TestGetAuthenticatedUserUnauthorized(t *testing.T) {
+	provider, client := newMockedProvider("")
+
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Bad credentials"}`)),
+		},
+	})
+
+	user, scopes, err := provider.GetAuthenticatedUser(context.Background())
+
+	assert.Nil(t, user)
+	assert.Nil(t, scopes)
+	assert.EqualValues(t, http.StatusUnauthorized, err.StatusCode)
+	assert.EqualValues(t, "Bad credentials", err.Message)
+}
+
+func TestGetAuthenticatedUserOk(t *testing.T) {
+	provider, client := newMockedProvider("")
+
+	headers := http.Header{}
+	headers.Set("X-OAuth-Scopes", "repo, admin:org,  workflow")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     headers,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 1234, "login": "LeJeksey", "type": "User"}`)),
+		},
+	})
+
+	user, scopes, err := provider.GetAuthenticatedUser(context.Background())
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, &github.User{Id: 1234, Login: "LeJeksey", Type: "User"}, user)
+	assert.EqualValues(t, []string{"repo", "admin:org", "workflow"}, scopes)
+}
+
+func mockAuthenticatedUser(client *restclient.Client, statusCode int, scopes string, body string) {
+	headers := http.Header{}
+	headers.Set("X-OAuth-Scopes", scopes)
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: statusCode,
+			Header:     headers,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		},
+	})
+}
+
+func TestCheckCredentialsMissingToken(t *testing.T) {
+	provider, _ := newMockedProvider("")
+	err := provider.CheckCredentials(context.Background())
+	assert.EqualValues(t, "github access token is not configured", err.Error())
+}
+
+func TestCheckCredentialsInvalidToken(t *testing.T) {
+	provider, client := newMockedProvider("abc123")
+	mockAuthenticatedUser(client, http.StatusUnauthorized, "", `{"message": "Bad credentials"}`)
+
+	err := provider.CheckCredentials(context.Background())
+	assert.EqualValues(t, "github access token is invalid: Bad credentials", err.Error())
+}
+
+func TestCheckCredentialsMissingScope(t *testing.T) {
+	provider, client := newMockedProvider("abc123")
+	mockAuthenticatedUser(client, http.StatusOK, "read:org, gist", `{"login": "LeJeksey"}`)
+
+	err := provider.CheckCredentials(context.Background())
+	assert.EqualValues(t, "github access token lacks repo scope, has: read:org, gist", err.Error())
+}
+
+func TestCheckCredentialsOk(t *testing.T) {
+	provider, client := newMockedProvider("abc123")
+	mockAuthenticatedUser(client, http.StatusOK, "repo, read:org", `{"login": "LeJeksey"}`)
+
+	assert.Nil(t, provider.CheckCredentials(context.Background()))
+}
+
+func TestGetListReposUrl(t *testing.T) {
+	provider, _ := newMockedProvider("")
+	assert.EqualValues(t, "https://api.github.com/user/repos?affiliation=owner&page=1&per_page=30", provider.getListReposUrl("", "", 1, 30))
+	assert.EqualValues(t, "https://api.github.com/user/repos?affiliation=owner&page=2&per_page=10&visibility=private", provider.getListReposUrl("", "private", 2, 10))
+	assert.EqualValues(t, "https://api.github.com/orgs/my-org/repos?page=1&per_page=30&type=public", provider.getListReposUrl("my-org", "public", 1, 30))
+}
+
+const repoJson = `{
+  "id": 2304923,
+  "name": "my-repo",
+  "full_name": "my-org/my-repo",
+  "description": "testing",
+  "private": true,
+  "archived": false,
+  "default_branch": "main",
+  "html_url": "https://github.com/my-org/my-repo",
+  "owner": {"login": "my-org"}
+}`
+
+func TestGetRepoNotFound(t *testing.T) {
+	provider, client := newMockedProvider("")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/missing",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+		},
+	})
+
+	repo, err := provider.GetRepo(context.Background(), "my-org", "missing")
+
+	assert.Nil(t, repo)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+	assert.EqualValues(t, "Not Found", err.Message)
+}
+
+func TestUpdateRepoOk(t *testing.T) {
+	provider, client := newMockedProvider("")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(repoJson)),
+		},
+	})
+
+	description := "testing"
+	repo, err := provider.UpdateRepo(context.Background(), "my-org", "my-repo", github.UpdateRepoRequest{Description: &description})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "testing", repo.Description)
+	assert.EqualValues(t, "main", repo.DefaultBranch)
+}
+
+func TestTransferRepoOk(t *testing.T) {
+	provider, client := newMockedProvider("")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo/transfer",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusAccepted,
+			Body:       io.NopCloser(strings.NewReader(repoJson)),
+		},
+	})
+
+	repo, err := provider.TransferRepo(context.Background(), "my-org", "my-repo", github.TransferRepoRequest{NewOwner: "new-org", TeamIds: []int64{12}})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-repo", repo.Name)
+}
+
+func TestTransferRepoForbidden(t *testing.T) {
+	provider, client := newMockedProvider("")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo/transfer",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "You don't have the permission to create public repositories on new-org"}`)),
+		},
+	})
+
+	repo, err := provider.TransferRepo(context.Background(), "my-org", "my-repo", github.TransferRepoRequest{NewOwner: "new-org"})
+
+	assert.Nil(t, repo)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+}
+
+func TestDeleteRepoOk(t *testing.T) {
+	provider, client := newMockedProvider("")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+			Body:       io.NopCloser(strings.NewReader("")),
+		},
+	})
+
+	assert.Nil(t, provider.DeleteRepo(context.Background(), "my-org", "my-repo"))
+}
+
+func TestRepoProviderListRepos(t *testing.T) {
+	provider, client := newMockedProvider("")
+	headers := http.Header{}
+	headers.Set("Link", `<https://api.github.com/organizations/1/repos?page=3&per_page=1>; rel="next", `+
+		`<https://api.github.com/organizations/1/repos?page=5&per_page=1>; rel="last"`)
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos?page=2&per_page=1",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     headers,
+			Body:       io.NopCloser(strings.NewReader("[" + repoJson + "]")),
+		},
+	})
+
+	page, err := NewRepoProvider(provider).ListRepos(context.Background(), repositories.ListReposOptions{Owner: "my-org", Page: 2, PerPage: 1})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, page.Page)
+	assert.EqualValues(t, 3, page.NextPage)
+	assert.EqualValues(t, 5, page.LastPage)
+	assert.EqualValues(t, []repositories.Repository{{
+		Id:            2304923,
+		Owner:         "my-org",
+		Name:          "my-repo",
+		FullName:      "my-org/my-repo",
+		Description:   "testing",
+		Private:       true,
+		DefaultBranch: "main",
+		HtmlUrl:       "https://github.com/my-org/my-repo",
+		Provider:      "github",
+	}}, page.Repositories)
+}
+
+func TestRepoProviderCreateRepoError(t *testing.T) {
+	provider, client := newMockedProvider("")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Repository creation failed."}`)),
+		},
+	})
+
+	repo, err := NewRepoProvider(provider).CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo", Org: "my-org"})
+
+	assert.Nil(t, repo)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.Status())
+	assert.EqualValues(t, "Repository creation failed.", err.Message())
+}
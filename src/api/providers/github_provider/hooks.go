@@ -0,0 +1,29 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/metrics"
+	"net/url"
+)
+
+const pathHooksFormat = "/repos/%s/%s/hooks"
+
+const endpointCreateHook = "POST /repos/{owner}/{repo}/hooks"
+
+const hookNameWeb = "web"
+const hookVerifySsl = "0"
+
+func (p *Provider) CreateHook(ctx context.Context, owner string, name string, request github.CreateHookRequest) (*github.Hook, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointCreateHook)
+
+	hooksUrl := p.getUrl(fmt.Sprintf(pathHooksFormat, url.PathEscape(owner), url.PathEscape(name)))
+	response, err := p.client.Post(ctx, hooksUrl, request, p.getHeaders())
+
+	var result github.Hook
+	if errResponse := handleResponse(ctx, "create hook", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
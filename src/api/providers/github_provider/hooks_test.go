@@ -0,0 +1,57 @@
+package github_provider
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/domain/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRepoProviderCreateHook(t *testing.T) {
+	var path string
+	var request github.CreateHookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 12345678, "active": true, "events": ["push", "pull_request"]}`))
+	}))
+	defer server.Close()
+
+	client, _ := restclient.NewClient("")
+	provider := NewRepoProvider(New(client, server.URL, "2022-11-28", "abc123")).(*repoProvider)
+
+	id, err := provider.CreateHook(context.Background(), "my-org", "my-repo", repositories.Webhook{
+		Url:         "https://ci.example.com/hooks",
+		ContentType: "json",
+		Events:      []string{"push", "pull_request"},
+		Secret:      "ci-hook",
+	}, "s3cr3t")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 12345678, id)
+	assert.EqualValues(t, "/repos/my-org/my-repo/hooks", path)
+	assert.EqualValues(t, github.CreateHookRequest{
+		Name:   "web",
+		Active: true,
+		Events: []string{"push", "pull_request"},
+		Config: github.HookConfig{Url: "https://ci.example.com/hooks", ContentType: "json", Secret: "s3cr3t", InsecureSsl: "0"},
+	}, request)
+}
+
+func TestCreateHookValidationFailed(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodPost, "https://api.github.com/repos/my-org/my-repo/hooks", http.StatusUnprocessableEntity,
+		`{"message": "Validation Failed", "errors": [{"resource": "Hook", "code": "custom", "message": "Hook already exists on this repository"}]}`)
+
+	hook, err := provider.CreateHook(context.Background(), "my-org", "my-repo", github.CreateHookRequest{Name: "web"})
+
+	assert.Nil(t, hook)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.EqualValues(t, "Validation Failed", err.Message)
+}
@@ -0,0 +1,79 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/metrics"
+	"net/url"
+)
+
+const pathLabelsFormat = "/repos/%s/%s/labels"
+const pathLabelFormat = "/repos/%s/%s/labels/%s"
+const pathTopicsFormat = "/repos/%s/%s/topics"
+
+const endpointListLabels = "GET /repos/{owner}/{repo}/labels"
+const endpointCreateLabel = "POST /repos/{owner}/{repo}/labels"
+const endpointUpdateLabel = "PATCH /repos/{owner}/{repo}/labels/{name}"
+const endpointDeleteLabel = "DELETE /repos/{owner}/{repo}/labels/{name}"
+const endpointReplaceTopics = "PUT /repos/{owner}/{repo}/topics"
+
+const labelsPerPage = 100
+
+// ListLabels walks every page of the repository labels.
+func (p *Provider) ListLabels(ctx context.Context, owner string, name string) ([]github.Label, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointListLabels)
+
+	result := make([]github.Label, 0)
+	for page := 1; ; page++ {
+		labelsUrl := fmt.Sprintf("%s?page=%d&per_page=%d", p.getGitUrl(pathLabelsFormat, owner, name), page, labelsPerPage)
+		response, err := p.client.Get(ctx, labelsUrl, p.getHeaders())
+
+		labels := make([]github.Label, 0)
+		if errResponse := handleResponse(ctx, "list labels", response, err, &labels); errResponse != nil {
+			return nil, errResponse
+		}
+		result = append(result, labels...)
+		if len(labels) < labelsPerPage {
+			return result, nil
+		}
+	}
+}
+
+func (p *Provider) CreateLabel(ctx context.Context, owner string, name string, label github.Label) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointCreateLabel)
+
+	response, err := p.client.Post(ctx, p.getGitUrl(pathLabelsFormat, owner, name), label, p.getHeaders())
+
+	return handleResponse(ctx, "create label", response, err, nil)
+}
+
+func (p *Provider) UpdateLabel(ctx context.Context, owner string, name string, current string, request github.UpdateLabelRequest) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointUpdateLabel)
+
+	response, err := p.client.Patch(ctx, p.getGitUrl(pathLabelFormat, owner, name, url.PathEscape(current)), request, p.getHeaders())
+
+	return handleResponse(ctx, "update label", response, err, nil)
+}
+
+func (p *Provider) DeleteLabel(ctx context.Context, owner string, name string, label string) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointDeleteLabel)
+
+	response, err := p.client.Delete(ctx, p.getGitUrl(pathLabelFormat, owner, name, url.PathEscape(label)), p.getHeaders())
+
+	return handleResponse(ctx, "delete label", response, err, nil)
+}
+
+// ReplaceTopics sets the topics of the repository, replacing every existing
+// one.
+func (p *Provider) ReplaceTopics(ctx context.Context, owner string, name string, topics []string) ([]string, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointReplaceTopics)
+
+	response, err := p.client.Put(ctx, p.getGitUrl(pathTopicsFormat, owner, name), github.Topics{Names: topics}, p.getHeaders())
+
+	var result github.Topics
+	if errResponse := handleResponse(ctx, "replace topics", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return result.Names, nil
+}
@@ -0,0 +1,44 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/github"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestListLabelsAllPages(t *testing.T) {
+	provider, client := newMockedProvider("")
+	page := make([]string, 0, labelsPerPage)
+	for i := 0; i < labelsPerPage; i++ {
+		page = append(page, fmt.Sprintf(`{"name": "label-%d", "color": "ededed"}`, i))
+	}
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/labels?page=1&per_page=100", http.StatusOK,
+		"["+strings.Join(page, ",")+"]")
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/labels?page=2&per_page=100", http.StatusOK,
+		`[{"name": "bug", "color": "d73a4a", "description": "Something isn't working"}]`)
+
+	labels, err := provider.ListLabels(context.Background(), "my-org", "my-repo")
+	assert.Nil(t, err)
+	assert.EqualValues(t, labelsPerPage+1, len(labels))
+	assert.EqualValues(t, github.Label{Name: "bug", Color: "d73a4a", Description: "Something isn't working"}, labels[labelsPerPage])
+}
+
+func TestDeleteLabelEscapesName(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodDelete, "https://api.github.com/repos/my-org/my-repo/labels/good%20first%20issue", http.StatusNoContent, "")
+
+	assert.Nil(t, provider.DeleteLabel(context.Background(), "my-org", "my-repo", "good first issue"))
+}
+
+func TestReplaceTopics(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodPut, "https://api.github.com/repos/my-org/my-repo/topics", http.StatusOK, `{"names": ["go", "payments"]}`)
+
+	topics, err := NewRepoProvider(provider).(*repoProvider).SetTopics(context.Background(), "my-org", "my-repo", []string{"go", "payments"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go", "payments"}, topics)
+}
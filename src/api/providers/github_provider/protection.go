@@ -0,0 +1,80 @@
+package github_provider
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/metrics"
+	"net/url"
+	"strconv"
+)
+
+const pathBranchProtectionFormat = "/repos/%s/%s/branches/%s/protection"
+const pathRulesetsFormat = "/repos/%s/%s/rulesets"
+const pathRulesetFormat = "/repos/%s/%s/rulesets/%s"
+
+const endpointGetBranchProtection = "GET /repos/{owner}/{repo}/branches/{branch}/protection"
+const endpointUpdateBranchProtection = "PUT /repos/{owner}/{repo}/branches/{branch}/protection"
+const endpointListRulesets = "GET /repos/{owner}/{repo}/rulesets"
+const endpointGetRuleset = "GET /repos/{owner}/{repo}/rulesets/{ruleset_id}"
+const endpointCreateRuleset = "POST /repos/{owner}/{repo}/rulesets"
+const endpointUpdateRuleset = "PUT /repos/{owner}/{repo}/rulesets/{ruleset_id}"
+
+func (p *Provider) GetBranchProtection(ctx context.Context, owner string, name string, branch string) (*github.BranchProtection, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetBranchProtection)
+
+	response, err := p.client.Get(ctx, p.getGitUrl(pathBranchProtectionFormat, owner, name, url.PathEscape(branch)), p.getHeaders())
+
+	var result github.BranchProtection
+	if errResponse := handleResponse(ctx, "get branch protection", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
+
+func (p *Provider) UpdateBranchProtection(ctx context.Context, owner string, name string, branch string, request github.UpdateBranchProtectionRequest) *github.GithubErrorResponse {
+	ctx = metrics.WithEndpoint(ctx, endpointUpdateBranchProtection)
+
+	response, err := p.client.Put(ctx, p.getGitUrl(pathBranchProtectionFormat, owner, name, url.PathEscape(branch)), request, p.getHeaders())
+
+	return handleResponse(ctx, "update branch protection", response, err, nil)
+}
+
+// ListRulesets returns the rulesets of the repository itself, without the
+// ones inherited from the organization and without their rules.
+func (p *Provider) ListRulesets(ctx context.Context, owner string, name string) ([]github.Ruleset, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointListRulesets)
+
+	response, err := p.client.Get(ctx, p.getGitUrl(pathRulesetsFormat, owner, name)+"?includes_parents=false", p.getHeaders())
+
+	result := make([]github.Ruleset, 0)
+	if errResponse := handleResponse(ctx, "list rulesets", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return result, nil
+}
+
+func (p *Provider) GetRuleset(ctx context.Context, owner string, name string, id int64) (*github.Ruleset, *github.GithubErrorResponse) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetRuleset)
+
+	response, err := p.client.Get(ctx, p.getGitUrl(pathRulesetFormat, owner, name, strconv.FormatInt(id, 10)), p.getHeaders())
+
+	var result github.Ruleset
+	if errResponse := handleResponse(ctx, "get ruleset", response, err, &result); errResponse != nil {
+		return nil, errResponse
+	}
+	return &result, nil
+}
+
+// SaveRuleset creates ruleset, or replaces the existing one when it has an id.
+func (p *Provider) SaveRuleset(ctx context.Context, owner string, name string, ruleset github.Ruleset) *github.GithubErrorResponse {
+	if ruleset.Id == 0 {
+		ctx = metrics.WithEndpoint(ctx, endpointCreateRuleset)
+		response, err := p.client.Post(ctx, p.getGitUrl(pathRulesetsFormat, owner, name), ruleset, p.getHeaders())
+		return handleResponse(ctx, "create ruleset", response, err, nil)
+	}
+
+	ctx = metrics.WithEndpoint(ctx, endpointUpdateRuleset)
+	rulesetUrl := p.getGitUrl(pathRulesetFormat, owner, name, strconv.FormatInt(ruleset.Id, 10))
+	response, err := p.client.Put(ctx, rulesetUrl, ruleset, p.getHeaders())
+	return handleResponse(ctx, "update ruleset", response, err, nil)
+}
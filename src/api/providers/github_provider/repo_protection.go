@@ -0,0 +1,215 @@
+package github_provider
+
+import (
+	"context"
+	"encoding/json"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+)
+
+const rulesetTargetBranch = "branch"
+const rulesetEnforcementActive = "active"
+const rulesetRefPrefix = "refs/heads/"
+
+const ruleTypePullRequest = "pull_request"
+const ruleTypeStatusChecks = "required_status_checks"
+const ruleTypeLinearHistory = "required_linear_history"
+const ruleTypeNonFastForward = "non_fast_forward"
+const ruleTypeDeletion = "deletion"
+
+// repositoryRoleAdmin is the id of the built in admin role, it is the bypass
+// actor of rulesets that do not enforce admins.
+const repositoryRoleAdmin = 5
+const actorTypeRepositoryRole = "RepositoryRole"
+const bypassModeAlways = "always"
+
+func toUpdateBranchProtectionRequest(protection repositories.BranchProtection) github.UpdateBranchProtectionRequest {
+	request := github.UpdateBranchProtectionRequest{
+		EnforceAdmins:         protection.EnforceAdmins,
+		RequiredLinearHistory: protection.RequireLinearHistory,
+		AllowForcePushes:      !protection.BlockForcePushes,
+		AllowDeletions:        !protection.BlockDeletions,
+	}
+	if len(protection.RequiredStatusChecks) > 0 {
+		request.RequiredStatusChecks = &github.RequiredStatusChecks{
+			Strict:   protection.StrictStatusChecks,
+			Contexts: protection.RequiredStatusChecks,
+		}
+	}
+	if protection.RequiredReviews > 0 || protection.DismissStaleReviews || protection.RequireCodeOwnerReviews {
+		request.RequiredPullRequestReviews = &github.RequiredPullRequestReviews{
+			DismissStaleReviews:          protection.DismissStaleReviews,
+			RequireCodeOwnerReviews:      protection.RequireCodeOwnerReviews,
+			RequiredApprovingReviewCount: protection.RequiredReviews,
+		}
+	}
+	return request
+}
+
+func fromBranchProtection(protection *github.BranchProtection) *repositories.BranchProtection {
+	result := &repositories.BranchProtection{
+		EnforceAdmins:        protection.EnforceAdmins.Enabled,
+		RequireLinearHistory: protection.RequiredLinearHistory.Enabled,
+		BlockForcePushes:     !protection.AllowForcePushes.Enabled,
+		BlockDeletions:       !protection.AllowDeletions.Enabled,
+	}
+	if checks := protection.RequiredStatusChecks; checks != nil {
+		result.RequiredStatusChecks = checks.Contexts
+		result.StrictStatusChecks = checks.Strict
+	}
+	if reviews := protection.RequiredPullRequestReviews; reviews != nil {
+		result.RequiredReviews = reviews.RequiredApprovingReviewCount
+		result.DismissStaleReviews = reviews.DismissStaleReviews
+		result.RequireCodeOwnerReviews = reviews.RequireCodeOwnerReviews
+	}
+	return result
+}
+
+func newRule(ruleType string, parameters interface{}) github.RulesetRule {
+	rule := github.RulesetRule{Type: ruleType}
+	if parameters != nil {
+		rule.Parameters, _ = json.Marshal(parameters)
+	}
+	return rule
+}
+
+func toRuleset(policy repositories.ProtectionPolicy, branch string) github.Ruleset {
+	ruleset := github.Ruleset{
+		Name:         policy.Name,
+		Target:       rulesetTargetBranch,
+		Enforcement:  rulesetEnforcementActive,
+		BypassActors: make([]github.RulesetBypassActor, 0),
+		Conditions: &github.RulesetConditions{
+			RefName: github.RulesetRefName{Include: []string{rulesetRefPrefix + branch}, Exclude: make([]string, 0)},
+		},
+		Rules: make([]github.RulesetRule, 0),
+	}
+	if !policy.EnforceAdmins {
+		ruleset.BypassActors = append(ruleset.BypassActors, github.RulesetBypassActor{
+			ActorId:    repositoryRoleAdmin,
+			ActorType:  actorTypeRepositoryRole,
+			BypassMode: bypassModeAlways,
+		})
+	}
+	if policy.RequiredReviews > 0 || policy.DismissStaleReviews || policy.RequireCodeOwnerReviews {
+		ruleset.Rules = append(ruleset.Rules, newRule(ruleTypePullRequest, github.PullRequestRuleParameters{
+			RequiredApprovingReviewCount: policy.RequiredReviews,
+			DismissStaleReviewsOnPush:    policy.DismissStaleReviews,
+			RequireCodeOwnerReview:       policy.RequireCodeOwnerReviews,
+		}))
+	}
+	if len(policy.RequiredStatusChecks) > 0 {
+		parameters := github.StatusChecksRuleParameters{StrictRequiredStatusChecksPolicy: policy.StrictStatusChecks}
+		for _, check := range policy.RequiredStatusChecks {
+			parameters.RequiredStatusChecks = append(parameters.RequiredStatusChecks, github.StatusCheck{Context: check})
+		}
+		ruleset.Rules = append(ruleset.Rules, newRule(ruleTypeStatusChecks, parameters))
+	}
+	if policy.RequireLinearHistory {
+		ruleset.Rules = append(ruleset.Rules, newRule(ruleTypeLinearHistory, nil))
+	}
+	if policy.BlockForcePushes {
+		ruleset.Rules = append(ruleset.Rules, newRule(ruleTypeNonFastForward, nil))
+	}
+	if policy.BlockDeletions {
+		ruleset.Rules = append(ruleset.Rules, newRule(ruleTypeDeletion, nil))
+	}
+	return ruleset
+}
+
+func fromRuleset(ruleset *github.Ruleset) *repositories.BranchProtection {
+	result := &repositories.BranchProtection{EnforceAdmins: len(ruleset.BypassActors) == 0}
+	for _, rule := range ruleset.Rules {
+		switch rule.Type {
+		case ruleTypePullRequest:
+			var parameters github.PullRequestRuleParameters
+			_ = json.Unmarshal(rule.Parameters, &parameters)
+			result.RequiredReviews = parameters.RequiredApprovingReviewCount
+			result.DismissStaleReviews = parameters.DismissStaleReviewsOnPush
+			result.RequireCodeOwnerReviews = parameters.RequireCodeOwnerReview
+		case ruleTypeStatusChecks:
+			var parameters github.StatusChecksRuleParameters
+			_ = json.Unmarshal(rule.Parameters, &parameters)
+			result.StrictStatusChecks = parameters.StrictRequiredStatusChecksPolicy
+			for _, check := range parameters.RequiredStatusChecks {
+				result.RequiredStatusChecks = append(result.RequiredStatusChecks, check.Context)
+			}
+		case ruleTypeLinearHistory:
+			result.RequireLinearHistory = true
+		case ruleTypeNonFastForward:
+			result.BlockForcePushes = true
+		case ruleTypeDeletion:
+			result.BlockDeletions = true
+		}
+	}
+	return result
+}
+
+// findRuleset looks the ruleset of the policy up by name, it returns nil when
+// the repository has none.
+func (p *repoProvider) findRuleset(ctx context.Context, owner string, name string, policy string) (*github.Ruleset, errors.ApiError) {
+	rulesets, err := p.github.ListRulesets(ctx, owner, name)
+	if err != nil {
+		return nil, toApiError(err)
+	}
+	for _, ruleset := range rulesets {
+		if ruleset.Name == policy {
+			result, err := p.github.GetRuleset(ctx, owner, name, ruleset.Id)
+			if err != nil {
+				return nil, toApiError(err)
+			}
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetBranchProtection reads the live protection in the form the policy uses,
+// an unprotected branch gives the zero protection.
+func (p *repoProvider) GetBranchProtection(ctx context.Context, owner string, name string, branch string, policy repositories.ProtectionPolicy) (*repositories.BranchProtection, errors.ApiError) {
+	if policy.Ruleset {
+		ruleset, err := p.findRuleset(ctx, owner, name, policy.Name)
+		if err != nil {
+			return nil, err
+		}
+		if ruleset == nil {
+			return &repositories.BranchProtection{}, nil
+		}
+		return fromRuleset(ruleset), nil
+	}
+
+	protection, err := p.github.GetBranchProtection(ctx, owner, name, branch)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound && err.Message == "Branch not protected" {
+			return &repositories.BranchProtection{}, nil
+		}
+		return nil, toApiError(err)
+	}
+	return fromBranchProtection(protection), nil
+}
+
+// SetBranchProtection replaces the protection of branch, rulesets are
+// matched by policy name so applying a policy twice updates it in place.
+func (p *repoProvider) SetBranchProtection(ctx context.Context, owner string, name string, branch string, policy repositories.ProtectionPolicy) errors.ApiError {
+	if policy.Ruleset {
+		existing, err := p.findRuleset(ctx, owner, name, policy.Name)
+		if err != nil {
+			return err
+		}
+		ruleset := toRuleset(policy, branch)
+		if existing != nil {
+			ruleset.Id = existing.Id
+		}
+		if err := p.github.SaveRuleset(ctx, owner, name, ruleset); err != nil {
+			return toApiError(err)
+		}
+		return nil
+	}
+
+	if err := p.github.UpdateBranchProtection(ctx, owner, name, branch, toUpdateBranchProtectionRequest(policy.BranchProtection)); err != nil {
+		return toApiError(err)
+	}
+	return nil
+}
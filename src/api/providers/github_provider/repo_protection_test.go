@@ -0,0 +1,121 @@
+package github_provider
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/domain/repositories"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var strictPolicy = repositories.ProtectionPolicy{
+	Name: "strict",
+	BranchProtection: repositories.BranchProtection{
+		RequiredReviews:      2,
+		DismissStaleReviews:  true,
+		RequiredStatusChecks: []string{"test"},
+		StrictStatusChecks:   true,
+		EnforceAdmins:        true,
+		BlockForcePushes:     true,
+		BlockDeletions:       true,
+	},
+}
+
+func TestSetBranchProtection(t *testing.T) {
+	var path string
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := restclient.NewClient("")
+	provider := NewRepoProvider(New(client, server.URL, "2022-11-28", "abc123")).(*repoProvider)
+
+	assert.Nil(t, provider.SetBranchProtection(context.Background(), "my-org", "my-repo", "main", strictPolicy))
+	assert.EqualValues(t, "/repos/my-org/my-repo/branches/main/protection", path)
+	assert.EqualValues(t, map[string]interface{}{
+		"required_status_checks": map[string]interface{}{"strict": true, "contexts": []interface{}{"test"}},
+		"enforce_admins":         true,
+		"required_pull_request_reviews": map[string]interface{}{
+			"dismiss_stale_reviews":           true,
+			"require_code_owner_reviews":      false,
+			"required_approving_review_count": float64(2),
+		},
+		"restrictions":            nil,
+		"required_linear_history": false,
+		"allow_force_pushes":      false,
+		"allow_deletions":         false,
+	}, request)
+}
+
+func TestGetBranchProtection(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/branches/main/protection", http.StatusOK, `{
+		"required_status_checks": {"strict": true, "contexts": ["test"]},
+		"enforce_admins": {"enabled": true},
+		"required_pull_request_reviews": {"dismiss_stale_reviews": true, "required_approving_review_count": 1},
+		"required_linear_history": {"enabled": false},
+		"allow_force_pushes": {"enabled": true},
+		"allow_deletions": {"enabled": false}
+	}`)
+
+	live, err := NewRepoProvider(provider).(*repoProvider).GetBranchProtection(context.Background(), "my-org", "my-repo", "main", strictPolicy)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []repositories.ProtectionDrift{
+		{Field: "required_reviews", Expected: 2, Actual: 1},
+		{Field: "block_force_pushes", Expected: true, Actual: false},
+	}, strictPolicy.Diff(*live))
+}
+
+func TestGetBranchProtectionNotProtected(t *testing.T) {
+	provider, client := newMockedProvider("")
+	mockJson(client, http.MethodGet, "https://api.github.com/repos/my-org/my-repo/branches/main/protection", http.StatusNotFound,
+		`{"message": "Branch not protected"}`)
+
+	live, err := NewRepoProvider(provider).(*repoProvider).GetBranchProtection(context.Background(), "my-org", "my-repo", "main", strictPolicy)
+	assert.Nil(t, err)
+	assert.EqualValues(t, &repositories.BranchProtection{}, live)
+}
+
+func TestSetBranchProtectionRulesetUpdatesExisting(t *testing.T) {
+	policy := strictPolicy
+	policy.Ruleset = true
+	policy.EnforceAdmins = false
+
+	var method, path string
+	var saved github.Ruleset
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/my-org/my-repo/rulesets":
+			_, _ = w.Write([]byte(`[{"id": 7, "name": "other"}, {"id": 9, "name": "strict"}]`))
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"id": 9, "name": "strict", "rules": []}`))
+		default:
+			method, path = r.Method, r.URL.Path
+			body, _ := ioutil.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &saved)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := restclient.NewClient("")
+	provider := NewRepoProvider(New(client, server.URL, "2022-11-28", "abc123")).(*repoProvider)
+
+	assert.Nil(t, provider.SetBranchProtection(context.Background(), "my-org", "my-repo", "main", policy))
+	assert.EqualValues(t, http.MethodPut, method)
+	assert.EqualValues(t, "/repos/my-org/my-repo/rulesets/9", path)
+	assert.EqualValues(t, []string{"refs/heads/main"}, saved.Conditions.RefName.Include)
+	assert.EqualValues(t, 1, len(saved.BypassActors))
+
+	live := fromRuleset(&saved)
+	assert.EqualValues(t, []repositories.ProtectionDrift{}, policy.Diff(*live))
+}
@@ -0,0 +1,268 @@
+package github_provider
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/github"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/utils/errors"
+	"sort"
+)
+
+type repoProvider struct {
+	github *Provider
+}
+
+// NewRepoProvider adapts the GitHub client to the provider neutral
+// providers.RepoProvider interface.
+func NewRepoProvider(provider *Provider) providers.RepoProvider {
+	return &repoProvider{github: provider}
+}
+
+func toApiError(err *github.GithubErrorResponse) errors.ApiError {
+	return errors.NewApiError(err.StatusCode, err.Message)
+}
+
+func toRepository(repo *github.Repository) *repositories.Repository {
+	return &repositories.Repository{
+		Id:            repo.Id,
+		Owner:         repo.Owner.Login,
+		Name:          repo.Name,
+		FullName:      repo.FullName,
+		Description:   repo.Description,
+		Homepage:      repo.Homepage,
+		Private:       repo.Private,
+		Archived:      repo.Archived,
+		DefaultBranch: repo.DefaultBranch,
+		HtmlUrl:       repo.HtmlUrl,
+		Topics:        repo.Topics,
+		Provider:      providers.Github,
+	}
+}
+
+func (p *repoProvider) CheckCredentials(ctx context.Context) error {
+	return p.github.CheckCredentials(ctx)
+}
+
+func (p *repoProvider) AccountLogin(ctx context.Context) (string, errors.ApiError) {
+	user, _, err := p.github.GetAuthenticatedUser(ctx)
+	if err != nil {
+		return "", toApiError(err)
+	}
+	return user.Login, nil
+}
+
+func (p *repoProvider) CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	response, err := p.github.CreateRepo(ctx, request.Org, github.CreateRepoRequest{
+		Name:        request.Name,
+		Description: request.Description,
+		Private:     true,
+		AutoInit:    request.AutoInit,
+	})
+	if err != nil {
+		return nil, toApiError(err)
+	}
+
+	return &repositories.Repository{
+		Id:            response.Id,
+		Owner:         response.Owner.Login,
+		Name:          response.Name,
+		FullName:      response.FullName,
+		Description:   request.Description,
+		Private:       true,
+		DefaultBranch: response.DefaultBranch,
+		Provider:      providers.Github,
+	}, nil
+}
+
+func (p *repoProvider) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	repo, err := p.github.GetRepo(ctx, owner, name)
+	if err != nil {
+		return nil, toApiError(err)
+	}
+	return toRepository(repo), nil
+}
+
+func (p *repoProvider) UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	repo, err := p.github.UpdateRepo(ctx, owner, name, github.UpdateRepoRequest{
+		Name:          request.Name,
+		Description:   request.Description,
+		Homepage:      request.Homepage,
+		Private:       request.Private,
+		Archived:      request.Archived,
+		DefaultBranch: request.DefaultBranch,
+	})
+	if err != nil {
+		return nil, toApiError(err)
+	}
+	return toRepository(repo), nil
+}
+
+func (p *repoProvider) TransferRepo(ctx context.Context, owner string, name string, request repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	repo, err := p.github.TransferRepo(ctx, owner, name, github.TransferRepoRequest{
+		NewOwner: request.NewOwner,
+		NewName:  request.NewName,
+		TeamIds:  request.TeamIds,
+	})
+	if err != nil {
+		return nil, toApiError(err)
+	}
+	return toRepository(repo), nil
+}
+
+func (p *repoProvider) DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError {
+	if err := p.github.DeleteRepo(ctx, owner, name); err != nil {
+		return toApiError(err)
+	}
+	return nil
+}
+
+func (p *repoProvider) CommitFiles(ctx context.Context, owner string, name string, branch string, message string, files []repositories.File) errors.ApiError {
+	if err := p.github.CommitFiles(ctx, owner, name, branch, message, files); err != nil {
+		return toApiError(err)
+	}
+	return nil
+}
+
+func (p *repoProvider) CreateHook(ctx context.Context, owner string, name string, hook repositories.Webhook, secret string) (int64, errors.ApiError) {
+	result, err := p.github.CreateHook(ctx, owner, name, github.CreateHookRequest{
+		Name:   hookNameWeb,
+		Active: true,
+		Events: hook.Events,
+		Config: github.HookConfig{
+			Url:         hook.Url,
+			ContentType: hook.ContentType,
+			Secret:      secret,
+			InsecureSsl: hookVerifySsl,
+		},
+	})
+	if err != nil {
+		return 0, toApiError(err)
+	}
+	return result.Id, nil
+}
+
+// SetActionsSecrets fetches the repository public key once and seals every
+// value with it.
+func (p *repoProvider) SetActionsSecrets(ctx context.Context, owner string, name string, values map[string]string) errors.ApiError {
+	key, err := p.github.GetActionsPublicKey(ctx, owner, name)
+	if err != nil {
+		return toApiError(err)
+	}
+
+	names := make([]string, 0, len(values))
+	for secretName := range values {
+		names = append(names, secretName)
+	}
+	sort.Strings(names)
+	for _, secretName := range names {
+		if err := p.github.PutActionsSecret(ctx, owner, name, key, secretName, values[secretName]); err != nil {
+			return toApiError(err)
+		}
+	}
+	return nil
+}
+
+func (p *repoProvider) SetActionsVariables(ctx context.Context, owner string, name string, variables []repositories.ActionsVariable) errors.ApiError {
+	for _, variable := range variables {
+		if err := p.github.CreateActionsVariable(ctx, owner, name, github.ActionsVariable{Name: variable.Name, Value: variable.Value}); err != nil {
+			return toApiError(err)
+		}
+	}
+	return nil
+}
+
+func (p *repoProvider) ListTeams(ctx context.Context, owner string, name string) ([]repositories.TeamAccess, errors.ApiError) {
+	teams, err := p.github.ListRepoTeams(ctx, owner, name)
+	if err != nil {
+		return nil, toApiError(err)
+	}
+	result := make([]repositories.TeamAccess, 0, len(teams))
+	for _, team := range teams {
+		result = append(result, repositories.TeamAccess{Slug: team.Slug, Permission: team.Permission})
+	}
+	return result, nil
+}
+
+// AddTeam looks the team up in the repository owner, teams only exist in
+// organizations.
+func (p *repoProvider) AddTeam(ctx context.Context, owner string, name string, slug string, permission string) errors.ApiError {
+	if err := p.github.AddTeamRepo(ctx, owner, slug, owner, name, permission); err != nil {
+		return toApiError(err)
+	}
+	return nil
+}
+
+func (p *repoProvider) AddCollaborator(ctx context.Context, owner string, name string, username string, permission string) (int64, errors.ApiError) {
+	invitation, err := p.github.AddCollaborator(ctx, owner, name, username, permission)
+	if err != nil {
+		return 0, toApiError(err)
+	}
+	if invitation == nil {
+		return 0, nil
+	}
+	return invitation.Id, nil
+}
+
+func (p *repoProvider) ListLabels(ctx context.Context, owner string, name string) ([]repositories.Label, errors.ApiError) {
+	labels, err := p.github.ListLabels(ctx, owner, name)
+	if err != nil {
+		return nil, toApiError(err)
+	}
+	result := make([]repositories.Label, 0, len(labels))
+	for _, label := range labels {
+		result = append(result, repositories.Label{Name: label.Name, Color: label.Color, Description: label.Description})
+	}
+	return result, nil
+}
+
+func (p *repoProvider) CreateLabel(ctx context.Context, owner string, name string, label repositories.Label) errors.ApiError {
+	if err := p.github.CreateLabel(ctx, owner, name, github.Label{Name: label.Name, Color: label.Color, Description: label.Description}); err != nil {
+		return toApiError(err)
+	}
+	return nil
+}
+
+func (p *repoProvider) UpdateLabel(ctx context.Context, owner string, name string, current string, label repositories.Label) errors.ApiError {
+	request := github.UpdateLabelRequest{NewName: label.Name, Color: label.Color, Description: label.Description}
+	if err := p.github.UpdateLabel(ctx, owner, name, current, request); err != nil {
+		return toApiError(err)
+	}
+	return nil
+}
+
+func (p *repoProvider) DeleteLabel(ctx context.Context, owner string, name string, label string) errors.ApiError {
+	if err := p.github.DeleteLabel(ctx, owner, name, label); err != nil {
+		return toApiError(err)
+	}
+	return nil
+}
+
+func (p *repoProvider) SetTopics(ctx context.Context, owner string, name string, topics []string) ([]string, errors.ApiError) {
+	result, err := p.github.ReplaceTopics(ctx, owner, name, topics)
+	if err != nil {
+		return nil, toApiError(err)
+	}
+	return result, nil
+}
+
+func (p *repoProvider) ListRepos(ctx context.Context, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	repos, next, last, err := p.github.ListRepos(ctx, options.Owner, options.Visibility, options.Page, options.PerPage)
+	if err != nil {
+		return nil, toApiError(err)
+	}
+
+	result := repositories.RepositoryPage{
+		Repositories: make([]repositories.Repository, 0, len(repos)),
+		Page:         options.Page,
+		NextPage:     next,
+		LastPage:     last,
+	}
+	if result.Page <= 0 {
+		result.Page = 1
+	}
+	for i := range repos {
+		result.Repositories = append(result.Repositories, *toRepository(&repos[i]))
+	}
+	return &result, nil
+}
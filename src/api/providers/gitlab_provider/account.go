@@ -0,0 +1,20 @@
+package gitlab_provider
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/gitlab"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/utils/errors"
+)
+
+// AccountLogin returns the GitLab user owning the access token.
+func (p *Provider) AccountLogin(ctx context.Context) (string, errors.ApiError) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetAuthenticatedUser)
+	response, err := p.client.Get(ctx, p.getUrl(pathAuthenticatedUser), p.getHeaders())
+
+	var user gitlab.User
+	if apiErr := handleResponse(ctx, "get authenticated user", response, err, &user); apiErr != nil {
+		return "", apiErr
+	}
+	return user.Username, nil
+}
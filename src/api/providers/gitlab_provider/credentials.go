@@ -0,0 +1,20 @@
+package gitlab_provider
+
+import (
+	"context"
+	"errors"
+)
+
+const pathAuthenticatedUser = "/api/v4/user"
+const endpointGetAuthenticatedUser = "GET /api/v4/user"
+
+func (p *Provider) CheckCredentials(ctx context.Context) error {
+	if p.accessToken == "" {
+		return errors.New("gitlab access token is not configured")
+	}
+
+	if _, apiErr := p.AccountLogin(ctx); apiErr != nil {
+		return errors.New("gitlab access token is invalid: " + apiErr.Message())
+	}
+	return nil
+}
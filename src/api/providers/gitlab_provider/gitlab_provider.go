@@ -0,0 +1,346 @@
+package gitlab_provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/gitlab"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const headerPrivateToken = "Private-Token"
+const headerNextPage = "X-Next-Page"
+const headerTotalPages = "X-Total-Pages"
+const headerLink = "Link"
+
+const pathProjects = "/api/v4/projects"
+const pathProjectFormat = "/api/v4/projects/%s"
+const pathArchiveProjectFormat = "/api/v4/projects/%s/archive"
+const pathUnarchiveProjectFormat = "/api/v4/projects/%s/unarchive"
+const pathTransferProjectFormat = "/api/v4/projects/%s/transfer"
+const pathGroupProjectsFormat = "/api/v4/groups/%s/projects"
+const pathNamespaceFormat = "/api/v4/namespaces/%s"
+
+const endpointCreateProject = "POST /api/v4/projects"
+const endpointGetProject = "GET /api/v4/projects/{id}"
+const endpointUpdateProject = "PUT /api/v4/projects/{id}"
+const endpointArchiveProject = "POST /api/v4/projects/{id}/archive"
+const endpointUnarchiveProject = "POST /api/v4/projects/{id}/unarchive"
+const endpointDeleteProject = "DELETE /api/v4/projects/{id}"
+const endpointTransferProject = "PUT /api/v4/projects/{id}/transfer"
+const endpointListProjects = "GET /api/v4/projects"
+const endpointListGroupProjects = "GET /api/v4/groups/{id}/projects"
+const endpointGetNamespace = "GET /api/v4/namespaces/{id}"
+
+const defaultPerPage = 30
+
+type Provider struct {
+	client      *restclient.Client
+	baseUrl     string
+	accessToken string
+}
+
+// New builds a GitLab provider, baseUrl is the instance root such as
+// https://gitlab.com without the /api/v4 suffix.
+func New(client *restclient.Client, baseUrl string, accessToken string) *Provider {
+	return &Provider{
+		client:      client,
+		baseUrl:     strings.TrimRight(baseUrl, "/"),
+		accessToken: accessToken,
+	}
+}
+
+func (p *Provider) getHeaders() http.Header {
+	headers := http.Header{}
+	headers.Set(headerPrivateToken, p.accessToken)
+	return headers
+}
+
+func (p *Provider) getUrl(path string) string {
+	return p.baseUrl + path
+}
+
+// projectId is either the numeric id or the url encoded full path, GitLab
+// accepts both wherever a project id is expected.
+func projectId(owner string, name string) string {
+	if owner == "" {
+		return url.PathEscape(name)
+	}
+	return url.PathEscape(owner + "/" + name)
+}
+
+func visibilityOf(private bool) string {
+	if private {
+		return providers.VisibilityPrivate
+	}
+	return providers.VisibilityPublic
+}
+
+func toRepository(project *gitlab.Project) *repositories.Repository {
+	return &repositories.Repository{
+		Id:            project.Id,
+		Owner:         project.Namespace.FullPath,
+		Name:          project.Path,
+		FullName:      project.PathWithNamespace,
+		Description:   project.Description,
+		Private:       project.Visibility != providers.VisibilityPublic,
+		Archived:      project.Archived,
+		DefaultBranch: project.DefaultBranch,
+		HtmlUrl:       project.WebUrl,
+		Topics:        project.Topics,
+		Provider:      providers.Gitlab,
+	}
+}
+
+func handleResponse(ctx context.Context, action string, response *http.Response, err error, result interface{}) errors.ApiError {
+	log := logger.FromContext(ctx)
+
+	if err != nil {
+		log.Error(fmt.Sprintf("error when trying to %s in gitlab", action), logger.Err(err))
+		return errors.NewInternalServerError(err.Error())
+	}
+
+	responseBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		log.Error("error when trying to read bytes from response body", logger.Err(err))
+		return errors.NewInternalServerError("invalid response body")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 {
+		var errResponse gitlab.ErrorResponse
+		if err := json.Unmarshal(responseBytes, &errResponse); err != nil {
+			return errors.NewInternalServerError("invalid json response body")
+		}
+
+		log.Warn(fmt.Sprintf("gitlab rejected request to %s", action),
+			logger.Any("status", response.StatusCode),
+			logger.Any("message", errResponse.Text()),
+		)
+		return errors.NewApiError(response.StatusCode, errResponse.Text())
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBytes, result); err != nil {
+		log.Error("error unmarshalling response body", logger.Err(err))
+		return errors.NewInternalServerError("error unmarshalling response body")
+	}
+
+	return nil
+}
+
+func (p *Provider) getNamespace(ctx context.Context, path string) (*gitlab.Namespace, errors.ApiError) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetNamespace)
+
+	response, err := p.client.Get(ctx, p.getUrl(fmt.Sprintf(pathNamespaceFormat, url.PathEscape(path))), p.getHeaders())
+
+	var result gitlab.Namespace
+	if apiErr := handleResponse(ctx, "get namespace", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+	return &result, nil
+}
+
+func (p *Provider) CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	body := gitlab.CreateProjectRequest{
+		Name:        request.Name,
+		Path:        request.Name,
+		Description: request.Description,
+		Visibility:  providers.VisibilityPrivate,
+	}
+	if request.Org != "" {
+		namespace, err := p.getNamespace(ctx, request.Org)
+		if err != nil {
+			return nil, err
+		}
+		body.NamespaceId = namespace.Id
+	}
+
+	ctx = metrics.WithEndpoint(ctx, endpointCreateProject)
+	response, err := p.client.Post(ctx, p.getUrl(pathProjects), body, p.getHeaders())
+
+	var result gitlab.Project
+	if apiErr := handleResponse(ctx, "create project", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+	return toRepository(&result), nil
+}
+
+func (p *Provider) getProject(ctx context.Context, id string) (*gitlab.Project, errors.ApiError) {
+	ctx = metrics.WithEndpoint(ctx, endpointGetProject)
+
+	response, err := p.client.Get(ctx, p.getUrl(fmt.Sprintf(pathProjectFormat, id)), p.getHeaders())
+
+	var result gitlab.Project
+	if apiErr := handleResponse(ctx, "get project", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+	return &result, nil
+}
+
+func (p *Provider) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	project, err := p.getProject(ctx, projectId(owner, name))
+	if err != nil {
+		return nil, err
+	}
+	return toRepository(project), nil
+}
+
+func (p *Provider) setArchived(ctx context.Context, id string, archived bool) (*gitlab.Project, errors.ApiError) {
+	endpoint, path := endpointUnarchiveProject, pathUnarchiveProjectFormat
+	if archived {
+		endpoint, path = endpointArchiveProject, pathArchiveProjectFormat
+	}
+	ctx = metrics.WithEndpoint(ctx, endpoint)
+
+	response, err := p.client.Post(ctx, p.getUrl(fmt.Sprintf(path, id)), nil, p.getHeaders())
+
+	var result gitlab.Project
+	if apiErr := handleResponse(ctx, "archive project", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+	return &result, nil
+}
+
+// UpdateRepo edits the project settings and then archives or unarchives it,
+// GitLab only exposes archiving through dedicated endpoints.
+func (p *Provider) UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	id := projectId(owner, name)
+	body := gitlab.UpdateProjectRequest{
+		Name:          request.Name,
+		Path:          request.Name,
+		Description:   request.Description,
+		DefaultBranch: request.DefaultBranch,
+	}
+	if request.Private != nil {
+		visibility := visibilityOf(*request.Private)
+		body.Visibility = &visibility
+	}
+
+	var project *gitlab.Project
+	if body.Name != nil || body.Description != nil || body.Visibility != nil || body.DefaultBranch != nil {
+		updateCtx := metrics.WithEndpoint(ctx, endpointUpdateProject)
+		response, err := p.client.Put(updateCtx, p.getUrl(fmt.Sprintf(pathProjectFormat, id)), body, p.getHeaders())
+
+		var result gitlab.Project
+		if apiErr := handleResponse(updateCtx, "update project", response, err, &result); apiErr != nil {
+			return nil, apiErr
+		}
+		project = &result
+		id = strconv.FormatInt(result.Id, 10)
+	}
+
+	if request.Archived != nil {
+		archived, err := p.setArchived(ctx, id, *request.Archived)
+		if err != nil {
+			return nil, err
+		}
+		project = archived
+	}
+
+	if project == nil {
+		var err errors.ApiError
+		if project, err = p.getProject(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return toRepository(project), nil
+}
+
+// TransferRepo moves the project to another namespace. GitLab has no teams,
+// access follows the target group membership, so team ids are rejected.
+func (p *Provider) TransferRepo(ctx context.Context, owner string, name string, request repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	if len(request.TeamIds) > 0 {
+		return nil, errors.NewBadRequestApiError("team ids are not supported by gitlab")
+	}
+
+	transferCtx := metrics.WithEndpoint(ctx, endpointTransferProject)
+	body := gitlab.TransferProjectRequest{Namespace: request.NewOwner}
+	response, err := p.client.Put(transferCtx, p.getUrl(fmt.Sprintf(pathTransferProjectFormat, projectId(owner, name))), body, p.getHeaders())
+
+	var result gitlab.Project
+	if apiErr := handleResponse(transferCtx, "transfer project", response, err, &result); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if request.NewName == "" || request.NewName == result.Path {
+		return toRepository(&result), nil
+	}
+	return p.UpdateRepo(ctx, "", strconv.FormatInt(result.Id, 10), repositories.UpdateRepoRequest{Name: &request.NewName})
+}
+
+func (p *Provider) DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError {
+	ctx = metrics.WithEndpoint(ctx, endpointDeleteProject)
+
+	response, err := p.client.Delete(ctx, p.getUrl(fmt.Sprintf(pathProjectFormat, projectId(owner, name))), p.getHeaders())
+
+	return handleResponse(ctx, "delete project", response, err, nil)
+}
+
+func (p *Provider) getListReposUrl(options repositories.ListReposOptions) string {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(options.Page))
+	query.Set("per_page", strconv.Itoa(options.PerPage))
+	if options.Visibility != "" {
+		query.Set("visibility", options.Visibility)
+	}
+
+	if options.Owner == "" {
+		query.Set("owned", "true")
+		return p.getUrl(pathProjects) + "?" + query.Encode()
+	}
+	return p.getUrl(fmt.Sprintf(pathGroupProjectsFormat, url.PathEscape(options.Owner))) + "?" + query.Encode()
+}
+
+// ListRepos lists the projects of a group, or the ones owned by the token user
+// when no owner is given. GitLab drops X-Total-Pages on very large result sets,
+// the Link header is used as a fallback.
+func (p *Provider) ListRepos(ctx context.Context, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	endpoint := endpointListProjects
+	if options.Owner != "" {
+		endpoint = endpointListGroupProjects
+	}
+	ctx = metrics.WithEndpoint(ctx, endpoint)
+
+	if options.Page <= 0 {
+		options.Page = 1
+	}
+	if options.PerPage <= 0 {
+		options.PerPage = defaultPerPage
+	}
+
+	response, err := p.client.Get(ctx, p.getListReposUrl(options), p.getHeaders())
+
+	projects := make([]gitlab.Project, 0)
+	if apiErr := handleResponse(ctx, "list projects", response, err, &projects); apiErr != nil {
+		return nil, apiErr
+	}
+
+	result := repositories.RepositoryPage{
+		Repositories: make([]repositories.Repository, 0, len(projects)),
+		Page:         options.Page,
+	}
+	result.NextPage, result.LastPage = providers.ParseLinkHeader(response.Header.Get(headerLink))
+	if next, err := strconv.Atoi(response.Header.Get(headerNextPage)); err == nil {
+		result.NextPage = next
+	}
+	if last, err := strconv.Atoi(response.Header.Get(headerTotalPages)); err == nil {
+		result.LastPage = last
+	}
+	for i := range projects {
+		result.Repositories = append(result.Repositories, *toRepository(&projects[i]))
+	}
+	return &result, nil
+}
@@ -0,0 +1,208 @@
+package gitlab_provider
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const projectJson = `{
+  "id": 42,
+  "name": "my-repo",
+  "path": "my-repo",
+  "path_with_namespace": "platform/tools/my-repo",
+  "description": "testing",
+  "visibility": "private",
+  "archived": false,
+  "default_branch": "main",
+  "web_url": "https://gitlab.example.com/platform/tools/my-repo",
+  "namespace": {"id": 7, "path": "tools", "full_path": "platform/tools"}
+}`
+
+func newMockedProvider() (*Provider, *restclient.Client) {
+	client, _ := restclient.NewClient("")
+	client.StartMock()
+	return New(client, "https://gitlab.example.com/", "glpat-abc123"), client
+}
+
+func mockResponse(client *restclient.Client, method string, url string, statusCode int, body string) {
+	client.AddMock(&restclient.Mock{
+		Url:        url,
+		HttpMethod: method,
+		Response: &http.Response{
+			StatusCode: statusCode,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		},
+	})
+}
+
+var expectedRepository = repositories.Repository{
+	Id:            42,
+	Owner:         "platform/tools",
+	Name:          "my-repo",
+	FullName:      "platform/tools/my-repo",
+	Description:   "testing",
+	Private:       true,
+	DefaultBranch: "main",
+	HtmlUrl:       "https://gitlab.example.com/platform/tools/my-repo",
+	Provider:      "gitlab",
+}
+
+func TestGetHeaders(t *testing.T) {
+	provider, _ := newMockedProvider()
+	assert.EqualValues(t, "glpat-abc123", provider.getHeaders().Get("Private-Token"))
+}
+
+func TestProjectId(t *testing.T) {
+	assert.EqualValues(t, "platform%2Ftools%2Fmy-repo", projectId("platform/tools", "my-repo"))
+	assert.EqualValues(t, "my-repo", projectId("", "my-repo"))
+}
+
+func TestCreateRepoInGroup(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodGet, "https://gitlab.example.com/api/v4/namespaces/platform%2Ftools", http.StatusOK,
+		`{"id": 7, "path": "tools", "full_path": "platform/tools"}`)
+	mockResponse(client, http.MethodPost, "https://gitlab.example.com/api/v4/projects", http.StatusCreated, projectJson)
+
+	repo, err := provider.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo", Org: "platform/tools"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedRepository, *repo)
+}
+
+func TestCreateRepoUnknownGroup(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodGet, "https://gitlab.example.com/api/v4/namespaces/missing", http.StatusNotFound,
+		`{"message": "404 Namespace Not Found"}`)
+
+	repo, err := provider.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo", Org: "missing"})
+
+	assert.Nil(t, repo)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "404 Namespace Not Found", err.Message())
+}
+
+func TestCreateRepoAlreadyExists(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPost, "https://gitlab.example.com/api/v4/projects", http.StatusBadRequest,
+		`{"message": {"name": ["has already been taken"], "path": ["has already been taken"]}}`)
+
+	repo, err := provider.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo"})
+
+	assert.Nil(t, repo)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "name has already been taken; path has already been taken", err.Message())
+}
+
+func TestGetRepoOk(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodGet, "https://gitlab.example.com/api/v4/projects/platform%2Ftools%2Fmy-repo", http.StatusOK, projectJson)
+
+	repo, err := provider.GetRepo(context.Background(), "platform/tools", "my-repo")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedRepository, *repo)
+}
+
+func TestUpdateRepoOnlyArchives(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPost, "https://gitlab.example.com/api/v4/projects/platform%2Ftools%2Fmy-repo/archive", http.StatusCreated,
+		strings.Replace(projectJson, `"archived": false`, `"archived": true`, 1))
+
+	archived := true
+	repo, err := provider.UpdateRepo(context.Background(), "platform/tools", "my-repo", repositories.UpdateRepoRequest{Archived: &archived})
+
+	assert.Nil(t, err)
+	assert.True(t, repo.Archived)
+}
+
+func TestUpdateRepoEditsThenArchivesById(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPut, "https://gitlab.example.com/api/v4/projects/platform%2Ftools%2Fmy-repo", http.StatusOK, projectJson)
+	mockResponse(client, http.MethodPost, "https://gitlab.example.com/api/v4/projects/42/unarchive", http.StatusCreated, projectJson)
+
+	description := "testing"
+	archived := false
+	repo, err := provider.UpdateRepo(context.Background(), "platform/tools", "my-repo",
+		repositories.UpdateRepoRequest{Description: &description, Archived: &archived})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedRepository, *repo)
+}
+
+func TestDeleteRepoOk(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodDelete, "https://gitlab.example.com/api/v4/projects/platform%2Ftools%2Fmy-repo", http.StatusAccepted,
+		`{"message": "202 Accepted"}`)
+
+	assert.Nil(t, provider.DeleteRepo(context.Background(), "platform/tools", "my-repo"))
+}
+
+func TestTransferRepoRenamesAfterTransfer(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodPut, "https://gitlab.example.com/api/v4/projects/platform%2Ftools%2Fmy-repo/transfer", http.StatusOK,
+		strings.Replace(projectJson, "platform/tools", "platform/apps", -1))
+	mockResponse(client, http.MethodPut, "https://gitlab.example.com/api/v4/projects/42", http.StatusOK,
+		strings.Replace(strings.Replace(projectJson, "platform/tools", "platform/apps", -1), "my-repo", "renamed", -1))
+
+	repo, err := provider.TransferRepo(context.Background(), "platform/tools", "my-repo",
+		repositories.TransferRepoRequest{NewOwner: "platform/apps", NewName: "renamed"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "platform/apps/renamed", repo.FullName)
+}
+
+func TestTransferRepoRejectsTeams(t *testing.T) {
+	provider, _ := newMockedProvider()
+
+	repo, err := provider.TransferRepo(context.Background(), "platform/tools", "my-repo",
+		repositories.TransferRepoRequest{NewOwner: "platform/apps", TeamIds: []int64{1}})
+
+	assert.Nil(t, repo)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestListReposPagination(t *testing.T) {
+	provider, client := newMockedProvider()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://gitlab.example.com/api/v4/groups/platform/projects?page=1&per_page=30&visibility=private",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"X-Next-Page": {"2"}, "X-Total-Pages": {"4"}},
+			Body:       io.NopCloser(strings.NewReader("[" + projectJson + "]")),
+		},
+	})
+
+	page, err := provider.ListRepos(context.Background(), repositories.ListReposOptions{Owner: "platform", Visibility: "private"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, page.Page)
+	assert.EqualValues(t, 2, page.NextPage)
+	assert.EqualValues(t, 4, page.LastPage)
+	assert.EqualValues(t, []repositories.Repository{expectedRepository}, page.Repositories)
+}
+
+func TestCheckCredentials(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodGet, "https://gitlab.example.com/api/v4/user", http.StatusUnauthorized, `{"message": "401 Unauthorized"}`)
+	assert.EqualValues(t, "gitlab access token is invalid: 401 Unauthorized", provider.CheckCredentials(context.Background()).Error())
+
+	unconfigured := New(client, "https://gitlab.example.com", "")
+	assert.EqualValues(t, "gitlab access token is not configured", unconfigured.CheckCredentials(context.Background()).Error())
+}
+
+func TestAccountLogin(t *testing.T) {
+	provider, client := newMockedProvider()
+	mockResponse(client, http.MethodGet, "https://gitlab.example.com/api/v4/user", http.StatusOK, `{"id": 1, "username": "ci-bot"}`)
+
+	login, err := provider.AccountLogin(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ci-bot", login)
+}
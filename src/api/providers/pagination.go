@@ -0,0 +1,38 @@
+package providers
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseLinkHeader returns the next and last page numbers of an RFC 5988 Link
+// header as sent by GitHub and Gitea, zero when the relation is missing.
+func ParseLinkHeader(header string) (next int, last int) {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+
+		target := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+		parsed, err := url.Parse(target)
+		if err != nil {
+			continue
+		}
+		page, err := strconv.Atoi(parsed.Query().Get("page"))
+		if err != nil {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			switch strings.TrimSpace(param) {
+			case `rel="next"`:
+				next = page
+			case `rel="last"`:
+				last = page
+			}
+		}
+	}
+	return next, last
+}
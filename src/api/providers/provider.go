@@ -0,0 +1,139 @@
+package providers
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/utils/errors"
+	"sort"
+)
+
+const Github = "github"
+const Gitlab = "gitlab"
+const Gitea = "gitea"
+
+const VisibilityPublic = "public"
+const VisibilityPrivate = "private"
+
+type RepoProvider interface {
+	CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.Repository, errors.ApiError)
+	GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError)
+	UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError)
+	DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError
+	TransferRepo(ctx context.Context, owner string, name string, request repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError)
+	ListRepos(ctx context.Context, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError)
+}
+
+// FileCommitter is implemented by providers able to commit files, it is used
+// to seed new repositories from templates.
+type FileCommitter interface {
+	CommitFiles(ctx context.Context, owner string, name string, branch string, message string, files []repositories.File) errors.ApiError
+}
+
+// HookCreator is implemented by providers able to create webhooks, secret is
+// the resolved secret value and must never be logged.
+type HookCreator interface {
+	CreateHook(ctx context.Context, owner string, name string, hook repositories.Webhook, secret string) (int64, errors.ApiError)
+}
+
+// ActionsConfigurer is implemented by providers with a CI secrets and
+// variables store. Secret values map secret names to resolved values, they
+// are encrypted by the provider and must never be logged.
+type ActionsConfigurer interface {
+	SetActionsSecrets(ctx context.Context, owner string, name string, values map[string]string) errors.ApiError
+	SetActionsVariables(ctx context.Context, owner string, name string, variables []repositories.ActionsVariable) errors.ApiError
+}
+
+// AccessManager is implemented by providers able to grant teams and
+// collaborators access. AddCollaborator returns the invitation id when the
+// user has to accept an invitation first, 0 when access is immediate.
+type AccessManager interface {
+	ListTeams(ctx context.Context, owner string, name string) ([]repositories.TeamAccess, errors.ApiError)
+	AddTeam(ctx context.Context, owner string, name string, slug string, permission string) errors.ApiError
+	AddCollaborator(ctx context.Context, owner string, name string, username string, permission string) (int64, errors.ApiError)
+}
+
+// BranchProtector is implemented by providers able to protect branches. The
+// policy is passed whole so providers with several mechanisms can pick the
+// one it asks for.
+type BranchProtector interface {
+	GetBranchProtection(ctx context.Context, owner string, name string, branch string, policy repositories.ProtectionPolicy) (*repositories.BranchProtection, errors.ApiError)
+	SetBranchProtection(ctx context.Context, owner string, name string, branch string, policy repositories.ProtectionPolicy) errors.ApiError
+}
+
+// LabelManager is implemented by providers with issue labels, labels are
+// addressed by their current name.
+type LabelManager interface {
+	ListLabels(ctx context.Context, owner string, name string) ([]repositories.Label, errors.ApiError)
+	CreateLabel(ctx context.Context, owner string, name string, label repositories.Label) errors.ApiError
+	UpdateLabel(ctx context.Context, owner string, name string, current string, label repositories.Label) errors.ApiError
+	DeleteLabel(ctx context.Context, owner string, name string, label string) errors.ApiError
+}
+
+// TopicsSetter is implemented by providers able to replace the topics of a
+// repository, it returns the topics as stored by the provider.
+type TopicsSetter interface {
+	SetTopics(ctx context.Context, owner string, name string, topics []string) ([]string, errors.ApiError)
+}
+
+// CredentialsChecker is implemented by providers able to verify their access
+// token, it backs the per provider readiness checks.
+type CredentialsChecker interface {
+	CheckCredentials(ctx context.Context) error
+}
+
+// AccountResolver is implemented by providers able to tell who owns their
+// access token, repositories created without an org belong to that account.
+type AccountResolver interface {
+	AccountLogin(ctx context.Context) (string, errors.ApiError)
+}
+
+type Registry struct {
+	providers       map[string]RepoProvider
+	defaultProvider string
+	orgProviders    map[string]string
+}
+
+func NewRegistry(defaultProvider string, orgProviders map[string]string) *Registry {
+	if orgProviders == nil {
+		orgProviders = make(map[string]string)
+	}
+	return &Registry{
+		providers:       make(map[string]RepoProvider),
+		defaultProvider: defaultProvider,
+		orgProviders:    orgProviders,
+	}
+}
+
+func (r *Registry) Register(name string, provider RepoProvider) {
+	r.providers[name] = provider
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) Get(name string) RepoProvider {
+	return r.providers[name]
+}
+
+// Resolve picks the provider explicitly requested, then the one configured for
+// the org and finally the default one.
+func (r *Registry) Resolve(name string, org string) (string, RepoProvider, errors.ApiError) {
+	if name == "" {
+		name = r.orgProviders[org]
+	}
+	if name == "" {
+		name = r.defaultProvider
+	}
+
+	provider := r.providers[name]
+	if provider == nil {
+		return "", nil, errors.NewBadRequestApiError("unknown repository provider " + name)
+	}
+	return name, provider, nil
+}
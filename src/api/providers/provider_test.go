@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+	"testing"
+)
+
+type nopProvider struct{}
+
+func (p *nopProvider) CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	return nil, nil
+}
+
+func (p *nopProvider) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	return nil, nil
+}
+
+func (p *nopProvider) UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	return nil, nil
+}
+
+func (p *nopProvider) TransferRepo(ctx context.Context, owner string, name string, request repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	return nil, nil
+}
+
+func (p *nopProvider) DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError {
+	return nil
+}
+
+func (p *nopProvider) ListRepos(ctx context.Context, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	return nil, nil
+}
+
+func TestRegistryResolve(t *testing.T) {
+	github := &nopProvider{}
+	gitlab := &nopProvider{}
+	registry := NewRegistry(Github, map[string]string{"platform": Gitlab})
+	registry.Register(Github, github)
+	registry.Register(Gitlab, gitlab)
+
+	name, provider, err := registry.Resolve("", "my-org")
+	assert.Nil(t, err)
+	assert.EqualValues(t, Github, name)
+	assert.True(t, provider == github)
+
+	name, provider, err = registry.Resolve("", "platform")
+	assert.Nil(t, err)
+	assert.EqualValues(t, Gitlab, name)
+	assert.True(t, provider == gitlab)
+
+	name, _, err = registry.Resolve(Github, "platform")
+	assert.Nil(t, err)
+	assert.EqualValues(t, Github, name)
+
+	_, provider, err = registry.Resolve("bitbucket", "")
+	assert.Nil(t, provider)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "unknown repository provider bitbucket", err.Message())
+
+	assert.EqualValues(t, []string{Github, Gitlab}, registry.Names())
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	next, last := ParseLinkHeader(`<https://api.github.com/orgs/my-org/repos?page=3&per_page=30>; rel="next", ` +
+		`<https://api.github.com/orgs/my-org/repos?page=7&per_page=30>; rel="last"`)
+	assert.EqualValues(t, 3, next)
+	assert.EqualValues(t, 7, last)
+
+	next, last = ParseLinkHeader(`<https://api.github.com/orgs/my-org/repos?page=1>; rel="first"`)
+	assert.EqualValues(t, 0, next)
+	assert.EqualValues(t, 0, last)
+
+	next, last = ParseLinkHeader("")
+	assert.EqualValues(t, 0, next)
+	assert.EqualValues(t, 0, last)
+}
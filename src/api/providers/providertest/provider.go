@@ -0,0 +1,357 @@
+// Package providertest provides an in memory repository provider so tests can
+// go through the real services, router and middlewares without a git host.
+package providertest
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/utils/errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ExistingName is refused by CreateRepo the way a git host refuses a name
+// that is already taken.
+const ExistingName = "existing"
+
+var (
+	_ providers.RepoProvider       = (*Provider)(nil)
+	_ providers.FileCommitter      = (*Provider)(nil)
+	_ providers.HookCreator        = (*Provider)(nil)
+	_ providers.AccessManager      = (*Provider)(nil)
+	_ providers.BranchProtector    = (*Provider)(nil)
+	_ providers.CredentialsChecker = (*Provider)(nil)
+)
+
+type Option func(*Provider)
+
+// WithName sets the provider name reported on repositories, providers.Github
+// by default.
+func WithName(name string) Option {
+	return func(p *Provider) {
+		p.name = name
+	}
+}
+
+// WithOwner sets the owner of repositories created without an org and listed
+// without an owner.
+func WithOwner(owner string) Option {
+	return func(p *Provider) {
+		p.owner = owner
+	}
+}
+
+// WithRepos seeds repositories from their full names.
+func WithRepos(fullNames ...string) Option {
+	return func(p *Provider) {
+		for _, fullName := range fullNames {
+			parts := strings.SplitN(fullName, "/", 2)
+			p.add(repositories.Repository{Owner: parts[0], Name: parts[1]})
+		}
+	}
+}
+
+// WithRepositories seeds repositories, only Owner and Name are required.
+func WithRepositories(repos ...repositories.Repository) Option {
+	return func(p *Provider) {
+		for _, repo := range repos {
+			p.add(repo)
+		}
+	}
+}
+
+// WithPageSize serves ListRepos pages of size repositories, every repository
+// is served on one page by default.
+func WithPageSize(size int) Option {
+	return func(p *Provider) {
+		p.pageSize = size
+	}
+}
+
+// Provider keeps repositories in creation order and records what the optional
+// provider interfaces were asked to do.
+type Provider struct {
+	mutex     sync.Mutex
+	name      string
+	owner     string
+	pageSize  int
+	lastId    int64
+	repos     []repositories.Repository
+	committed []string
+	hooks     []string
+	access    []string
+	protected map[string]repositories.BranchProtection
+}
+
+func New(options ...Option) *Provider {
+	p := &Provider{name: providers.Github, owner: "owner", protected: make(map[string]repositories.BranchProtection)}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+func (p *Provider) add(repo repositories.Repository) repositories.Repository {
+	p.lastId++
+	repo.Id = p.lastId
+	repo.FullName = repo.Owner + "/" + repo.Name
+	repo.Provider = p.name
+	if repo.DefaultBranch == "" {
+		repo.DefaultBranch = "main"
+	}
+	p.repos = append(p.repos, repo)
+	return repo
+}
+
+func (p *Provider) find(owner string, name string) int {
+	for i, repo := range p.repos {
+		if repo.FullName == owner+"/"+name {
+			return i
+		}
+	}
+	return -1
+}
+
+// FullNames returns the full names of the repositories in creation order.
+func (p *Provider) FullNames() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	result := make([]string, 0, len(p.repos))
+	for _, repo := range p.repos {
+		result = append(result, repo.FullName)
+	}
+	return result
+}
+
+// Repo returns the repository named fullName, the zero value when there is
+// none.
+func (p *Provider) Repo(fullName string) repositories.Repository {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, repo := range p.repos {
+		if repo.FullName == fullName {
+			return repo
+		}
+	}
+	return repositories.Repository{}
+}
+
+// Committed returns the committed files as "owner/name/path: content".
+func (p *Provider) Committed() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]string(nil), p.committed...)
+}
+
+// Hooks returns the created webhooks as "owner/name url secret".
+func (p *Provider) Hooks() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]string(nil), p.hooks...)
+}
+
+// Access returns the grants as "owner/name team|collaborator name permission".
+func (p *Provider) Access() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]string(nil), p.access...)
+}
+
+func (p *Provider) CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	owner := p.owner
+	if request.Org != "" {
+		owner = request.Org
+	}
+	if request.Name == ExistingName || p.find(owner, request.Name) >= 0 {
+		return nil, errors.NewApiError(http.StatusUnprocessableEntity, "Repository creation failed.")
+	}
+	repo := p.add(repositories.Repository{Owner: owner, Name: request.Name, Description: request.Description, Private: true})
+	return &repo, nil
+}
+
+func (p *Provider) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	i := p.find(owner, name)
+	if i < 0 {
+		return nil, errors.NewNotFoundApiError("Not Found")
+	}
+	repo := p.repos[i]
+	return &repo, nil
+}
+
+func (p *Provider) UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	i := p.find(owner, name)
+	if i < 0 {
+		return nil, errors.NewNotFoundApiError("Not Found")
+	}
+	repo := &p.repos[i]
+	if request.Name != nil {
+		repo.Name, repo.FullName = *request.Name, owner+"/"+*request.Name
+	}
+	if request.Description != nil {
+		repo.Description = *request.Description
+	}
+	if request.Private != nil {
+		repo.Private = *request.Private
+	}
+	if request.Archived != nil {
+		repo.Archived = *request.Archived
+	}
+	result := *repo
+	return &result, nil
+}
+
+func (p *Provider) DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	i := p.find(owner, name)
+	if i < 0 {
+		return errors.NewNotFoundApiError("Not Found")
+	}
+	p.repos = append(p.repos[:i], p.repos[i+1:]...)
+	return nil
+}
+
+func (p *Provider) TransferRepo(ctx context.Context, owner string, name string, request repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	i := p.find(owner, name)
+	if i < 0 {
+		return nil, errors.NewNotFoundApiError("Not Found")
+	}
+	repo := &p.repos[i]
+	if request.NewName != "" {
+		repo.Name = request.NewName
+	}
+	repo.Owner, repo.FullName = request.NewOwner, request.NewOwner+"/"+repo.Name
+	result := *repo
+	return &result, nil
+}
+
+// ListRepos lists the repositories of options.Owner, of the provider owner
+// when it is empty, in creation order.
+func (p *Provider) ListRepos(ctx context.Context, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	owner := options.Owner
+	if owner == "" {
+		owner = p.owner
+	}
+	owned := make([]repositories.Repository, 0)
+	for _, repo := range p.repos {
+		if repo.Owner == owner {
+			owned = append(owned, repo)
+		}
+	}
+
+	size := p.pageSize
+	if size <= 0 {
+		size = len(owned)
+	}
+	page := options.Page
+	if page <= 0 {
+		page = 1
+	}
+	result := &repositories.RepositoryPage{Repositories: make([]repositories.Repository, 0), Page: page}
+	if size == 0 {
+		return result, nil
+	}
+	result.LastPage = (len(owned) + size - 1) / size
+	start := (page - 1) * size
+	if start < len(owned) {
+		end := start + size
+		if end > len(owned) {
+			end = len(owned)
+		}
+		result.Repositories = append(result.Repositories, owned[start:end]...)
+	}
+	if page < result.LastPage {
+		result.NextPage = page + 1
+	}
+	return result, nil
+}
+
+func (p *Provider) CommitFiles(ctx context.Context, owner string, name string, branch string, message string, files []repositories.File) errors.ApiError {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, file := range files {
+		p.committed = append(p.committed, owner+"/"+name+"/"+file.Path+": "+string(file.Content))
+	}
+	return nil
+}
+
+func (p *Provider) CreateHook(ctx context.Context, owner string, name string, hook repositories.Webhook, secret string) (int64, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.hooks = append(p.hooks, owner+"/"+name+" "+hook.Url+" "+secret)
+	return int64(len(p.hooks)), nil
+}
+
+func (p *Provider) ListTeams(ctx context.Context, owner string, name string) ([]repositories.TeamAccess, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	result := make([]repositories.TeamAccess, 0)
+	for _, grant := range p.access {
+		if parts := strings.Split(grant, " "); parts[0] == owner+"/"+name && parts[1] == "team" {
+			result = append(result, repositories.TeamAccess{Slug: parts[2], Permission: parts[3]})
+		}
+	}
+	return result, nil
+}
+
+func (p *Provider) AddTeam(ctx context.Context, owner string, name string, slug string, permission string) errors.ApiError {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.access = append(p.access, owner+"/"+name+" team "+slug+" "+permission)
+	return nil
+}
+
+func (p *Provider) AddCollaborator(ctx context.Context, owner string, name string, username string, permission string) (int64, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.access = append(p.access, owner+"/"+name+" collaborator "+username+" "+permission)
+	return int64(len(p.access)), nil
+}
+
+func (p *Provider) GetBranchProtection(ctx context.Context, owner string, name string, branch string, policy repositories.ProtectionPolicy) (*repositories.BranchProtection, errors.ApiError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	protection := p.protected[owner+"/"+name+"@"+branch]
+	return &protection, nil
+}
+
+func (p *Provider) SetBranchProtection(ctx context.Context, owner string, name string, branch string, policy repositories.ProtectionPolicy) errors.ApiError {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.protected[owner+"/"+name+"@"+branch] = policy.BranchProtection
+	return nil
+}
+
+func (p *Provider) CheckCredentials(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const quotaDayLayout = "2006-01-02"
+
+// evictInterval is how often idle buckets and past quota counters are
+// dropped, a full bucket behaves exactly like a missing one.
+const evictInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket is back to its burst, zero if it never refills.
+	full time.Time
+}
+
+type quotaCounter struct {
+	day  string
+	used int
+}
+
+type memoryStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	quotas    map[string]*quotaCounter
+	lastEvict time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: make(map[string]*bucket),
+		quotas:  make(map[string]*quotaCounter),
+	}
+}
+
+func (s *memoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.evictIdle(now)
+	current := s.buckets[key]
+	if current == nil {
+		current = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = current
+	}
+
+	elapsed := now.Sub(current.updated).Seconds()
+	if elapsed > 0 {
+		current.tokens = math.Min(float64(limit.Burst), current.tokens+elapsed*limit.Rate)
+		current.updated = now
+	}
+
+	result := Result{Limit: limit.Burst}
+	if current.tokens >= 1 {
+		current.tokens--
+		result.Allowed = true
+	}
+	result.Remaining = int(current.tokens)
+
+	missing := float64(limit.Burst) - current.tokens
+	if limit.Rate > 0 {
+		result.ResetAt = now.Add(time.Duration(missing / limit.Rate * float64(time.Second)))
+		current.full = result.ResetAt
+	}
+
+	return result, nil
+}
+
+// evictIdle drops the buckets that refilled and the quota counters of past
+// days, at most once per evictInterval.
+func (s *memoryStore) evictIdle(now time.Time) {
+	if now.Sub(s.lastEvict) < evictInterval {
+		return
+	}
+	s.lastEvict = now
+
+	for key, current := range s.buckets {
+		if !current.full.IsZero() && !now.Before(current.full) {
+			delete(s.buckets, key)
+		}
+	}
+	day := now.UTC().Format(quotaDayLayout)
+	for key, current := range s.quotas {
+		if current.day != day {
+			delete(s.quotas, key)
+		}
+	}
+}
+
+func (s *memoryStore) quotaCounter(key string, now time.Time) *quotaCounter {
+	day := now.UTC().Format(quotaDayLayout)
+	current := s.quotas[key]
+	if current == nil || current.day != day {
+		current = &quotaCounter{day: day}
+		s.quotas[key] = current
+	}
+	return current
+}
+
+func (s *memoryStore) ConsumeQuota(key string, amount int, quota int, now time.Time) (Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := s.quotaCounter(key, now)
+	year, month, day := now.UTC().Date()
+	result := Result{Limit: quota, ResetAt: time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)}
+
+	if current.used+amount <= quota {
+		current.used += amount
+		result.Allowed = true
+	}
+	result.Remaining = quota - current.used
+
+	return result, nil
+}
+
+func (s *memoryStore) RefundQuota(key string, amount int, now time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := s.quotaCounter(key, now)
+	current.used -= amount
+	if current.used < 0 {
+		current.used = 0
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTakeExhaustsBurst(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Rate: 1, Burst: 2}
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first, _ := store.Take("team-a", limit, now)
+	second, _ := store.Take("team-a", limit, now)
+	third, _ := store.Take("team-a", limit, now)
+
+	assert.True(t, first.Allowed)
+	assert.EqualValues(t, 1, first.Remaining)
+	assert.True(t, second.Allowed)
+	assert.EqualValues(t, 0, second.Remaining)
+	assert.False(t, third.Allowed)
+	assert.EqualValues(t, 2, third.Limit)
+	assert.EqualValues(t, now.Add(2*time.Second), third.ResetAt)
+
+	other, _ := store.Take("team-b", limit, now)
+	assert.True(t, other.Allowed)
+}
+
+func TestTakeRefillsTokens(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Rate: 1, Burst: 1}
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first, _ := store.Take("team-a", limit, now)
+	second, _ := store.Take("team-a", limit, now.Add(500*time.Millisecond))
+	third, _ := store.Take("team-a", limit, now.Add(1500*time.Millisecond))
+
+	assert.True(t, first.Allowed)
+	assert.False(t, second.Allowed)
+	assert.True(t, third.Allowed)
+}
+
+func TestConsumeQuota(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first, _ := store.ConsumeQuota("team-a", 2, 3, now)
+	second, _ := store.ConsumeQuota("team-a", 2, 3, now)
+
+	assert.True(t, first.Allowed)
+	assert.EqualValues(t, 1, first.Remaining)
+	assert.EqualValues(t, time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), first.ResetAt)
+	assert.False(t, second.Allowed)
+	assert.EqualValues(t, 1, second.Remaining)
+
+	assert.Nil(t, store.RefundQuota("team-a", 1, now))
+	third, _ := store.ConsumeQuota("team-a", 2, 3, now)
+	assert.True(t, third.Allowed)
+	assert.EqualValues(t, 0, third.Remaining)
+
+	nextDay, _ := store.ConsumeQuota("team-a", 3, 3, now.Add(24*time.Hour))
+	assert.True(t, nextDay.Allowed)
+}
+
+func TestTakeEvictsIdleBuckets(t *testing.T) {
+	store := NewMemoryStore().(*memoryStore)
+	limit := Limit{Rate: 1, Burst: 2}
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, _ = store.Take("team-a", limit, now)
+	_, _ = store.ConsumeQuota("team-a", 1, 3, now)
+	_, _ = store.Take("team-b", limit, now.Add(evictInterval))
+	assert.EqualValues(t, 1, len(store.buckets))
+	assert.NotNil(t, store.buckets["team-b"])
+
+	_, _ = store.Take("team-b", limit, now.Add(evictInterval+time.Second))
+	assert.EqualValues(t, 1, len(store.quotas))
+
+	_, _ = store.Take("team-c", limit, now.Add(24*time.Hour))
+	assert.EqualValues(t, 1, len(store.buckets))
+	assert.EqualValues(t, 0, len(store.quotas))
+}
+
+func TestLimitValidate(t *testing.T) {
+	assert.Nil(t, Limit{Rate: 0.5, Burst: 1}.Validate())
+	assert.NotNil(t, Limit{Rate: 0, Burst: 1}.Validate())
+	assert.NotNil(t, Limit{Rate: 1, Burst: 0}.Validate())
+}
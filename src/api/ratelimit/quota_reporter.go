@@ -0,0 +1,19 @@
+package ratelimit
+
+import "context"
+
+// QuotaReporter receives the quota results consumed while serving a request,
+// the http layer turns them into headers.
+type QuotaReporter func(result Result)
+
+type quotaReporterKey struct{}
+
+func WithQuotaReporter(ctx context.Context, reporter QuotaReporter) context.Context {
+	return context.WithValue(ctx, quotaReporterKey{}, reporter)
+}
+
+func ReportQuota(ctx context.Context, result Result) {
+	if reporter, ok := ctx.Value(quotaReporterKey{}).(QuotaReporter); ok {
+		reporter(result)
+	}
+}
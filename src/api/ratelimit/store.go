@@ -0,0 +1,34 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Validate rejects limits that never refill or never allow a request.
+func (l Limit) Validate() error {
+	if l.Rate <= 0 || l.Burst <= 0 {
+		return errors.New("rate limit must be positive")
+	}
+	return nil
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+type Store interface {
+	Take(key string, limit Limit, now time.Time) (Result, error)
+	ConsumeQuota(key string, amount int, quota int, now time.Time) (Result, error)
+	RefundQuota(key string, amount int, now time.Time) error
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// Store resolves secret references to their values. Requests only ever carry
+// the names, values stay on the server and must not be logged or returned.
+type Store struct {
+	values map[string]string
+	scopes map[string]Scope
+}
+
+// Scope lists who may hand a secret to a repository, as an Actions secret any
+// workflow can read back or as the signing secret of a webhook. "*" in Orgs
+// allows any org and "" the account of the token owner.
+type Scope struct {
+	Orgs    []string `json:"orgs"`
+	Callers []string `json:"callers"`
+}
+
+type scopedValue struct {
+	Value string `json:"value"`
+	Scope
+}
+
+func NewStore(values map[string]string) *Store {
+	return NewScopedStore(values, nil)
+}
+
+func NewScopedStore(values map[string]string, scopes map[string]Scope) *Store {
+	store := &Store{
+		values: make(map[string]string, len(values)),
+		scopes: make(map[string]Scope, len(scopes)),
+	}
+	for name, value := range values {
+		store.values[name] = value
+	}
+	for name, scope := range scopes {
+		store.scopes[name] = scope
+	}
+	return store
+}
+
+// Load reads a json object of secret names to values, an empty path gives an
+// empty store. A value is either the plain secret or an object with the
+// "value" and the "orgs" and "callers" of its Scope.
+func Load(path string) (*Store, error) {
+	if path == "" {
+		return NewStore(nil), nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", path, err)
+	}
+	values := make(map[string]string, len(entries))
+	scopes := make(map[string]Scope)
+	for name, entry := range entries {
+		var value string
+		if err := json.Unmarshal(entry, &value); err == nil {
+			values[name] = value
+			continue
+		}
+		var scoped scopedValue
+		if err := json.Unmarshal(entry, &scoped); err != nil {
+			return nil, fmt.Errorf("invalid secret %s in %s: %w", name, path, err)
+		}
+		values[name] = scoped.Value
+		scopes[name] = scoped.Scope
+	}
+	return NewScopedStore(values, scopes), nil
+}
+
+func (s *Store) Get(name string) (string, bool) {
+	value, ok := s.values[name]
+	return value, ok
+}
+
+func (s *Store) Has(name string) bool {
+	_, ok := s.values[name]
+	return ok
+}
+
+// Allows reports whether the caller may use the secret in the org. Secrets
+// without a scope are denied, as are orgs and callers not listed in it.
+func (s *Store) Allows(name string, caller string, org string) bool {
+	scope, ok := s.scopes[name]
+	if !ok {
+		return false
+	}
+	for _, current := range scope.Orgs {
+		if current == "*" || current == org {
+			return true
+		}
+	}
+	for _, current := range scope.Callers {
+		if current == caller {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) Names() []string {
+	result := make([]string, 0, len(s.values))
+	for name := range s.values {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"ci-hook": "s3cr3t", "chat-hook": "other"}`), 0600))
+
+	store, err := Load(path)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"chat-hook", "ci-hook"}, store.Names())
+
+	value, ok := store.Get("ci-hook")
+	assert.True(t, ok)
+	assert.EqualValues(t, "s3cr3t", value)
+	assert.False(t, store.Has("missing"))
+}
+
+func TestLoadScoped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	content := `{"ci-hook": "s3cr3t", "npm": {"value": "t0ken", "orgs": ["my-org"], "callers": ["team-a"]}}`
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	store, err := Load(path)
+	assert.Nil(t, err)
+	value, _ := store.Get("npm")
+	assert.EqualValues(t, "t0ken", value)
+
+	assert.True(t, store.Allows("npm", "team-b", "my-org"))
+	assert.True(t, store.Allows("npm", "team-a", "other-org"))
+	assert.False(t, store.Allows("npm", "team-b", "other-org"))
+	assert.False(t, store.Allows("ci-hook", "team-a", "my-org"))
+	assert.False(t, store.Allows("missing", "team-a", "my-org"))
+}
+
+func TestLoadErrors(t *testing.T) {
+	store, err := Load("")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{}, store.Names())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`["not", "an", "object"]`), 0600))
+	_, err = Load(path)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"npm": ["not", "a", "secret"]}`), 0600))
+	_, err = Load(path)
+	assert.NotNil(t, err)
+}
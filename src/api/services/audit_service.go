@@ -0,0 +1,34 @@
+package services
+
+import (
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+)
+
+type auditService struct {
+	reader audit.Reader
+}
+
+type AuditServiceInterface interface {
+	Query(filter repositories.AuditFilter) ([]repositories.AuditEntry, errors.ApiError)
+}
+
+func NewAuditService(reader audit.Reader) AuditServiceInterface {
+	return &auditService{reader: reader}
+}
+
+func (s *auditService) Query(filter repositories.AuditFilter) ([]repositories.AuditEntry, errors.ApiError) {
+	if s.reader == nil {
+		return nil, errors.NewNotFoundApiError("audit log is not configured")
+	}
+
+	entries, err := s.reader.Query(filter)
+	if err != nil {
+		logger.Default().Error("error when querying audit log", logger.Err(err))
+		return nil, errors.NewInternalServerError("error when querying audit log")
+	}
+
+	return entries, nil
+}
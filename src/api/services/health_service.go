@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"golang-microservices/src/api/config"
+	"golang-microservices/src/api/health"
+	"golang-microservices/src/api/providers"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	tokenCheckTtl        = 5 * time.Minute
+	tokenCheckFailureTtl = 10 * time.Second
+	checkTimeout         = 5 * time.Second
+)
+
+type healthService struct {
+	liveness     []health.Check
+	readiness    []health.Check
+	shuttingDown int32
+}
+
+type HealthServiceInterface interface {
+	Liveness(ctx context.Context) health.Report
+	Readiness(ctx context.Context) health.Report
+	SetShuttingDown()
+}
+
+// NewHealthService bounds every readiness check with checkTimeout, a
+// dependency that hangs fails the probe instead of stalling it.
+func NewHealthService(readiness ...health.Check) HealthServiceInterface {
+	result := &healthService{}
+	result.readiness = []health.Check{health.NewCheck("shutdown", result.checkShutdown)}
+	for _, check := range readiness {
+		result.readiness = append(result.readiness, health.WithTimeout(check, checkTimeout))
+	}
+	return result
+}
+
+func (s *healthService) checkShutdown(ctx context.Context) error {
+	if atomic.LoadInt32(&s.shuttingDown) == 1 {
+		return errors.New("server is shutting down")
+	}
+	return nil
+}
+
+func (s *healthService) Liveness(ctx context.Context) health.Report {
+	return health.Run(ctx, s.liveness)
+}
+
+func (s *healthService) Readiness(ctx context.Context) health.Report {
+	return health.Run(ctx, s.readiness)
+}
+
+func (s *healthService) SetShuttingDown() {
+	atomic.StoreInt32(&s.shuttingDown, 1)
+}
+
+func NewConfigCheck(cfg config.Config) health.Check {
+	return health.NewCheck("config", func(ctx context.Context) error {
+		return cfg.Validate()
+	})
+}
+
+func NewProviderTokenCheck(name string, checker providers.CredentialsChecker) health.Check {
+	return health.Cached(health.NewCheck(name+"_token", checker.CheckCredentials), tokenCheckTtl, tokenCheckFailureTtl)
+}
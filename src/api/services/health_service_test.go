@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/health"
+	"testing"
+	"time"
+)
+
+func TestReadinessFlipsOnShutdown(t *testing.T) {
+	service := NewHealthService(health.NewCheck("audit_sink", func(ctx context.Context) error { return nil }))
+
+	report := service.Readiness(context.Background())
+	assert.True(t, report.Ok())
+	assert.EqualValues(t, 2, len(report.Checks))
+
+	service.SetShuttingDown()
+
+	report = service.Readiness(context.Background())
+	assert.False(t, report.Ok())
+	assert.EqualValues(t, "server is shutting down", report.Checks[0].Error)
+	assert.True(t, service.Liveness(context.Background()).Ok())
+}
+
+func TestReadinessFailingCheck(t *testing.T) {
+	service := NewHealthService(health.NewCheck("audit_sink", func(ctx context.Context) error {
+		return errors.New("file closed")
+	}))
+
+	report := service.Readiness(context.Background())
+	assert.False(t, report.Ok())
+	assert.EqualValues(t, "audit_sink", report.Checks[1].Name)
+	assert.EqualValues(t, "file closed", report.Checks[1].Error)
+}
+
+type credentialsChecker struct {
+	calls int
+	err   error
+}
+
+func (c *credentialsChecker) CheckCredentials(ctx context.Context) error {
+	c.calls++
+	return c.err
+}
+
+func TestProviderTokenCheck(t *testing.T) {
+	checker := &credentialsChecker{err: errors.New("gitlab access token is invalid: 401 Unauthorized")}
+	check := NewProviderTokenCheck("gitlab", checker)
+
+	assert.EqualValues(t, "gitlab_token", check.Name())
+	assert.EqualValues(t, "gitlab access token is invalid: 401 Unauthorized", check.Check(context.Background()).Error())
+	assert.NotNil(t, check.Check(context.Background()))
+	assert.EqualValues(t, 1, checker.calls)
+}
+
+func TestReadinessCheckTimesOut(t *testing.T) {
+	service := NewHealthService(health.NewCheck("audit_sink", func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.True(t, deadline.Before(time.Now().Add(checkTimeout+time.Second)))
+		return nil
+	}))
+
+	assert.True(t, service.Readiness(context.Background()).Ok())
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/tracing"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"strings"
+)
+
+// grantAccess applies the grants in order and stops at the first failure,
+// the response holds what was applied so far. Grants are idempotent so the
+// whole request can be retried.
+func grantAccess(ctx context.Context, manager providers.AccessManager, owner string, name string, input repositories.AccessRequest) (*repositories.AccessResponse, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.grantAccess")
+	defer span.End()
+
+	res := &repositories.AccessResponse{
+		Granted: make([]repositories.AccessGrant, 0, len(input.Teams)+len(input.Collaborators)),
+		Invited: make([]repositories.AccessGrant, 0),
+	}
+	for _, team := range input.Teams {
+		if err := manager.AddTeam(ctx, owner, name, team.Slug, team.Permission); err != nil {
+			span.SetError(err.Message())
+			return res, errors.NewApiError(err.Status(), "team "+team.Slug+": "+err.Message())
+		}
+		res.Granted = append(res.Granted, repositories.AccessGrant{
+			Kind:       repositories.AccessKindTeam,
+			Name:       team.Slug,
+			Permission: team.Permission,
+		})
+	}
+	for _, collaborator := range input.Collaborators {
+		invitationId, err := manager.AddCollaborator(ctx, owner, name, collaborator.Username, collaborator.Permission)
+		if err != nil {
+			span.SetError(err.Message())
+			return res, errors.NewApiError(err.Status(), "collaborator "+collaborator.Username+": "+err.Message())
+		}
+		grant := repositories.AccessGrant{
+			Kind:         repositories.AccessKindCollaborator,
+			Name:         collaborator.Username,
+			Permission:   collaborator.Permission,
+			InvitationId: invitationId,
+		}
+		if invitationId != 0 {
+			res.Invited = append(res.Invited, grant)
+		} else {
+			res.Granted = append(res.Granted, grant)
+		}
+	}
+	return res, nil
+}
+
+// GrantAccess returns the grants applied before a failure along with the
+// error.
+func (s *reposService) GrantAccess(ctx context.Context, providerName string, owner string, name string, input repositories.AccessRequest) (*repositories.AccessResponse, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.GrantAccess")
+	defer span.End()
+	span.SetAttribute("repository.owner", owner)
+	span.SetAttribute("repository.name", name)
+
+	owner, name = strings.TrimSpace(owner), strings.TrimSpace(name)
+	if owner == "" || name == "" {
+		return nil, errors.NewBadRequestApiError("invalid repository owner or name")
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if input.IsEmpty() {
+		return nil, errors.NewBadRequestApiError("nothing to grant")
+	}
+
+	providerName, provider, err := s.resolveProvider(ctx, providerName, owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	manager, ok := provider.(providers.AccessManager)
+	if !ok {
+		return nil, errors.NewBadRequestApiError("provider " + providerName + " does not support access management")
+	}
+
+	res, err := grantAccess(ctx, manager, owner, name, input)
+	if err != nil {
+		return res, err
+	}
+
+	logger.FromContext(ctx).Info("repository access granted",
+		logger.Any("full_name", owner+"/"+name),
+		logger.Any("granted", len(res.Granted)),
+		logger.Any("invited", len(res.Invited)),
+	)
+	return res, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func mockAccess(client *restclient.Client, url string, statusCode int, body string) {
+	client.AddMock(&restclient.Mock{
+		Url:        url,
+		HttpMethod: http.MethodPut,
+		Response:   &http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(body))},
+	})
+}
+
+func TestGrantAccess(t *testing.T) {
+	registry, client := newMockedProviders()
+	mockAccess(client, "https://api.github.com/orgs/my-org/teams/core/repos/my-org/payments", http.StatusNoContent, "")
+	mockAccess(client, "https://api.github.com/repos/my-org/payments/collaborators/octocat", http.StatusCreated, `{"id": 42}`)
+	mockAccess(client, "https://api.github.com/repos/my-org/payments/collaborators/hubot", http.StatusNoContent, "")
+	service := NewRepositoryService(ReposDependencies{Providers: registry})
+
+	res, err := service.GrantAccess(context.Background(), "", "my-org", "payments", repositories.AccessRequest{
+		Teams:         []repositories.TeamAccess{{Slug: "core", Permission: "push"}},
+		Collaborators: []repositories.CollaboratorAccess{{Username: "octocat"}, {Username: "hubot", Permission: "admin"}},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []repositories.AccessGrant{
+		{Kind: repositories.AccessKindTeam, Name: "core", Permission: "push"},
+		{Kind: repositories.AccessKindCollaborator, Name: "hubot", Permission: "admin"},
+	}, res.Granted)
+	assert.EqualValues(t, []repositories.AccessGrant{
+		{Kind: repositories.AccessKindCollaborator, Name: "octocat", Permission: "pull", InvitationId: 42},
+	}, res.Invited)
+}
+
+func TestGrantAccessErrors(t *testing.T) {
+	registry, client := newMockedProviders()
+	mockAccess(client, "https://api.github.com/orgs/my-org/teams/core/repos/my-org/payments", http.StatusNoContent, "")
+	mockAccess(client, "https://api.github.com/orgs/my-org/teams/missing/repos/my-org/payments", http.StatusNotFound, `{"message": "Not Found"}`)
+	service := NewRepositoryService(ReposDependencies{Providers: registry})
+
+	res, err := service.GrantAccess(context.Background(), "", "my-org", "payments", repositories.AccessRequest{})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "nothing to grant", err.Message())
+
+	res, err = service.GrantAccess(context.Background(), "", "my-org", "payments", repositories.AccessRequest{
+		Teams: []repositories.TeamAccess{{Slug: "core", Permission: "push"}, {Slug: "missing"}},
+	})
+	assert.EqualValues(t, []repositories.AccessGrant{
+		{Kind: repositories.AccessKindTeam, Name: "core", Permission: "push"},
+	}, res.Granted)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "team missing: Not Found", err.Message())
+
+	gitlab := providers.NewRegistry(providers.Gitlab, nil)
+	gitlab.Register(providers.Gitlab, &stubProvider{})
+	res, err = NewRepositoryService(ReposDependencies{Providers: gitlab}).GrantAccess(context.Background(), "", "my-org", "payments",
+		repositories.AccessRequest{Teams: []repositories.TeamAccess{{Slug: "core"}}})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "provider gitlab does not support access management", err.Message())
+}
+
+func TestCreateRepoWithAccessWarning(t *testing.T) {
+	service, client := newWebhooksService()
+	mockAccess(client, "https://api.github.com/orgs/my-org/teams/core/repos/my-org/payments", http.StatusNoContent, "")
+	mockAccess(client, "https://api.github.com/repos/my-org/payments/collaborators/ghost", http.StatusNotFound, `{"message": "Not Found"}`)
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name: "payments",
+		Org:  "my-org",
+		Access: &repositories.AccessRequest{
+			Teams:         []repositories.TeamAccess{{Slug: "core"}},
+			Collaborators: []repositories.CollaboratorAccess{{Username: "ghost"}},
+		},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "core", res.Access.Granted[0].Name)
+	assert.EqualValues(t, []string{"access was not fully granted: collaborator ghost: Not Found"}, res.Warnings)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateRepoUnknownActionsSecret(t *testing.T) {
+	service, _ := newWebhooksService()
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:    "payments",
+		Org:     "my-org",
+		Secrets: []repositories.ActionsSecret{{Name: "DEPLOY_KEY", Secret: "deploy"}},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "unknown secret deploy", err.Message())
+}
+
+func TestCreateRepoActionsSecretOutsideScope(t *testing.T) {
+	service, _ := newWebhooksService()
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"*"}})
+
+	res, err := service.CreateRepo(ctx, repositories.CreateRepoRequest{
+		Name:    "payments",
+		Org:     "other-org",
+		Secrets: []repositories.ActionsSecret{{Name: "DEPLOY_KEY", Secret: "ci-hook"}},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "caller is not allowed to use secret ci-hook", err.Message())
+
+	res, err = service.CreateRepo(ctx, repositories.CreateRepoRequest{
+		Name:    "payments",
+		Org:     "my-org",
+		Secrets: []repositories.ActionsSecret{{Name: "NPM_TOKEN", Secret: "npm"}},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "caller is not allowed to use secret npm", err.Message())
+}
+
+func TestCreateRepoActionsNotSupported(t *testing.T) {
+	registry := providers.NewRegistry(providers.Gitlab, nil)
+	registry.Register(providers.Gitlab, &stubProvider{})
+	service := NewRepositoryService(ReposDependencies{Providers: registry})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:      "payments",
+		Variables: []repositories.ActionsVariable{{Name: "GO_VERSION", Value: "1.17"}},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "provider gitlab does not support actions secrets and variables", err.Message())
+}
+
+func TestCreateRepoActionsSecretsFailWithWarning(t *testing.T) {
+	service, client := newWebhooksService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/actions/secrets/public-key",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Resource not accessible by integration"}`)),
+		},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/actions/variables",
+		HttpMethod: http.MethodPost,
+		Response:   &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(strings.NewReader(""))},
+	})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:      "payments",
+		Org:       "my-org",
+		Secrets:   []repositories.ActionsSecret{{Name: "deploy_key", Secret: "ci-hook"}},
+		Variables: []repositories.ActionsVariable{{Name: "go_version", Value: "1.17"}},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, res.Secrets)
+	assert.EqualValues(t, []string{"GO_VERSION"}, res.Variables)
+	assert.EqualValues(t, []string{"actions secrets were not set: Resource not accessible by integration"}, res.Warnings)
+}
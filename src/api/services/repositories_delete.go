@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/domain/headers"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/tracing"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeletePolicy guards repository removal. A repository can only be removed
+// when its owner is allowlisted, or when this service created it according to
+// the audit log or the topic marker, which is added to every new repository.
+type DeletePolicy struct {
+	AllowedOrgs         []string
+	TopicMarker         string
+	ArchiveOnly         bool
+	RequireConfirmation bool
+}
+
+func (p DeletePolicy) allowsOrg(owner string) bool {
+	for _, org := range p.AllowedOrgs {
+		if strings.EqualFold(org, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p DeletePolicy) checkConfirmation(confirm string, expected string) errors.ApiError {
+	if !p.RequireConfirmation || confirm == expected {
+		return nil
+	}
+	return errors.NewApiError(http.StatusPreconditionRequired,
+		fmt.Sprintf("confirmation required, set the %s header to %s", headers.ConfirmDelete, expected))
+}
+
+// markTopics adds the topic marker to the topics of a new repository, so the
+// policy recognizes it even once the audit log is gone.
+func (p DeletePolicy) markTopics(topics []string) []string {
+	if p.TopicMarker == "" {
+		return topics
+	}
+	for _, topic := range topics {
+		if strings.EqualFold(topic, p.TopicMarker) {
+			return topics
+		}
+	}
+	return append(append(make([]string, 0, len(topics)+1), topics...), p.TopicMarker)
+}
+
+// checkTopics rejects requested topics holding the topic marker, only the
+// service applies it.
+func (p DeletePolicy) checkTopics(topics []string) errors.ApiError {
+	if p.TopicMarker == "" {
+		return nil
+	}
+	for _, topic := range topics {
+		if strings.EqualFold(topic, p.TopicMarker) {
+			return errors.NewBadRequestApiError("topic " + p.TopicMarker + " is reserved")
+		}
+	}
+	return nil
+}
+
+// keepMarker adds the topic marker to topics replacing those of live when
+// live already carries it.
+func (p DeletePolicy) keepMarker(topics []string, live *repositories.Repository) []string {
+	if p.TopicMarker == "" || !hasTopic(live, p.TopicMarker) {
+		return topics
+	}
+	return p.markTopics(topics)
+}
+
+func hasTopic(repo *repositories.Repository, topic string) bool {
+	for _, current := range repo.Topics {
+		if strings.EqualFold(current, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// createdRepos reads the audit log once for owner and returns the lower cased
+// names of the repositories this service successfully created there. Owners
+// allowlisted by the policy are not looked up.
+func (s *reposService) createdRepos(ctx context.Context, owner string) map[string]bool {
+	created := make(map[string]bool)
+	if s.auditLog == nil || s.deletePolicy.allowsOrg(owner) {
+		return created
+	}
+
+	entries, err := s.auditLog.Query(repositories.AuditFilter{Owner: owner})
+	if err != nil {
+		logger.FromContext(ctx).Error("error when reading audit log", logger.Err(err))
+		return created
+	}
+	for _, entry := range entries {
+		if entry.IsCreation() && entry.Status == http.StatusCreated {
+			created[strings.ToLower(entry.FullName)] = true
+		}
+	}
+	return created
+}
+
+// checkDeletable applies the delete policy, created comes from createdRepos
+// and repo is the already fetched repository when there is one so batch
+// deletes do not fetch it twice.
+func (s *reposService) checkDeletable(ctx context.Context, provider providers.RepoProvider, owner string, name string, repo *repositories.Repository, created map[string]bool) errors.ApiError {
+	if s.deletePolicy.allowsOrg(owner) || created[strings.ToLower(owner+"/"+name)] {
+		return nil
+	}
+
+	if s.deletePolicy.TopicMarker != "" {
+		if repo == nil {
+			var err errors.ApiError
+			if repo, err = provider.GetRepo(ctx, owner, name); err != nil {
+				return err
+			}
+		}
+		if hasTopic(repo, s.deletePolicy.TopicMarker) {
+			return nil
+		}
+	}
+
+	return errors.NewForbiddenApiError("repository was not created by this service and its owner is not allowed for deletion")
+}
+
+func (s *reposService) DeleteRepo(ctx context.Context, input repositories.DeleteRepoRequest) (*repositories.DeleteRepoResponse, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.DeleteRepo")
+	defer span.End()
+	span.SetAttribute("repository.owner", input.Owner)
+	span.SetAttribute("repository.name", input.Name)
+
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.deletePolicy.checkConfirmation(input.Confirm, input.ConfirmationToken()); err != nil {
+		return nil, err
+	}
+
+	providerName, provider, err := s.resolveProvider(ctx, input.Provider, input.Owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+
+	created := s.createdRepos(ctx, input.Owner)
+	res, err := s.deleteRepo(ctx, providerName, provider, input.Owner, input.Name, input.Archive, nil, created)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *reposService) deleteRepo(ctx context.Context, providerName string, provider providers.RepoProvider, owner string, name string, archive bool, repo *repositories.Repository, created map[string]bool) (*repositories.DeleteRepoResponse, errors.ApiError) {
+	archive = archive || s.deletePolicy.ArchiveOnly
+
+	err := s.checkDeletable(ctx, provider, owner, name, repo, created)
+	if err == nil {
+		if archive {
+			archived := true
+			_, err = provider.UpdateRepo(ctx, owner, name, repositories.UpdateRepoRequest{Archived: &archived})
+		} else {
+			err = provider.DeleteRepo(ctx, owner, name)
+		}
+	}
+
+	res := &repositories.DeleteRepoResponse{
+		Owner:    owner,
+		Name:     name,
+		FullName: owner + "/" + name,
+		Provider: providerName,
+		Action:   repositories.ActionDeleted,
+	}
+	entry := repositories.AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Action:    repositories.AuditActionDelete,
+		BatchId:   audit.BatchIdFromContext(ctx),
+		Removal:   &repositories.AuditRemoval{Provider: providerName, Owner: owner, Name: name, Archive: archive},
+		Owner:     owner,
+		FullName:  res.FullName,
+		Status:    http.StatusOK,
+	}
+	if archive {
+		res.Action = repositories.ActionArchived
+		entry.Action = repositories.AuditActionArchive
+	}
+	if err != nil {
+		entry.Status = err.Status()
+		entry.Error = err.Message()
+	}
+	s.record(ctx, entry)
+	metrics.RepositoryDeletions.Inc(res.Action, strconv.Itoa(entry.Status))
+
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("repository removed",
+		logger.Any("full_name", res.FullName),
+		logger.Any("action", res.Action),
+		logger.Any("provider", providerName),
+	)
+	return res, nil
+}
+
+// listByPrefix walks every page of the owner repositories and keeps the ones
+// whose name starts with prefix.
+func listByPrefix(ctx context.Context, provider providers.RepoProvider, owner string, prefix string) ([]repositories.Repository, errors.ApiError) {
+	result := make([]repositories.Repository, 0)
+	options := repositories.ListReposOptions{Owner: owner, Page: 1, PerPage: repositories.MaxPerPage}
+	for {
+		page, err := provider.ListRepos(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range page.Repositories {
+			if strings.HasPrefix(repo.Name, prefix) {
+				result = append(result, repo)
+			}
+		}
+		if page.NextPage <= options.Page {
+			return result, nil
+		}
+		options.Page = page.NextPage
+	}
+}
+
+// DeleteRepos removes the matching repositories one after the other, the
+// policy is checked for each of them.
+func (s *reposService) DeleteRepos(ctx context.Context, input repositories.DeleteReposRequest) (*repositories.DeleteReposResponse, errors.ApiError) {
+	batchId := newBatchId()
+	ctx = audit.WithBatchId(ctx, batchId)
+
+	ctx, span := tracing.Start(ctx, "reposService.DeleteRepos")
+	defer span.End()
+	span.SetAttribute("batch.id", batchId)
+	span.SetAttribute("repository.owner", input.Owner)
+	span.SetAttribute("repository.prefix", input.Prefix)
+
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.deletePolicy.checkConfirmation(input.Confirm, input.ConfirmationToken()); err != nil {
+		return nil, err
+	}
+
+	providerName, provider, err := s.resolveProvider(ctx, input.Provider, input.Owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+
+	listOwner, err := s.listOwner(ctx, providerName, provider, input.Owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	repos, err := listByPrefix(ctx, provider, listOwner, input.Prefix)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	archive := input.Archive || s.deletePolicy.ArchiveOnly
+	created := s.createdRepos(ctx, input.Owner)
+
+	result := &repositories.DeleteReposResponse{Results: make([]repositories.DeleteRepositoriesResult, 0, len(repos))}
+	for i := range repos {
+		if archive && repos[i].Archived {
+			continue
+		}
+		res, err := s.deleteRepo(ctx, providerName, provider, input.Owner, repos[i].Name, archive, &repos[i], created)
+		result.Results = append(result.Results, repositories.DeleteRepositoriesResult{Response: res, Error: err})
+	}
+	span.SetAttribute("batch.size", strconv.Itoa(len(result.Results)))
+
+	if len(result.Results) == 0 {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("no repositories of %s match prefix %s", input.Owner, input.Prefix))
+	}
+
+	successes := 0
+	for _, current := range result.Results {
+		if current.Response != nil {
+			successes++
+		}
+	}
+
+	switch true {
+	case successes == 0:
+		result.StatusCode = result.Results[0].Error.Status()
+	case successes == len(result.Results):
+		result.StatusCode = http.StatusOK
+	default:
+		result.StatusCode = http.StatusPartialContent
+	}
+
+	return result, nil
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/utils/errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDeleteService(policy DeletePolicy) (ReposServiceInterface, *restclient.Client, *audit.MemorySink) {
+	sink := audit.NewMemorySink()
+	service, client := newFixture(withAccount("token-user"), withDependencies(func(deps *ReposDependencies) {
+		deps.AuditLog, deps.DeletePolicy = sink, policy
+	}))
+	return service, client, sink
+}
+
+func TestDeleteRepoRequiresConfirmation(t *testing.T) {
+	service, _, _ := newDeleteService(DeletePolicy{AllowedOrgs: []string{"my-org"}, RequireConfirmation: true})
+
+	res, err := service.DeleteRepo(context.Background(), repositories.DeleteRepoRequest{Owner: "my-org", Name: "testing_repo", Confirm: "my-org/other"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusPreconditionRequired, err.Status())
+	assert.EqualValues(t, "confirmation required, set the X-Confirm-Delete header to my-org/testing_repo", err.Message())
+}
+
+func TestDeleteRepoNotCreatedByService(t *testing.T) {
+	service, client, sink := newDeleteService(DeletePolicy{TopicMarker: "managed"})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 1, "name": "testing_repo", "topics": ["go"], "owner": {"login": "my-org"}}`)),
+		},
+	})
+
+	res, err := service.DeleteRepo(context.Background(), repositories.DeleteRepoRequest{Owner: "my-org", Name: "testing_repo"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	entries, _ := sink.Query(repositories.AuditFilter{})
+	assert.EqualValues(t, 1, len(entries))
+	assert.EqualValues(t, repositories.AuditActionDelete, entries[0].Action)
+	assert.EqualValues(t, http.StatusForbidden, entries[0].Status)
+}
+
+func TestDeleteRepoWithTopicMarker(t *testing.T) {
+	service, client, _ := newDeleteService(DeletePolicy{TopicMarker: "managed"})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 1, "name": "testing_repo", "topics": ["Managed"], "owner": {"login": "my-org"}}`)),
+		},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo",
+		HttpMethod: http.MethodDelete,
+		Response:   &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))},
+	})
+
+	res, err := service.DeleteRepo(context.Background(), repositories.DeleteRepoRequest{Owner: "my-org", Name: "testing_repo"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, &repositories.DeleteRepoResponse{
+		Owner:    "my-org",
+		Name:     "testing_repo",
+		FullName: "my-org/testing_repo",
+		Provider: "github",
+		Action:   repositories.ActionDeleted,
+	}, res)
+}
+
+func TestDeleteRepoCreatedByService(t *testing.T) {
+	service, client, sink := newDeleteService(DeletePolicy{})
+	_ = sink.Record(repositories.AuditEntry{Timestamp: time.Now(), Owner: "my-org", FullName: "my-org/testing_repo", Status: http.StatusCreated})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo",
+		HttpMethod: http.MethodDelete,
+		Response:   &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))},
+	})
+
+	res, err := service.DeleteRepo(context.Background(), repositories.DeleteRepoRequest{Owner: "my-org", Name: "testing_repo"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, repositories.ActionDeleted, res.Action)
+}
+
+func TestDeleteRepoArchiveOnly(t *testing.T) {
+	service, client, sink := newDeleteService(DeletePolicy{AllowedOrgs: []string{"My-Org"}, ArchiveOnly: true})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 1, "name": "testing_repo", "archived": true, "owner": {"login": "my-org"}}`)),
+		},
+	})
+
+	res, err := service.DeleteRepo(context.Background(), repositories.DeleteRepoRequest{Owner: "my-org", Name: "testing_repo"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, repositories.ActionArchived, res.Action)
+
+	entries, _ := sink.Query(repositories.AuditFilter{Owner: "my-org"})
+	assert.EqualValues(t, repositories.AuditActionArchive, entries[0].Action)
+	assert.EqualValues(t, http.StatusOK, entries[0].Status)
+	assert.Nil(t, entries[0].Request)
+	assert.EqualValues(t, &repositories.AuditRemoval{Provider: "github", Owner: "my-org", Name: "testing_repo", Archive: true}, entries[0].Removal)
+}
+
+func TestDeleteReposByPrefix(t *testing.T) {
+	service, client, _ := newDeleteService(DeletePolicy{AllowedOrgs: []string{"my-org"}, RequireConfirmation: true})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos?page=1&per_page=100",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(`[
+				{"id": 1, "name": "ci-one", "owner": {"login": "my-org"}},
+				{"id": 2, "name": "keep", "owner": {"login": "my-org"}},
+				{"id": 3, "name": "ci-two", "owner": {"login": "my-org"}}
+			]`)),
+		},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/ci-one",
+		HttpMethod: http.MethodDelete,
+		Response:   &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/ci-two",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Must have admin rights to Repository."}`)),
+		},
+	})
+
+	res, err := service.DeleteRepos(context.Background(), repositories.DeleteReposRequest{Owner: "my-org", Prefix: "ci-", Confirm: "my-org/ci-*"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, res.StatusCode)
+	assert.EqualValues(t, 2, len(res.Results))
+	assert.EqualValues(t, "my-org/ci-one", res.Results[0].Response.FullName)
+	assert.EqualValues(t, "Must have admin rights to Repository.", res.Results[1].Error.Message())
+}
+
+type countingLog struct {
+	*audit.MemorySink
+	queries int
+}
+
+func (l *countingLog) Query(filter repositories.AuditFilter) ([]repositories.AuditEntry, error) {
+	l.queries++
+	return l.MemorySink.Query(filter)
+}
+
+func TestDeleteReposReadsAuditLogOnce(t *testing.T) {
+	auditLog := &countingLog{MemorySink: audit.NewMemorySink()}
+	for _, name := range []string{"ci-one", "ci-two"} {
+		_ = auditLog.Record(repositories.AuditEntry{Action: repositories.AuditActionCreate, Owner: "my-org", FullName: "my-org/" + name, Status: http.StatusCreated})
+	}
+	service, client := newFixture(withAccount("token-user"), withDependencies(func(deps *ReposDependencies) { deps.AuditLog = auditLog }))
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos?page=1&per_page=100",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(`[
+				{"id": 1, "name": "ci-one", "owner": {"login": "my-org"}},
+				{"id": 3, "name": "ci-Two", "owner": {"login": "my-org"}}
+			]`)),
+		},
+	})
+	for _, name := range []string{"ci-one", "ci-Two"} {
+		client.AddMock(&restclient.Mock{
+			Url:        "https://api.github.com/repos/my-org/" + name,
+			HttpMethod: http.MethodDelete,
+			Response:   &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))},
+		})
+	}
+
+	res, err := service.DeleteRepos(context.Background(), repositories.DeleteReposRequest{Owner: "my-org", Prefix: "ci-"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, res.StatusCode)
+	assert.EqualValues(t, 2, len(res.Results))
+	assert.EqualValues(t, 1, auditLog.queries)
+}
+
+func TestDeleteReposNoMatch(t *testing.T) {
+	service, client, _ := newDeleteService(DeletePolicy{AllowedOrgs: []string{"my-org"}})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos?page=1&per_page=100",
+		HttpMethod: http.MethodGet,
+		Response:   &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`[]`))},
+	})
+
+	res, err := service.DeleteRepos(context.Background(), repositories.DeleteReposRequest{Owner: "my-org", Prefix: "ci-"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "no repositories of my-org match prefix ci-", err.Message())
+}
+
+type topicsStub struct {
+	stubProvider
+	topics []string
+}
+
+func (p *topicsStub) SetTopics(ctx context.Context, owner string, name string, topics []string) ([]string, errors.ApiError) {
+	p.topics = topics
+	return topics, nil
+}
+
+func TestCreateRepoAppliesTopicMarker(t *testing.T) {
+	registry := providers.NewRegistry(providers.Github, nil)
+	provider := &topicsStub{}
+	registry.Register(providers.Github, provider)
+	service := NewRepositoryService(ReposDependencies{Providers: registry, DeletePolicy: DeletePolicy{TopicMarker: "managed"}})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"managed"}, provider.topics)
+	assert.EqualValues(t, []string{"managed"}, res.Topics)
+
+	_, err = service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org", Topics: []string{"go"}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go", "managed"}, provider.topics)
+
+	topics := make([]string, repositories.MaxTopics)
+	for i := range topics {
+		topics[i] = fmt.Sprintf("topic-%d", i)
+	}
+	res, err = service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org", Topics: topics})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "too many topics, at most 19 are allowed besides the delete marker", err.Message())
+}
+
+func TestCreateRepoWithoutTopicsSupportSkipsMarker(t *testing.T) {
+	registry := providers.NewRegistry(providers.Github, nil)
+	registry.Register(providers.Github, &stubProvider{})
+	service := NewRepositoryService(ReposDependencies{Providers: registry, DeletePolicy: DeletePolicy{TopicMarker: "managed"}})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org"})
+	assert.Nil(t, err)
+	assert.Nil(t, res.Topics)
+	assert.Empty(t, res.Warnings)
+}
+
+type markedStub struct {
+	topicsStub
+	live []string
+}
+
+func (p *markedStub) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	return &repositories.Repository{Owner: owner, Name: name, FullName: owner + "/" + name, Topics: p.live}, nil
+}
+
+func TestTopicMarkerIsReserved(t *testing.T) {
+	registry := providers.NewRegistry(providers.Github, nil)
+	provider := &markedStub{live: []string{"legacy", "managed"}}
+	registry.Register(providers.Github, provider)
+	service := NewRepositoryService(ReposDependencies{Providers: registry, DeletePolicy: DeletePolicy{TopicMarker: "managed"}})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org", Topics: []string{"Managed"}})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "topic managed is reserved", err.Message())
+
+	synced, err := service.SyncRepo(context.Background(), "", "my-org", "payments", repositories.SyncRequest{Topics: []string{"go", "managed"}})
+	assert.Nil(t, synced)
+	assert.EqualValues(t, "topic managed is reserved", err.Message())
+
+	synced, err = service.SyncRepo(context.Background(), "", "my-org", "payments", repositories.SyncRequest{Topics: []string{"go"}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go", "managed"}, synced.Topics)
+
+	provider.live = []string{"legacy"}
+	synced, err = service.SyncRepo(context.Background(), "", "my-org", "payments", repositories.SyncRequest{Topics: []string{"go"}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go"}, synced.Topics)
+
+	_, err = service.Reconcile(context.Background(), repositories.Manifest{
+		Repositories: []repositories.ManifestRepository{{Owner: "my-org", Name: "payments", Topics: []string{"managed"}}},
+	}, true)
+	assert.EqualValues(t, "my-org/payments: topic managed is reserved", err.Message())
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/tracing"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"strings"
+)
+
+func isDefaultLabel(name string) bool {
+	for _, label := range repositories.DefaultLabels {
+		if strings.EqualFold(label, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncLabels makes the repository labels match set. Labels are matched by
+// name regardless of case, like GitHub does. It stops at the first failure,
+// the result holds what was applied so far.
+func syncLabels(ctx context.Context, manager providers.LabelManager, owner string, name string, set []repositories.Label, removeDefaults bool) (*repositories.LabelSyncResult, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.syncLabels")
+	defer span.End()
+
+	current, err := manager.ListLabels(ctx, owner, name)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	existing := make(map[string]repositories.Label, len(current))
+	for _, label := range current {
+		existing[strings.ToLower(label.Name)] = label
+	}
+
+	result := &repositories.LabelSyncResult{Created: make([]string, 0), Updated: make([]string, 0), Deleted: make([]string, 0)}
+	wanted := make(map[string]bool, len(set))
+	for _, label := range set {
+		key := strings.ToLower(label.Name)
+		wanted[key] = true
+
+		live, ok := existing[key]
+		switch {
+		case !ok:
+			if err := manager.CreateLabel(ctx, owner, name, label); err != nil {
+				span.SetError(err.Message())
+				return result, labelError(label.Name, err)
+			}
+			result.Created = append(result.Created, label.Name)
+		case live.Name != label.Name || !strings.EqualFold(live.Color, label.Color) || live.Description != label.Description:
+			if err := manager.UpdateLabel(ctx, owner, name, live.Name, label); err != nil {
+				span.SetError(err.Message())
+				return result, labelError(label.Name, err)
+			}
+			result.Updated = append(result.Updated, label.Name)
+		}
+	}
+
+	if removeDefaults {
+		for _, label := range current {
+			if wanted[strings.ToLower(label.Name)] || !isDefaultLabel(label.Name) {
+				continue
+			}
+			if err := manager.DeleteLabel(ctx, owner, name, label.Name); err != nil {
+				span.SetError(err.Message())
+				return result, labelError(label.Name, err)
+			}
+			result.Deleted = append(result.Deleted, label.Name)
+		}
+	}
+	return result, nil
+}
+
+func labelError(label string, err errors.ApiError) errors.ApiError {
+	return errors.NewApiError(err.Status(), "label "+label+": "+err.Message())
+}
+
+// labelSet resolves the set of a sync request and checks the provider can
+// apply it.
+func (s *reposService) labelSet(providerName string, provider providers.RepoProvider, sync *repositories.LabelSync) ([]repositories.Label, errors.ApiError) {
+	if _, ok := provider.(providers.LabelManager); !ok {
+		return nil, errors.NewBadRequestApiError("provider " + providerName + " does not support labels")
+	}
+	set, ok := s.labels.Get(sync.Set)
+	if !ok {
+		return nil, errors.NewBadRequestApiError("unknown label set " + sync.Set)
+	}
+	return set, nil
+}
+
+func checkTopicsSupported(providerName string, provider providers.RepoProvider) errors.ApiError {
+	if _, ok := provider.(providers.TopicsSetter); !ok {
+		return errors.NewBadRequestApiError("provider " + providerName + " does not support topics")
+	}
+	return nil
+}
+
+// labelNewRepo applies the label set and topics of a creation request along
+// with the delete marker topic, failures are reported as warnings.
+func (s *reposService) labelNewRepo(ctx context.Context, provider providers.RepoProvider, input *repositories.CreateRepoRequest, repo *repositories.Repository, res *repositories.CreateRepoResponse) {
+	if input.Labels != nil {
+		set, _ := s.labels.Get(input.Labels.Set)
+		result, err := syncLabels(ctx, provider.(providers.LabelManager), repo.Owner, repo.Name, set, input.Labels.RemoveDefaults)
+		if err != nil {
+			logger.FromContext(ctx).Warn("error when syncing labels",
+				logger.Any("full_name", repo.FullName),
+				logger.Any("message", err.Message()),
+			)
+			res.Warnings = append(res.Warnings, "labels were not fully synced: "+err.Message())
+		}
+		res.Labels = result
+	}
+
+	setter, ok := provider.(providers.TopicsSetter)
+	if !ok {
+		if s.deletePolicy.TopicMarker != "" {
+			logger.FromContext(ctx).Warn("provider does not support topics, the delete marker is not applied",
+				logger.Any("full_name", repo.FullName),
+			)
+		}
+		return
+	}
+	if topics := s.deletePolicy.markTopics(input.Topics); len(topics) > 0 {
+		applied, err := setter.SetTopics(ctx, repo.Owner, repo.Name, topics)
+		if err != nil {
+			logger.FromContext(ctx).Warn("error when setting topics",
+				logger.Any("full_name", repo.FullName),
+				logger.Any("message", err.Message()),
+			)
+			res.Warnings = append(res.Warnings, "topics were not set: "+err.Message())
+			return
+		}
+		res.Topics = applied
+	}
+}
+
+// SyncRepo applies a label set and replaces the topics of an existing
+// repository, keeping the delete marker topic when the repository has it.
+func (s *reposService) SyncRepo(ctx context.Context, providerName string, owner string, name string, input repositories.SyncRequest) (*repositories.SyncResponse, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.SyncRepo")
+	defer span.End()
+	span.SetAttribute("repository.owner", owner)
+	span.SetAttribute("repository.name", name)
+
+	owner, name = strings.TrimSpace(owner), strings.TrimSpace(name)
+	if owner == "" || name == "" {
+		return nil, errors.NewBadRequestApiError("invalid repository owner or name")
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	providerName, provider, err := s.resolveProvider(ctx, providerName, owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	var set []repositories.Label
+	if input.Labels != nil {
+		if set, err = s.labelSet(providerName, provider, input.Labels); err != nil {
+			return nil, err
+		}
+	}
+	if input.Topics != nil {
+		if err := checkTopicsSupported(providerName, provider); err != nil {
+			return nil, err
+		}
+		if err := s.deletePolicy.checkTopics(input.Topics); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &repositories.SyncResponse{}
+	if input.Labels != nil {
+		if res.Labels, err = syncLabels(ctx, provider.(providers.LabelManager), owner, name, set, input.Labels.RemoveDefaults); err != nil {
+			span.SetError(err.Message())
+			return nil, err
+		}
+	}
+	if input.Topics != nil {
+		topics := input.Topics
+		if s.deletePolicy.TopicMarker != "" {
+			live, err := provider.GetRepo(ctx, owner, name)
+			if err != nil {
+				span.SetError(err.Message())
+				return nil, err
+			}
+			topics = s.deletePolicy.keepMarker(topics, live)
+		}
+		if res.Topics, err = provider.(providers.TopicsSetter).SetTopics(ctx, owner, name, topics); err != nil {
+			span.SetError(err.Message())
+			return nil, err
+		}
+	}
+
+	logger.FromContext(ctx).Info("repository labels and topics synced",
+		logger.Any("full_name", owner+"/"+name),
+		logger.Any("label_set", input.Labels != nil),
+		logger.Any("topics", len(res.Topics)),
+	)
+	return res, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/labels"
+	"golang-microservices/src/api/utils/errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type labelManagerStub struct {
+	labels []repositories.Label
+	calls  []string
+	fail   string
+}
+
+func (m *labelManagerStub) ListLabels(ctx context.Context, owner string, name string) ([]repositories.Label, errors.ApiError) {
+	return m.labels, nil
+}
+
+func (m *labelManagerStub) CreateLabel(ctx context.Context, owner string, name string, label repositories.Label) errors.ApiError {
+	if label.Name == m.fail {
+		return errors.NewApiError(http.StatusUnprocessableEntity, "Validation Failed")
+	}
+	m.calls = append(m.calls, "create "+label.Name)
+	return nil
+}
+
+func (m *labelManagerStub) UpdateLabel(ctx context.Context, owner string, name string, current string, label repositories.Label) errors.ApiError {
+	m.calls = append(m.calls, "update "+current+" to "+label.Name)
+	return nil
+}
+
+func (m *labelManagerStub) DeleteLabel(ctx context.Context, owner string, name string, label string) errors.ApiError {
+	m.calls = append(m.calls, "delete "+label)
+	return nil
+}
+
+func TestSyncLabels(t *testing.T) {
+	manager := &labelManagerStub{labels: []repositories.Label{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "Incident", Color: "B60205"},
+		{Name: "wontfix", Color: "ffffff"},
+		{Name: "team-made", Color: "000000"},
+	}}
+	set := []repositories.Label{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "incident", Color: "b60205"},
+		{Name: "needs-triage", Color: "fbca04"},
+	}
+
+	res, err := syncLabels(context.Background(), manager, "my-org", "payments", set, true)
+	assert.Nil(t, err)
+	assert.EqualValues(t, &repositories.LabelSyncResult{
+		Created: []string{"needs-triage"},
+		Updated: []string{"incident"},
+		Deleted: []string{"wontfix"},
+	}, res)
+	assert.EqualValues(t, []string{"update Incident to incident", "create needs-triage", "delete wontfix"}, manager.calls)
+}
+
+func TestSyncLabelsStopsAtFirstFailure(t *testing.T) {
+	manager := &labelManagerStub{fail: "broken"}
+	set := []repositories.Label{{Name: "ok", Color: "ededed"}, {Name: "broken", Color: "ededed"}, {Name: "never", Color: "ededed"}}
+
+	res, err := syncLabels(context.Background(), manager, "my-org", "payments", set, false)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.Status())
+	assert.EqualValues(t, "label broken: Validation Failed", err.Message())
+	assert.EqualValues(t, []string{"ok"}, res.Created)
+}
+
+func TestSyncRepo(t *testing.T) {
+	registry, client := newMockedProviders()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/labels?page=1&per_page=100",
+		HttpMethod: http.MethodGet,
+		Response:   &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`[{"name": "bug", "color": "d73a4a"}]`))},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/labels",
+		HttpMethod: http.MethodPost,
+		Response:   &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(strings.NewReader(`{}`))},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/labels/bug",
+		HttpMethod: http.MethodDelete,
+		Response:   &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/topics",
+		HttpMethod: http.MethodPut,
+		Response:   &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"names": ["go"]}`))},
+	})
+	store := labels.NewStore(map[string][]repositories.Label{"platform": {{Name: "incident", Color: "b60205"}}})
+	service := NewRepositoryService(ReposDependencies{Providers: registry, Labels: store})
+
+	res, err := service.SyncRepo(context.Background(), "", "my-org", "payments", repositories.SyncRequest{
+		Labels: &repositories.LabelSync{Set: "platform", RemoveDefaults: true},
+		Topics: []string{"Go"},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, &repositories.SyncResponse{
+		Labels: &repositories.LabelSyncResult{Created: []string{"incident"}, Updated: []string{}, Deleted: []string{"bug"}},
+		Topics: []string{"go"},
+	}, res)
+
+	res, err = service.SyncRepo(context.Background(), "", "my-org", "payments", repositories.SyncRequest{
+		Labels: &repositories.LabelSync{Set: "missing"},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "unknown label set missing", err.Message())
+}
+
+func TestCreateRepoTopicsWarning(t *testing.T) {
+	service, client := newWebhooksService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/topics",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Validation Failed"}`)),
+		},
+	})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org", Topics: []string{"go"}})
+	assert.Nil(t, err)
+	assert.Nil(t, res.Topics)
+	assert.EqualValues(t, []string{"topics were not set: Validation Failed"}, res.Warnings)
+}
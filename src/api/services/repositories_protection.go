@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/tracing"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"strings"
+)
+
+func (s *reposService) resolvePolicy(name string) (repositories.ProtectionPolicy, errors.ApiError) {
+	if name == "" {
+		name = s.defaultProtection
+	}
+	if name == "" {
+		return repositories.ProtectionPolicy{}, errors.NewBadRequestApiError("no protection policy given and no default policy configured")
+	}
+	policy, ok := s.protection.Get(name)
+	if !ok {
+		return repositories.ProtectionPolicy{}, errors.NewBadRequestApiError("unknown protection policy " + name)
+	}
+	return policy, nil
+}
+
+// protectionPolicy gives the policy for the default branch of a new
+// repository, empty when the provider cannot protect branches.
+func (s *reposService) protectionPolicy(provider providers.RepoProvider, input *repositories.CreateRepoRequest) string {
+	if _, ok := provider.(providers.BranchProtector); !ok {
+		return ""
+	}
+	if input.Protection != "" {
+		return input.Protection
+	}
+	return s.defaultProtection
+}
+
+// protectNewRepo applies the requested or default policy to the default
+// branch of a new repository. The branch exists once the template files are
+// committed or, without templates, through the initial commit of AutoInit.
+func (s *reposService) protectNewRepo(ctx context.Context, provider providers.RepoProvider, input *repositories.CreateRepoRequest, repo *repositories.Repository, res *repositories.CreateRepoResponse) {
+	policyName := s.protectionPolicy(provider, input)
+	if policyName == "" {
+		return
+	}
+	protector := provider.(providers.BranchProtector)
+	if len(res.Files) == 0 && !input.AutoInit {
+		res.Warnings = append(res.Warnings, "branch protection was not applied: the repository has no branch yet")
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, "reposService.protectNewRepo")
+	defer span.End()
+
+	policy, err := s.resolvePolicy(policyName)
+	if err == nil {
+		err = protector.SetBranchProtection(ctx, repo.Owner, repo.Name, repo.DefaultBranch, policy)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warn("error when protecting default branch",
+			logger.Any("full_name", repo.FullName),
+			logger.Any("policy", policyName),
+			logger.Any("message", err.Message()),
+		)
+		span.SetError(err.Message())
+		res.Warnings = append(res.Warnings, "branch protection was not applied: "+err.Message())
+		return
+	}
+	res.Protected = policyName
+}
+
+// protectionTarget resolves everything a protection request needs, the
+// branch defaults to the default branch of the repository.
+func (s *reposService) protectionTarget(ctx context.Context, providerName string, owner string, name string, input *repositories.ProtectionRequest) (providers.BranchProtector, repositories.ProtectionPolicy, errors.ApiError) {
+	if owner == "" || name == "" {
+		return nil, repositories.ProtectionPolicy{}, errors.NewBadRequestApiError("invalid repository owner or name")
+	}
+	if err := input.Validate(); err != nil {
+		return nil, repositories.ProtectionPolicy{}, err
+	}
+
+	policy, err := s.resolvePolicy(input.Policy)
+	if err != nil {
+		return nil, policy, err
+	}
+	providerName, provider, err := s.resolveProvider(ctx, providerName, owner)
+	if err != nil {
+		return nil, policy, err
+	}
+	protector, ok := provider.(providers.BranchProtector)
+	if !ok {
+		return nil, policy, errors.NewBadRequestApiError("provider " + providerName + " does not support branch protection")
+	}
+
+	if input.Branch == "" {
+		repo, err := provider.GetRepo(ctx, owner, name)
+		if err != nil {
+			return nil, policy, err
+		}
+		input.Branch = repo.DefaultBranch
+	}
+	return protector, policy, nil
+}
+
+func (s *reposService) ApplyProtection(ctx context.Context, providerName string, owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.ApplyProtection")
+	defer span.End()
+	span.SetAttribute("repository.owner", owner)
+	span.SetAttribute("repository.name", name)
+
+	owner, name = strings.TrimSpace(owner), strings.TrimSpace(name)
+	protector, policy, err := s.protectionTarget(ctx, providerName, owner, name, &input)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	if err := protector.SetBranchProtection(ctx, owner, name, input.Branch, policy); err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("branch protection applied",
+		logger.Any("full_name", owner+"/"+name),
+		logger.Any("branch", input.Branch),
+		logger.Any("policy", policy.Name),
+	)
+	return &repositories.ProtectionReport{
+		Owner:  owner,
+		Name:   name,
+		Branch: input.Branch,
+		Policy: policy.Name,
+		InSync: true,
+		Drift:  make([]repositories.ProtectionDrift, 0),
+	}, nil
+}
+
+// GetProtectionDrift compares the live protection of the branch with the
+// policy without changing anything.
+func (s *reposService) GetProtectionDrift(ctx context.Context, providerName string, owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.GetProtectionDrift")
+	defer span.End()
+	span.SetAttribute("repository.owner", owner)
+	span.SetAttribute("repository.name", name)
+
+	owner, name = strings.TrimSpace(owner), strings.TrimSpace(name)
+	protector, policy, err := s.protectionTarget(ctx, providerName, owner, name, &input)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	live, err := protector.GetBranchProtection(ctx, owner, name, input.Branch, policy)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+
+	drift := policy.Diff(*live)
+	return &repositories.ProtectionReport{
+		Owner:  owner,
+		Name:   name,
+		Branch: input.Branch,
+		Policy: policy.Name,
+		InSync: len(drift) == 0,
+		Drift:  drift,
+	}, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/protection"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/utils/errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newProtectionService(defaultPolicy string) (ReposServiceInterface, *restclient.Client) {
+	store := protection.NewStore(map[string]repositories.ProtectionPolicy{
+		"strict": {BranchProtection: repositories.BranchProtection{RequiredReviews: 2, BlockForcePushes: true}},
+	})
+	return newFixture(
+		withCreatedRepo(),
+		withMock(http.MethodGet, "https://api.github.com/repos/my-org/payments", http.StatusOK,
+			`{"id": 1, "name": "payments", "default_branch": "trunk", "owner": {"login": "my-org"}}`),
+		withDependencies(func(deps *ReposDependencies) { deps.Protection, deps.DefaultProtection = store, defaultPolicy }),
+	)
+}
+
+func TestApplyProtectionOnDefaultBranch(t *testing.T) {
+	service, client := newProtectionService("strict")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/branches/trunk/protection",
+		HttpMethod: http.MethodPut,
+		Response:   &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{}`))},
+	})
+
+	res, err := service.ApplyProtection(context.Background(), "", "my-org", "payments", repositories.ProtectionRequest{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, &repositories.ProtectionReport{
+		Owner:  "my-org",
+		Name:   "payments",
+		Branch: "trunk",
+		Policy: "strict",
+		InSync: true,
+		Drift:  []repositories.ProtectionDrift{},
+	}, res)
+}
+
+func TestGetProtectionDrift(t *testing.T) {
+	service, client := newProtectionService("")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/branches/release/protection",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"required_pull_request_reviews": {"required_approving_review_count": 2}, "allow_force_pushes": {"enabled": true}, "allow_deletions": {"enabled": true}}`,
+			)),
+		},
+	})
+
+	res, err := service.GetProtectionDrift(context.Background(), "", "my-org", "payments",
+		repositories.ProtectionRequest{Policy: "strict", Branch: "release"})
+	assert.Nil(t, err)
+	assert.False(t, res.InSync)
+	assert.EqualValues(t, []repositories.ProtectionDrift{
+		{Field: "block_force_pushes", Expected: true, Actual: false},
+	}, res.Drift)
+}
+
+func TestProtectionPolicyErrors(t *testing.T) {
+	service, _ := newProtectionService("")
+
+	res, err := service.GetProtectionDrift(context.Background(), "", "my-org", "payments", repositories.ProtectionRequest{})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "no protection policy given and no default policy configured", err.Message())
+
+	res, err = service.ApplyProtection(context.Background(), "", "my-org", "payments", repositories.ProtectionRequest{Policy: "lax"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "unknown protection policy lax", err.Message())
+}
+
+func TestCreateRepoProtection(t *testing.T) {
+	service, client := newProtectionService("strict")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/branches/main/protection",
+		HttpMethod: http.MethodPut,
+		Response:   &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{}`))},
+	})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "strict", res.Protected)
+	assert.Nil(t, res.Warnings)
+
+	res, err = service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org", Protection: "lax"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "unknown protection policy lax", err.Message())
+}
+
+type protectorStub struct {
+	stubProvider
+	protected []string
+}
+
+func (p *protectorStub) CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	repo, _ := p.stubProvider.CreateRepo(ctx, request)
+	repo.DefaultBranch = "main"
+	return repo, nil
+}
+
+func (p *protectorStub) GetBranchProtection(ctx context.Context, owner string, name string, branch string, policy repositories.ProtectionPolicy) (*repositories.BranchProtection, errors.ApiError) {
+	return &repositories.BranchProtection{}, nil
+}
+
+func (p *protectorStub) SetBranchProtection(ctx context.Context, owner string, name string, branch string, policy repositories.ProtectionPolicy) errors.ApiError {
+	p.protected = append(p.protected, owner+"/"+name+"@"+branch)
+	return nil
+}
+
+func TestCreateRepoProtectionInitializesRepo(t *testing.T) {
+	registry := providers.NewRegistry(providers.Github, nil)
+	provider := &protectorStub{}
+	registry.Register(providers.Github, provider)
+	store := protection.NewStore(map[string]repositories.ProtectionPolicy{"strict": {}})
+
+	service := NewRepositoryService(ReposDependencies{Providers: registry, Protection: store})
+	_, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org"})
+	assert.Nil(t, err)
+	assert.False(t, provider.created[0].AutoInit)
+	assert.Nil(t, provider.protected)
+
+	service = NewRepositoryService(ReposDependencies{Providers: registry, Protection: store, DefaultProtection: "strict"})
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "payments", Org: "my-org"})
+	assert.Nil(t, err)
+	assert.True(t, provider.created[1].AutoInit)
+	assert.EqualValues(t, []string{"my-org/payments@main"}, provider.protected)
+	assert.EqualValues(t, "strict", res.Protected)
+}
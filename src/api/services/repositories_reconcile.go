@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/tracing"
+	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// reconcileStep is one planned action along with what applying it needs.
+type reconcileStep struct {
+	action       repositories.ReconcileAction
+	providerName string
+	provider     providers.RepoProvider
+	declared     repositories.ManifestRepository
+	live         *repositories.Repository
+
+	update repositories.UpdateRepoRequest
+	topics bool
+	teams  []repositories.TeamAccess
+	policy *repositories.ProtectionPolicy
+	// created lists what this service created for the owner, see
+	// createdRepos, it is only set on archive steps.
+	created map[string]bool
+}
+
+type reconcileResult struct {
+	index  int
+	action repositories.ReconcileAction
+}
+
+// Reconcile diffs the manifest against the live repositories of its owners
+// and, unless planOnly is set, applies the resulting plan. Teams are only
+// granted, access the manifest does not mention is left untouched. Archive
+// steps go through the delete policy, including its confirmation.
+func (s *reposService) Reconcile(ctx context.Context, manifest repositories.Manifest, planOnly bool) (*repositories.ReconcileResponse, errors.ApiError) {
+	batchId := newBatchId()
+	ctx = audit.WithBatchId(ctx, batchId)
+
+	ctx, span := tracing.Start(ctx, "reposService.Reconcile")
+	defer span.End()
+	span.SetAttribute("batch.id", batchId)
+	span.SetAttribute("reconcile.plan_only", strconv.FormatBool(planOnly))
+
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	for _, repo := range manifest.Repositories {
+		if err := s.deletePolicy.checkTopics(repo.Topics); err != nil {
+			return nil, errors.NewApiError(err.Status(), fmt.Sprintf("%s/%s: %s", repo.Owner, repo.Name, err.Message()))
+		}
+	}
+
+	steps, err := s.plan(ctx, manifest)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	span.SetAttribute("batch.size", strconv.Itoa(len(steps)))
+	if !planOnly {
+		for _, step := range steps {
+			if step.action.Action != repositories.ReconcileArchive {
+				continue
+			}
+			if err := s.deletePolicy.checkConfirmation(manifest.Confirm, manifest.ConfirmationToken()); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	result := &repositories.ReconcileResponse{
+		StatusCode: http.StatusOK,
+		BatchId:    batchId,
+		PlanOnly:   planOnly,
+		Actions:    make([]repositories.ReconcileAction, 0, len(steps)),
+	}
+	if planOnly || len(steps) == 0 {
+		for _, step := range steps {
+			result.Actions = append(result.Actions, step.action)
+		}
+		return result, nil
+	}
+
+	input := make(chan reconcileResult)
+	output := make(chan []repositories.ReconcileAction)
+	defer close(output)
+
+	var wg sync.WaitGroup
+	go s.handleReconcileResults(&wg, len(steps), input, output)
+
+	for i := range steps {
+		wg.Add(1)
+		go s.applyStepConcurrent(ctx, i, steps[i], input)
+	}
+
+	wg.Wait()
+	close(input)
+	result.Actions = <-output
+
+	successes := 0
+	var firstError *repositories.ReconcileAction
+	for i := range result.Actions {
+		if result.Actions[i].Error == "" {
+			successes++
+		} else if firstError == nil {
+			firstError = &result.Actions[i]
+		}
+	}
+
+	switch true {
+	case successes == 0:
+		result.StatusCode = firstError.Status
+	case successes == len(result.Actions):
+		result.StatusCode = http.StatusOK
+	default:
+		result.StatusCode = http.StatusPartialContent
+	}
+
+	logger.FromContext(ctx).Info("manifest reconciled",
+		logger.Any("batch_id", batchId),
+		logger.Any("actions", len(result.Actions)),
+		logger.Any("failed", len(result.Actions)-successes),
+	)
+	return result, nil
+}
+
+// plan lists the live repositories of every owner of the manifest once and
+// compares them with the declared ones, in manifest order. Archive actions
+// come last.
+func (s *reposService) plan(ctx context.Context, manifest repositories.Manifest) ([]reconcileStep, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.plan")
+	defer span.End()
+
+	type ownerState struct {
+		providerName string
+		provider     providers.RepoProvider
+		live         []repositories.Repository
+	}
+	owners := make(map[string]*ownerState)
+	for _, owner := range manifest.Owners() {
+		providerName, provider, err := s.resolveProvider(ctx, manifest.Provider, owner)
+		if err != nil {
+			return nil, err
+		}
+		listOwner, err := s.listOwner(ctx, providerName, provider, owner)
+		if err != nil {
+			return nil, err
+		}
+		live, err := listByPrefix(ctx, provider, listOwner, "")
+		if err != nil {
+			return nil, err
+		}
+		owners[strings.ToLower(owner)] = &ownerState{providerName: providerName, provider: provider, live: live}
+	}
+
+	steps := make([]reconcileStep, 0)
+	declared := make(map[string]bool, len(manifest.Repositories))
+	for _, repo := range manifest.Repositories {
+		declared[strings.ToLower(repo.Owner+"/"+repo.Name)] = true
+		state := owners[strings.ToLower(repo.Owner)]
+
+		var live *repositories.Repository
+		for i := range state.live {
+			if strings.EqualFold(state.live[i].Name, repo.Name) {
+				live = &state.live[i]
+				break
+			}
+		}
+
+		step := reconcileStep{providerName: state.providerName, provider: state.provider, declared: repo, live: live}
+		if live == nil {
+			step.action = repositories.ReconcileAction{Action: repositories.ReconcileCreate, Owner: repo.Owner, Name: repo.Name}
+			steps = append(steps, step)
+			continue
+		}
+
+		step.action = repositories.ReconcileAction{Action: repositories.ReconcileUpdate, Owner: live.Owner, Name: live.Name}
+		if err := s.diffRepo(ctx, &step); err != nil {
+			return nil, errors.NewApiError(err.Status(), fmt.Sprintf("%s/%s: %s", live.Owner, live.Name, err.Message()))
+		}
+		if len(step.action.Changes) > 0 {
+			steps = append(steps, step)
+		}
+	}
+
+	if manifest.ArchiveMissing {
+		for _, owner := range manifest.Owners() {
+			state := owners[strings.ToLower(owner)]
+			var created map[string]bool
+			for i := range state.live {
+				live := &state.live[i]
+				if live.Archived || declared[strings.ToLower(owner+"/"+live.Name)] {
+					continue
+				}
+				if created == nil {
+					created = s.createdRepos(ctx, owner)
+				}
+				steps = append(steps, reconcileStep{
+					action:       repositories.ReconcileAction{Action: repositories.ReconcileArchive, Owner: owner, Name: live.Name},
+					providerName: state.providerName,
+					provider:     state.provider,
+					live:         live,
+					created:      created,
+				})
+			}
+		}
+	}
+	return steps, nil
+}
+
+// diffRepo fills the changes of an existing repository, only the fields the
+// manifest declares are compared.
+func (s *reposService) diffRepo(ctx context.Context, step *reconcileStep) errors.ApiError {
+	declared, live := step.declared, step.live
+	changes := make([]string, 0)
+
+	if live.Archived {
+		archived := false
+		step.update.Archived = &archived
+		changes = append(changes, "archived: true -> false")
+	}
+	if declared.Description != nil && *declared.Description != live.Description {
+		step.update.Description = declared.Description
+		changes = append(changes, fmt.Sprintf("description: %q -> %q", live.Description, *declared.Description))
+	}
+	if declared.Private != nil && *declared.Private != live.Private {
+		step.update.Private = declared.Private
+		changes = append(changes, fmt.Sprintf("private: %t -> %t", live.Private, *declared.Private))
+	}
+
+	if declared.Topics != nil {
+		if err := checkTopicsSupported(step.providerName, step.provider); err != nil {
+			return err
+		}
+		step.declared.Topics = s.deletePolicy.keepMarker(declared.Topics, live)
+		current := append([]string(nil), live.Topics...)
+		sort.Strings(current)
+		wanted := append([]string(nil), step.declared.Topics...)
+		sort.Strings(wanted)
+		if strings.Join(current, ",") != strings.Join(wanted, ",") {
+			step.topics = true
+			changes = append(changes, fmt.Sprintf("topics: [%s] -> [%s]", strings.Join(current, ", "), strings.Join(wanted, ", ")))
+		}
+	}
+
+	if len(declared.Teams) > 0 {
+		manager, ok := step.provider.(providers.AccessManager)
+		if !ok {
+			return errors.NewBadRequestApiError("provider " + step.providerName + " does not support access management")
+		}
+		teams, err := manager.ListTeams(ctx, live.Owner, live.Name)
+		if err != nil {
+			return err
+		}
+		current := make(map[string]string, len(teams))
+		for _, team := range teams {
+			current[strings.ToLower(team.Slug)] = team.Permission
+		}
+		for _, team := range declared.Teams {
+			permission, ok := current[team.Slug]
+			if ok && permission == team.Permission {
+				continue
+			}
+			if !ok {
+				permission = "none"
+			}
+			step.teams = append(step.teams, team)
+			changes = append(changes, fmt.Sprintf("team %s: %s -> %s", team.Slug, permission, team.Permission))
+		}
+	}
+
+	if declared.Protection != "" {
+		policy, err := s.resolvePolicy(declared.Protection)
+		if err != nil {
+			return err
+		}
+		protector, ok := step.provider.(providers.BranchProtector)
+		if !ok {
+			return errors.NewBadRequestApiError("provider " + step.providerName + " does not support branch protection")
+		}
+		current, err := protector.GetBranchProtection(ctx, live.Owner, live.Name, live.DefaultBranch, policy)
+		if err != nil {
+			return err
+		}
+		drift := policy.Diff(*current)
+		for _, field := range drift {
+			changes = append(changes, fmt.Sprintf("protection %s: %v -> %v", field.Field, field.Actual, field.Expected))
+		}
+		if len(drift) > 0 {
+			step.policy = &policy
+		}
+	}
+
+	step.action.Changes = changes
+	return nil
+}
+
+func (s *reposService) handleReconcileResults(wg *sync.WaitGroup, size int, input <-chan reconcileResult, output chan<- []repositories.ReconcileAction) {
+	results := make([]repositories.ReconcileAction, size)
+
+	for result := range input {
+		results[result.index] = result.action
+		wg.Done()
+	}
+
+	output <- results
+}
+
+func (s *reposService) applyStepConcurrent(ctx context.Context, index int, step reconcileStep, output chan<- reconcileResult) {
+	action := step.action
+	var err errors.ApiError
+	switch action.Action {
+	case repositories.ReconcileCreate:
+		action.Warnings, err = s.applyCreate(ctx, step)
+	case repositories.ReconcileUpdate:
+		err = s.applyUpdate(ctx, step)
+	case repositories.ReconcileArchive:
+		_, err = s.deleteRepo(ctx, step.providerName, step.provider, step.action.Owner, step.action.Name, true, step.live, step.created)
+	}
+
+	if err != nil {
+		action.Status = err.Status()
+		action.Error = err.Message()
+	} else if action.Action == repositories.ReconcileCreate {
+		action.Status = http.StatusCreated
+	} else {
+		action.Status = http.StatusOK
+	}
+
+	output <- reconcileResult{index: index, action: action}
+}
+
+// applyCreate goes through CreateRepo so quotas, audit and the post creation
+// steps apply. Repositories are created private, a public one is switched
+// afterwards.
+func (s *reposService) applyCreate(ctx context.Context, step reconcileStep) ([]string, errors.ApiError) {
+	declared := step.declared
+	request := repositories.CreateRepoRequest{
+		Name:       declared.Name,
+		Org:        declared.Owner,
+		Provider:   step.providerName,
+		Templates:  declared.Templates,
+		Protection: declared.Protection,
+		Topics:     declared.Topics,
+	}
+	if declared.Description != nil {
+		request.Description = *declared.Description
+	}
+	if len(declared.Teams) > 0 {
+		request.Access = &repositories.AccessRequest{Teams: declared.Teams}
+	}
+
+	res, err := s.CreateRepo(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := res.Warnings
+	if declared.Private != nil && !*declared.Private {
+		if _, err := step.provider.UpdateRepo(ctx, res.Owner, res.Name, repositories.UpdateRepoRequest{Private: declared.Private}); err != nil {
+			warnings = append(warnings, "visibility was not changed: "+err.Message())
+		}
+	}
+	return warnings, nil
+}
+
+// applyUpdate applies the changes of an existing repository in a fixed order
+// and stops at the first failure, every step is idempotent so reconciling
+// again resumes where it stopped.
+func (s *reposService) applyUpdate(ctx context.Context, step reconcileStep) errors.ApiError {
+	owner, name := step.live.Owner, step.live.Name
+
+	update := step.update
+	if update.Archived != nil || update.Description != nil || update.Private != nil {
+		if _, err := step.provider.UpdateRepo(ctx, owner, name, update); err != nil {
+			return err
+		}
+	}
+	if step.topics {
+		if _, err := step.provider.(providers.TopicsSetter).SetTopics(ctx, owner, name, step.declared.Topics); err != nil {
+			return errors.NewApiError(err.Status(), "topics: "+err.Message())
+		}
+	}
+	if len(step.teams) > 0 {
+		manager := step.provider.(providers.AccessManager)
+		if _, err := grantAccess(ctx, manager, owner, name, repositories.AccessRequest{Teams: step.teams}); err != nil {
+			return err
+		}
+	}
+	if step.policy != nil {
+		protector := step.provider.(providers.BranchProtector)
+		if err := protector.SetBranchProtection(ctx, owner, name, step.live.DefaultBranch, *step.policy); err != nil {
+			return errors.NewApiError(err.Status(), "protection: "+err.Message())
+		}
+	}
+	return nil
+}
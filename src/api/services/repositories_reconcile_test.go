@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newReconcileService() (ReposServiceInterface, *restclient.Client) {
+	return newFixture(
+		withAccount("token-user"),
+		withMock(http.MethodGet, "https://api.github.com/orgs/my-org/repos?page=1&per_page=100", http.StatusOK, `[
+			{"id": 1, "name": "payments", "description": "old", "private": true, "topics": ["go"], "owner": {"login": "my-org"}},
+			{"id": 2, "name": "tools", "description": "Tools", "private": true, "owner": {"login": "my-org"}},
+			{"id": 3, "name": "legacy", "private": true, "owner": {"login": "my-org"}}
+		]`),
+		withDependencies(func(deps *ReposDependencies) { deps.DeletePolicy = DeletePolicy{AllowedOrgs: []string{"my-org"}} }),
+	)
+}
+
+func reconcileManifest() repositories.Manifest {
+	description := "Payments API"
+	return repositories.Manifest{
+		Owner:          "my-org",
+		ArchiveMissing: true,
+		Repositories: []repositories.ManifestRepository{
+			{Name: "payments", Description: &description, Topics: []string{"payments", "go"}},
+			{Name: "tools"},
+			{Name: "billing"},
+		},
+	}
+}
+
+func TestReconcilePlanOnly(t *testing.T) {
+	service, _ := newReconcileService()
+
+	res, err := service.Reconcile(context.Background(), reconcileManifest(), true)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, res.StatusCode)
+	assert.True(t, res.PlanOnly)
+	assert.EqualValues(t, []repositories.ReconcileAction{
+		{Action: repositories.ReconcileUpdate, Owner: "my-org", Name: "payments", Changes: []string{
+			`description: "old" -> "Payments API"`,
+			"topics: [go] -> [go, payments]",
+		}},
+		{Action: repositories.ReconcileCreate, Owner: "my-org", Name: "billing"},
+		{Action: repositories.ReconcileArchive, Owner: "my-org", Name: "legacy"},
+	}, res.Actions)
+}
+
+func TestReconcileApply(t *testing.T) {
+	service, client := newReconcileService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 1, "name": "payments", "owner": {"login": "my-org"}}`)),
+		},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/topics",
+		HttpMethod: http.MethodPut,
+		Response:   &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"names": ["go", "payments"]}`))},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Repository creation failed."}`)),
+		},
+	})
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/legacy",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 3, "name": "legacy", "archived": true, "owner": {"login": "my-org"}}`)),
+		},
+	})
+
+	res, err := service.Reconcile(context.Background(), reconcileManifest(), false)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, res.StatusCode)
+	assert.NotEmpty(t, res.BatchId)
+	assert.EqualValues(t, 3, len(res.Actions))
+	assert.EqualValues(t, http.StatusOK, res.Actions[0].Status)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, res.Actions[1].Status)
+	assert.EqualValues(t, "Repository creation failed.", res.Actions[1].Error)
+	assert.EqualValues(t, http.StatusOK, res.Actions[2].Status)
+}
+
+func newArchiveService() ReposServiceInterface {
+	service, _ := newFixture(
+		withAccount("token-user"),
+		withMock(http.MethodGet, "https://api.github.com/orgs/my-org/repos?page=1&per_page=100", http.StatusOK,
+			`[{"id": 1, "name": "payments", "owner": {"login": "my-org"}}, {"id": 3, "name": "legacy", "owner": {"login": "my-org"}}]`),
+		withMock(http.MethodPatch, "https://api.github.com/repos/my-org/legacy", http.StatusOK,
+			`{"id": 3, "name": "legacy", "archived": true, "owner": {"login": "my-org"}}`),
+		withDependencies(func(deps *ReposDependencies) {
+			deps.DeletePolicy = DeletePolicy{AllowedOrgs: []string{"my-org"}, RequireConfirmation: true}
+		}),
+	)
+	return service
+}
+
+func TestReconcileArchiveRequiresConfirmation(t *testing.T) {
+	manifest := repositories.Manifest{
+		Owner:          "my-org",
+		ArchiveMissing: true,
+		Repositories:   []repositories.ManifestRepository{{Name: "payments"}},
+	}
+
+	res, err := newArchiveService().Reconcile(context.Background(), manifest, true)
+	assert.Nil(t, err)
+	assert.EqualValues(t, repositories.ReconcileArchive, res.Actions[0].Action)
+
+	res, err = newArchiveService().Reconcile(context.Background(), manifest, false)
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusPreconditionRequired, err.Status())
+	assert.EqualValues(t, "confirmation required, set the X-Confirm-Delete header to my-org/*", err.Message())
+
+	manifest.Confirm = "my-org/*"
+	res, err = newArchiveService().Reconcile(context.Background(), manifest, false)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, res.Actions[0].Status)
+}
+
+func TestReconcileForbiddenOwner(t *testing.T) {
+	service, _ := newReconcileService()
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "ci", Orgs: []string{"platform"}})
+
+	res, err := service.Reconcile(ctx, reconcileManifest(), true)
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
+
+func TestReconcilePlanTeams(t *testing.T) {
+	service, client := newReconcileService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/tools/teams?per_page=100",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`[{"id": 1, "slug": "core", "permission": "pull"}, {"id": 2, "slug": "ops", "permission": "admin"}]`)),
+		},
+	})
+	manifest := reconcileManifest()
+	manifest.ArchiveMissing = false
+	manifest.Repositories[1].Teams = []repositories.TeamAccess{{Slug: "core", Permission: "push"}, {Slug: "ops", Permission: "admin"}, {Slug: "qa"}}
+
+	res, err := service.Reconcile(context.Background(), manifest, true)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, len(res.Actions))
+	assert.EqualValues(t, []string{"team core: pull -> push", "team qa: none -> pull"}, res.Actions[1].Changes)
+}
+
+func TestReconcileInvalidManifest(t *testing.T) {
+	service, _ := newReconcileService()
+
+	res, err := service.Reconcile(context.Background(), repositories.Manifest{Repositories: []repositories.ManifestRepository{{Name: "payments"}}}, true)
+	assert.Nil(t, res)
+	assert.EqualValues(t, "repository payments has no owner", err.Message())
+}
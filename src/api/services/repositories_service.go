@@ -1,49 +1,743 @@
 package services
 
 import (
-	"golang-microservices/src/api/config"
-	"golang-microservices/src/api/domain/github"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/auth"
 	"golang-microservices/src/api/domain/repositories"
-	"golang-microservices/src/api/providers/github_provider"
+	"golang-microservices/src/api/labels"
+	"golang-microservices/src/api/metrics"
+	"golang-microservices/src/api/protection"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/ratelimit"
+	"golang-microservices/src/api/secrets"
+	"golang-microservices/src/api/templates"
+	"golang-microservices/src/api/tracing"
 	"golang-microservices/src/api/utils/errors"
+	"golang-microservices/src/api/utils/logger"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
-type reposService struct{}
+const initialFilesMessage = "Add initial files"
+
+type reposService struct {
+	providers    *providers.Registry
+	quotaStore   ratelimit.Store
+	dailyQuota   int
+	auditLog     audit.Log
+	deletePolicy DeletePolicy
+	templates    *templates.Store
+	secrets      *secrets.Store
+	protection   *protection.Store
+	labels       *labels.Store
+	// defaultProtection is the policy applied to new repositories not
+	// naming one, empty disables it.
+	defaultProtection string
+
+	// accounts caches the login owning the token of each provider.
+	accountsMutex sync.Mutex
+	accounts      map[string]string
+}
 
 type ReposServiceInterface interface {
-	CreateRepo(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
-	CreateRepos(input []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError)
+	CreateRepo(ctx context.Context, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
+	CreateRepos(ctx context.Context, input []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError)
+	GetRepo(ctx context.Context, provider string, owner string, name string) (*repositories.Repository, errors.ApiError)
+	ListRepos(ctx context.Context, provider string, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError)
+	SyncRepo(ctx context.Context, provider string, owner string, name string, input repositories.SyncRequest) (*repositories.SyncResponse, errors.ApiError)
+	UpdateRepo(ctx context.Context, provider string, owner string, name string, input repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError)
+	TransferRepo(ctx context.Context, provider string, owner string, name string, input repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError)
+	ApplyProtection(ctx context.Context, provider string, owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError)
+	GetProtectionDrift(ctx context.Context, provider string, owner string, name string, input repositories.ProtectionRequest) (*repositories.ProtectionReport, errors.ApiError)
+	GrantAccess(ctx context.Context, provider string, owner string, name string, input repositories.AccessRequest) (*repositories.AccessResponse, errors.ApiError)
+	DeleteRepo(ctx context.Context, input repositories.DeleteRepoRequest) (*repositories.DeleteRepoResponse, errors.ApiError)
+	DeleteRepos(ctx context.Context, input repositories.DeleteReposRequest) (*repositories.DeleteReposResponse, errors.ApiError)
+	Reconcile(ctx context.Context, manifest repositories.Manifest, planOnly bool) (*repositories.ReconcileResponse, errors.ApiError)
 }
 
-var RepositoryService ReposServiceInterface
+// ReposDependencies groups what the repository service is built from, only
+// Providers is required.
+type ReposDependencies struct {
+	Providers    *providers.Registry
+	QuotaStore   ratelimit.Store
+	DailyQuota   int
+	AuditLog     audit.Log
+	DeletePolicy DeletePolicy
+	Templates    *templates.Store
+	Secrets      *secrets.Store
+	Protection   *protection.Store
+	Labels       *labels.Store
+	// DefaultProtection names the policy of Protection applied to new
+	// repositories.
+	DefaultProtection string
+}
 
-func init() {
-	RepositoryService = &reposService{}
+func NewRepositoryService(deps ReposDependencies) ReposServiceInterface {
+	if deps.Templates == nil {
+		deps.Templates = templates.NewStore()
+	}
+	if deps.Secrets == nil {
+		deps.Secrets = secrets.NewStore(nil)
+	}
+	if deps.Protection == nil {
+		deps.Protection = protection.NewStore(nil)
+	}
+	if deps.Labels == nil {
+		deps.Labels = labels.NewStore(nil)
+	}
+	return &reposService{
+		providers:    deps.Providers,
+		quotaStore:   deps.QuotaStore,
+		dailyQuota:   deps.DailyQuota,
+		auditLog:     deps.AuditLog,
+		deletePolicy: deps.DeletePolicy,
+		templates:    deps.Templates,
+		secrets:      deps.Secrets,
+		protection:   deps.Protection,
+		labels:       deps.Labels,
+
+		defaultProtection: deps.DefaultProtection,
+	}
 }
 
-func (s *reposService) CreateRepo(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+func (s *reposService) CreateRepo(ctx context.Context, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.CreateRepo")
+	defer span.End()
+	span.SetAttribute("repository.name", input.Name)
+	span.SetAttribute("repository.org", input.Org)
+	span.SetAttribute("repository.provider", input.Provider)
+
+	res, err := s.createRepo(ctx, &input)
+	s.recordAudit(ctx, input, res, err)
+
+	status := http.StatusCreated
+	if err != nil {
+		status = err.Status()
+		span.SetError(err.Message())
+	}
+	metrics.RepositoryCreations.Inc(strconv.Itoa(status))
+
+	return res, err
+}
+
+func (s *reposService) authorize(ctx context.Context, input *repositories.CreateRepoRequest) (providers.RepoProvider, errors.ApiError) {
+	_, span := tracing.Start(ctx, "reposService.authorize")
+	defer span.End()
+
 	if err := input.Validate(); err != nil {
 		return nil, err
 	}
 
-	request := github.CreateRepoRequest{
-		Name:        input.Name,
-		Description: input.Description,
-		Private:     true,
+	name, provider, err := s.providers.Resolve(input.Provider, input.Org)
+	if err != nil {
+		return nil, err
+	}
+	input.Provider = name
+
+	caller := auth.CallerFromContext(ctx)
+	if caller != nil && !caller.CanUseOrg(input.Org) {
+		return nil, errors.NewForbiddenApiError("caller is not allowed to create repositories in this org")
+	}
+	callerId := ""
+	if caller != nil {
+		callerId = caller.Id
+	}
+
+	if len(input.Templates) > 0 {
+		if _, ok := provider.(providers.FileCommitter); !ok {
+			return nil, errors.NewBadRequestApiError("provider " + name + " does not support templates")
+		}
+		for _, ref := range input.Templates {
+			if !s.templates.Has(ref) {
+				return nil, errors.NewBadRequestApiError("unknown template " + ref)
+			}
+		}
+	}
+	if len(input.Webhooks) > 0 {
+		if _, ok := provider.(providers.HookCreator); !ok {
+			return nil, errors.NewBadRequestApiError("provider " + name + " does not support webhooks")
+		}
+		for _, hook := range input.Webhooks {
+			if hook.Secret == "" {
+				continue
+			}
+			if !s.secrets.Has(hook.Secret) {
+				return nil, errors.NewBadRequestApiError("unknown secret " + hook.Secret)
+			}
+			if !s.secrets.Allows(hook.Secret, callerId, input.Org) {
+				return nil, errors.NewForbiddenApiError("caller is not allowed to use secret " + hook.Secret)
+			}
+		}
+	}
+
+	if len(input.Secrets) > 0 || len(input.Variables) > 0 {
+		if _, ok := provider.(providers.ActionsConfigurer); !ok {
+			return nil, errors.NewBadRequestApiError("provider " + name + " does not support actions secrets and variables")
+		}
+		for _, secret := range input.Secrets {
+			if !s.secrets.Has(secret.Secret) {
+				return nil, errors.NewBadRequestApiError("unknown secret " + secret.Secret)
+			}
+			if !s.secrets.Allows(secret.Secret, callerId, input.Org) {
+				return nil, errors.NewForbiddenApiError("caller is not allowed to use secret " + secret.Secret)
+			}
+		}
 	}
 
-	response, err := github_provider.CreateRepo(config.GetGithubAccessToken(), request)
+	if input.Protection != "" {
+		if _, ok := provider.(providers.BranchProtector); !ok {
+			return nil, errors.NewBadRequestApiError("provider " + name + " does not support branch protection")
+		}
+		if _, ok := s.protection.Get(input.Protection); !ok {
+			return nil, errors.NewBadRequestApiError("unknown protection policy " + input.Protection)
+		}
+	}
+	if input.Labels != nil {
+		if _, err := s.labelSet(name, provider, input.Labels); err != nil {
+			return nil, err
+		}
+	}
+	if len(input.Topics) > 0 {
+		if err := checkTopicsSupported(name, provider); err != nil {
+			return nil, err
+		}
+		if err := s.deletePolicy.checkTopics(input.Topics); err != nil {
+			return nil, err
+		}
+		if len(s.deletePolicy.markTopics(input.Topics)) > repositories.MaxTopics {
+			return nil, errors.NewBadRequestApiError(
+				fmt.Sprintf("too many topics, at most %d are allowed besides the delete marker", repositories.MaxTopics-1),
+			)
+		}
+	}
+	if input.Access != nil && !input.Access.IsEmpty() {
+		if _, ok := provider.(providers.AccessManager); !ok {
+			return nil, errors.NewBadRequestApiError("provider " + name + " does not support access management")
+		}
+	}
+
+	if err := s.consumeQuota(ctx, caller); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (s *reposService) createRepo(ctx context.Context, input *repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	provider, err := s.authorize(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Err() != nil {
+		s.refundQuota(ctx, auth.CallerFromContext(ctx))
+		logger.FromContext(ctx).Warn("repository creation cancelled before calling the provider",
+			logger.Any("name", input.Name),
+			logger.Any("org", input.Org),
+		)
+		return nil, errors.NewServiceUnavailableApiError("repository creation cancelled before completion")
+	}
+
+	if len(input.Templates) == 0 && s.protectionPolicy(provider, input) != "" {
+		input.AutoInit = true
+	}
+	response, err := provider.CreateRepo(ctx, *input)
 	if err != nil {
-		return nil, errors.NewApiError(err.StatusCode, err.Message)
+		if ctx.Err() != nil {
+			logger.FromContext(ctx).Warn("repository creation cancelled while waiting for the provider",
+				logger.Any("name", input.Name),
+				logger.Any("org", input.Org),
+				logger.Any("provider", input.Provider),
+			)
+			return nil, errors.NewServiceUnavailableApiError("repository creation cancelled, repository state unknown")
+		}
+		s.refundQuota(ctx, auth.CallerFromContext(ctx))
+		return nil, err
 	}
 
-	res := repositories.CreateRepoResponse{Id: response.Id, Name: response.Name, Owner: response.Owner.Login}
+	logger.FromContext(ctx).Info("repository created",
+		logger.Any("full_name", response.FullName),
+		logger.Any("repo_id", response.Id),
+		logger.Any("provider", input.Provider),
+	)
+
+	res := repositories.CreateRepoResponse{
+		Id:       response.Id,
+		Name:     response.Name,
+		FullName: response.FullName,
+		Owner:    response.Owner,
+		Provider: input.Provider,
+	}
+	if len(input.Templates) > 0 {
+		s.seedFiles(ctx, provider, input, response, &res)
+	}
+	if len(input.Webhooks) > 0 {
+		s.createHooks(ctx, provider, input, response, &res)
+	}
+	if len(input.Secrets) > 0 || len(input.Variables) > 0 {
+		s.configureActions(ctx, provider, input, response, &res)
+	}
+	s.protectNewRepo(ctx, provider, input, response, &res)
+	if input.Labels != nil || len(input.Topics) > 0 || s.deletePolicy.TopicMarker != "" {
+		s.labelNewRepo(ctx, provider, input, response, &res)
+	}
+	if input.Access != nil && !input.Access.IsEmpty() {
+		access, err := grantAccess(ctx, provider.(providers.AccessManager), response.Owner, response.Name, *input.Access)
+		if err != nil {
+			logger.FromContext(ctx).Warn("error when granting access",
+				logger.Any("full_name", response.FullName),
+				logger.Any("message", err.Message()),
+			)
+			res.Warnings = append(res.Warnings, "access was not fully granted: "+err.Message())
+		}
+		res.Access = access
+	}
 	return &res, nil
 }
 
-func (s *reposService) CreateRepos(requests []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError) {
+// configureActions sets the Actions secrets and variables of the new
+// repository, failures are reported as warnings. Only names end up in the
+// response, logs and audit log.
+func (s *reposService) configureActions(ctx context.Context, provider providers.RepoProvider, input *repositories.CreateRepoRequest, repo *repositories.Repository, res *repositories.CreateRepoResponse) {
+	ctx, span := tracing.Start(ctx, "reposService.configureActions")
+	defer span.End()
+
+	configurer := provider.(providers.ActionsConfigurer)
+	if len(input.Secrets) > 0 {
+		values := make(map[string]string, len(input.Secrets))
+		for _, secret := range input.Secrets {
+			values[secret.Name], _ = s.secrets.Get(secret.Secret)
+		}
+		if err := configurer.SetActionsSecrets(ctx, repo.Owner, repo.Name, values); err != nil {
+			logger.FromContext(ctx).Warn("error when setting actions secrets",
+				logger.Any("full_name", repo.FullName),
+				logger.Any("message", err.Message()),
+			)
+			span.SetError(err.Message())
+			res.Warnings = append(res.Warnings, "actions secrets were not set: "+err.Message())
+		} else {
+			for _, secret := range input.Secrets {
+				res.Secrets = append(res.Secrets, secret.Name)
+			}
+		}
+	}
+
+	if len(input.Variables) > 0 {
+		if err := configurer.SetActionsVariables(ctx, repo.Owner, repo.Name, input.Variables); err != nil {
+			logger.FromContext(ctx).Warn("error when setting actions variables",
+				logger.Any("full_name", repo.FullName),
+				logger.Any("message", err.Message()),
+			)
+			span.SetError(err.Message())
+			res.Warnings = append(res.Warnings, "actions variables were not set: "+err.Message())
+			return
+		}
+		for _, variable := range input.Variables {
+			res.Variables = append(res.Variables, variable.Name)
+		}
+	}
+}
+
+// createHooks adds the requested webhooks to the new repository, failures
+// are reported as warnings like for the initial files. Secret values are only
+// handed to the provider, messages name the hook by its redacted url.
+func (s *reposService) createHooks(ctx context.Context, provider providers.RepoProvider, input *repositories.CreateRepoRequest, repo *repositories.Repository, res *repositories.CreateRepoResponse) {
+	ctx, span := tracing.Start(ctx, "reposService.createHooks")
+	defer span.End()
+
+	creator := provider.(providers.HookCreator)
+	for _, hook := range input.Webhooks {
+		secret, _ := s.secrets.Get(hook.Secret)
+
+		id, err := creator.CreateHook(ctx, repo.Owner, repo.Name, hook, secret)
+		if err != nil {
+			hookUrl := hook.Redacted().Url
+			logger.FromContext(ctx).Warn("error when creating webhook",
+				logger.Any("full_name", repo.FullName),
+				logger.Any("url", hookUrl),
+				logger.Any("message", err.Message()),
+			)
+			span.SetError(err.Message())
+			res.Warnings = append(res.Warnings, fmt.Sprintf("webhook %s was not created: %s", hookUrl, err.Message()))
+			continue
+		}
+		res.HookIds = append(res.HookIds, id)
+	}
+}
+
+// seedFiles commits the rendered templates to the new repository. The
+// repository exists at this point, failures are reported as warnings instead
+// of failing the whole creation.
+func (s *reposService) seedFiles(ctx context.Context, provider providers.RepoProvider, input *repositories.CreateRepoRequest, repo *repositories.Repository, res *repositories.CreateRepoResponse) {
+	ctx, span := tracing.Start(ctx, "reposService.seedFiles")
+	defer span.End()
+
+	files, err := s.templates.Render(input.Templates, templates.Vars{
+		Name:        repo.Name,
+		Owner:       repo.Owner,
+		Description: input.Description,
+		Team:        input.Team,
+		Year:        time.Now().Year(),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("error when rendering templates", logger.Err(err))
+		span.SetError(err.Error())
+		res.Warnings = append(res.Warnings, "initial files were not committed: "+err.Error())
+		return
+	}
+
+	committer := provider.(providers.FileCommitter)
+	if apiErr := committer.CommitFiles(ctx, repo.Owner, repo.Name, repo.DefaultBranch, initialFilesMessage, files); apiErr != nil {
+		logger.FromContext(ctx).Warn("error when committing initial files",
+			logger.Any("full_name", repo.FullName),
+			logger.Any("message", apiErr.Message()),
+		)
+		span.SetError(apiErr.Message())
+		res.Warnings = append(res.Warnings, "initial files were not committed: "+apiErr.Message())
+		return
+	}
+
+	for _, file := range files {
+		res.Files = append(res.Files, file.Path)
+	}
+}
+
+func (s *reposService) recordAudit(ctx context.Context, input repositories.CreateRepoRequest, res *repositories.CreateRepoResponse, err errors.ApiError) {
+	if len(input.Webhooks) > 0 {
+		webhooks := make([]repositories.Webhook, 0, len(input.Webhooks))
+		for _, hook := range input.Webhooks {
+			webhooks = append(webhooks, hook.Redacted())
+		}
+		input.Webhooks = webhooks
+	}
+
+	entry := repositories.AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Action:    repositories.AuditActionCreate,
+		BatchId:   audit.BatchIdFromContext(ctx),
+		Request:   &input,
+		Owner:     input.Org,
+		Status:    http.StatusCreated,
+	}
+	if res != nil {
+		entry.RepoId = res.Id
+		entry.FullName = res.FullName
+		if entry.Owner == "" {
+			entry.Owner = res.Owner
+		}
+	}
+	if err != nil {
+		entry.Status = err.Status()
+		entry.Error = err.Message()
+	}
+
+	s.record(ctx, entry)
+}
+
+func (s *reposService) record(ctx context.Context, entry repositories.AuditEntry) {
+	if s.auditLog == nil {
+		return
+	}
+	if caller := auth.CallerFromContext(ctx); caller != nil {
+		entry.Caller = caller.Id
+	}
+
+	if err := s.auditLog.Record(entry); err != nil {
+		logger.FromContext(ctx).Error("error when recording audit entry", logger.Err(err))
+	}
+}
+
+func (s *reposService) consumeQuota(ctx context.Context, caller *auth.Caller) errors.ApiError {
+	if caller == nil || s.quotaStore == nil || s.dailyQuota <= 0 {
+		return nil
+	}
+
+	result, err := s.quotaStore.ConsumeQuota(caller.Id, 1, s.dailyQuota, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error("error when checking repository creation quota", logger.Err(err))
+		return errors.NewInternalServerError("error when checking repository creation quota")
+	}
+	ratelimit.ReportQuota(ctx, result)
+	if !result.Allowed {
+		return errors.NewTooManyRequestsApiError(
+			fmt.Sprintf("daily repository creation quota of %d exceeded", result.Limit),
+		)
+	}
+
+	return nil
+}
+
+func (s *reposService) refundQuota(ctx context.Context, caller *auth.Caller) {
+	if caller == nil || s.quotaStore == nil || s.dailyQuota <= 0 {
+		return
+	}
+
+	if err := s.quotaStore.RefundQuota(caller.Id, 1, time.Now()); err != nil {
+		logger.FromContext(ctx).Error("error when refunding repository creation quota", logger.Err(err))
+	}
+}
+
+// resolveProvider picks the provider for repositories of owner and makes sure
+// the caller may act on that owner.
+func (s *reposService) resolveProvider(ctx context.Context, name string, owner string) (string, providers.RepoProvider, errors.ApiError) {
+	name, provider, err := s.providers.Resolve(strings.ToLower(strings.TrimSpace(name)), owner)
+	if err != nil {
+		return "", nil, err
+	}
+
+	caller := auth.CallerFromContext(ctx)
+	if caller == nil {
+		return name, provider, nil
+	}
+	org, err := s.ownerOrg(ctx, caller, name, provider, owner)
+	if err != nil {
+		return "", nil, err
+	}
+	if !caller.CanUseOrg(org) {
+		return "", nil, errors.NewForbiddenApiError("caller is not allowed to access repositories of this owner")
+	}
+
+	return name, provider, nil
+}
+
+// ownerOrg is the org checked against the allowlist of the caller for
+// repositories of owner. The account owning the provider token stands for ""
+// the way it does on creation, it is only looked up when that changes the
+// outcome.
+func (s *reposService) ownerOrg(ctx context.Context, caller *auth.Caller, name string, provider providers.RepoProvider, owner string) (string, errors.ApiError) {
+	resolver, ok := provider.(providers.AccountResolver)
+	if !ok || caller.CanUseOrg(owner) == caller.CanUseOrg("") {
+		return owner, nil
+	}
+
+	login, err := s.accountLogin(ctx, name, resolver)
+	if err != nil {
+		return "", err
+	}
+	if strings.EqualFold(login, owner) {
+		return "", nil
+	}
+	return owner, nil
+}
+
+// listOwner is the owner passed to ListRepos, the account owning the provider
+// token is listed as "" since the org listing endpoints do not know it.
+func (s *reposService) listOwner(ctx context.Context, name string, provider providers.RepoProvider, owner string) (string, errors.ApiError) {
+	resolver, ok := provider.(providers.AccountResolver)
+	if !ok || owner == "" {
+		return owner, nil
+	}
+
+	login, err := s.accountLogin(ctx, name, resolver)
+	if err != nil {
+		return "", err
+	}
+	if strings.EqualFold(login, owner) {
+		return "", nil
+	}
+	return owner, nil
+}
+
+func (s *reposService) accountLogin(ctx context.Context, name string, resolver providers.AccountResolver) (string, errors.ApiError) {
+	s.accountsMutex.Lock()
+	login, ok := s.accounts[name]
+	s.accountsMutex.Unlock()
+	if ok {
+		return login, nil
+	}
+
+	login, err := resolver.AccountLogin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("error when resolving the account of the provider token",
+			logger.Any("provider", name),
+			logger.Any("message", err.Message()),
+		)
+		return "", errors.NewServiceUnavailableApiError("error when resolving the account of provider " + name)
+	}
+
+	s.accountsMutex.Lock()
+	if s.accounts == nil {
+		s.accounts = make(map[string]string)
+	}
+	s.accounts[name] = login
+	s.accountsMutex.Unlock()
+	return login, nil
+}
+
+func (s *reposService) GetRepo(ctx context.Context, providerName string, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.GetRepo")
+	defer span.End()
+	span.SetAttribute("repository.owner", owner)
+	span.SetAttribute("repository.name", name)
+
+	owner, name = strings.TrimSpace(owner), strings.TrimSpace(name)
+	if owner == "" || name == "" {
+		return nil, errors.NewBadRequestApiError("invalid repository owner or name")
+	}
+
+	_, provider, err := s.resolveProvider(ctx, providerName, owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+
+	repo, err := provider.GetRepo(ctx, owner, name)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (s *reposService) UpdateRepo(ctx context.Context, providerName string, owner string, name string, input repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.UpdateRepo")
+	defer span.End()
+	span.SetAttribute("repository.owner", owner)
+	span.SetAttribute("repository.name", name)
+
+	owner, name = strings.TrimSpace(owner), strings.TrimSpace(name)
+	if owner == "" || name == "" {
+		return nil, errors.NewBadRequestApiError("invalid repository owner or name")
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	_, provider, err := s.resolveProvider(ctx, providerName, owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+
+	repo, err := provider.UpdateRepo(ctx, owner, name, input)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	return repo, nil
+}
+
+// TransferRepo moves a repository to another owner of the same provider, the
+// caller has to be allowed on both owners.
+func (s *reposService) TransferRepo(ctx context.Context, providerName string, owner string, name string, input repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.TransferRepo")
+	defer span.End()
+	span.SetAttribute("repository.owner", owner)
+	span.SetAttribute("repository.name", name)
+	span.SetAttribute("repository.new_owner", input.NewOwner)
+
+	owner, name = strings.TrimSpace(owner), strings.TrimSpace(name)
+	if owner == "" || name == "" {
+		return nil, errors.NewBadRequestApiError("invalid repository owner or name")
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(owner, input.NewOwner) {
+		return nil, errors.NewBadRequestApiError("repository already belongs to " + owner)
+	}
+
+	sourceName, provider, err := s.resolveProvider(ctx, providerName, owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	targetName, _, err := s.resolveProvider(ctx, providerName, input.NewOwner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	if sourceName != targetName {
+		return nil, errors.NewBadRequestApiError(
+			fmt.Sprintf("cannot transfer repositories from %s to %s", sourceName, targetName),
+		)
+	}
+
+	repo, err := provider.TransferRepo(ctx, owner, name, input)
+	entry := repositories.AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Action:    repositories.AuditActionTransfer,
+		Transfer:  &input,
+		Owner:     owner,
+		FullName:  owner + "/" + name,
+		Status:    http.StatusOK,
+	}
+	if repo != nil {
+		entry.RepoId = repo.Id
+	}
+	if err != nil {
+		entry.Status = err.Status()
+		entry.Error = err.Message()
+	}
+	s.record(ctx, entry)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("repository transferred",
+		logger.Any("full_name", owner+"/"+name),
+		logger.Any("new_owner", input.NewOwner),
+		logger.Any("provider", sourceName),
+	)
+	return repo, nil
+}
+
+func (s *reposService) ListRepos(ctx context.Context, providerName string, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	ctx, span := tracing.Start(ctx, "reposService.ListRepos")
+	defer span.End()
+	span.SetAttribute("repository.owner", options.Owner)
+
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	providerName, provider, err := s.resolveProvider(ctx, providerName, options.Owner)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	if options.Owner, err = s.listOwner(ctx, providerName, provider, options.Owner); err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+
+	page, err := provider.ListRepos(ctx, options)
+	if err != nil {
+		span.SetError(err.Message())
+		return nil, err
+	}
+	return page, nil
+}
+
+func newBatchId() string {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(bytes)
+}
+
+func (s *reposService) CreateRepos(ctx context.Context, requests []repositories.CreateRepoRequest) (*repositories.CreateReposResponse, errors.ApiError) {
+	batchId := newBatchId()
+	ctx = audit.WithBatchId(ctx, batchId)
+	metrics.BatchSize.Observe(float64(len(requests)))
+
+	ctx, span := tracing.Start(ctx, "reposService.CreateRepos")
+	defer span.End()
+	span.SetAttribute("batch.id", batchId)
+	span.SetAttribute("batch.size", strconv.Itoa(len(requests)))
+
+	if len(requests) == 0 {
+		return nil, errors.NewBadRequestApiError("no repositories to create")
+	}
+
 	input := make(chan *repositories.CreateRepositoresResult)
 	output := make(chan *repositories.CreateReposResponse)
 	defer close(output)
@@ -53,13 +747,14 @@ func (s *reposService) CreateRepos(requests []repositories.CreateRepoRequest) (*
 
 	for _, current := range requests {
 		wg.Add(1)
-		go s.createRepoConcurrent(current, input)
+		go s.createRepoConcurrent(ctx, current, input)
 	}
 
 	wg.Wait()
 	close(input)
 
 	result := <-output
+	result.BatchId = batchId
 
 	successCreations := 0
 	for _, current := range result.Results {
@@ -84,6 +779,11 @@ func (s *reposService) handleRepoResults(wg *sync.WaitGroup, input <-chan *repos
 	var results repositories.CreateReposResponse
 
 	for result := range input {
+		if result.Error != nil {
+			metrics.BatchItems.Inc("failed", strconv.Itoa(result.Error.Status()))
+		} else {
+			metrics.BatchItems.Inc("created", strconv.Itoa(http.StatusCreated))
+		}
 		results.Results = append(results.Results, *result)
 		wg.Done()
 	}
@@ -91,8 +791,11 @@ func (s *reposService) handleRepoResults(wg *sync.WaitGroup, input <-chan *repos
 	output <- &results
 }
 
-func (s *reposService) createRepoConcurrent(input repositories.CreateRepoRequest, output chan<- *repositories.CreateRepositoresResult) {
-	res, err := s.CreateRepo(input)
+func (s *reposService) createRepoConcurrent(ctx context.Context, input repositories.CreateRepoRequest, output chan<- *repositories.CreateRepositoresResult) {
+	metrics.InFlightCreations.Inc()
+	defer metrics.InFlightCreations.Dec()
+
+	res, err := s.CreateRepo(ctx, input)
 
 	output <- &repositories.CreateRepositoresResult{Response: res, Error: err}
 }
@@ -1,21 +1,104 @@
 package services
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/audit"
+	"golang-microservices/src/api/auth"
 	"golang-microservices/src/api/domain/clients/restclient"
 	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/providers/github_provider"
+	"golang-microservices/src/api/ratelimit"
+	"golang-microservices/src/api/tracing"
 	"golang-microservices/src/api/utils/errors"
 	"io"
 	"net/http"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
+func newMockedProviders() (*providers.Registry, *restclient.Client) {
+	client, _ := restclient.NewClient("")
+	client.StartMock()
+
+	registry := providers.NewRegistry(providers.Github, nil)
+	registry.Register(providers.Github, github_provider.NewRepoProvider(
+		github_provider.New(client, "https://api.github.com", "2022-11-28", "abc123"),
+	))
+	return registry, client
+}
+
+// mockAccount answers the lookup of the account owning the GitHub token.
+func mockAccount(client *restclient.Client, login string) {
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 1, "login": "` + login + `"}`)),
+		},
+	})
+}
+
+func newMockedService() (ReposServiceInterface, *restclient.Client) {
+	return newFixture()
+}
+
+// fixture is the service under test and its mocked GitHub client, options
+// add the dependencies and the responses each test needs.
+type fixture struct {
+	deps   ReposDependencies
+	client *restclient.Client
+}
+
+type fixtureOption func(*fixture)
+
+func newFixture(options ...fixtureOption) (ReposServiceInterface, *restclient.Client) {
+	registry, client := newMockedProviders()
+	f := &fixture{deps: ReposDependencies{Providers: registry}, client: client}
+	for _, option := range options {
+		option(f)
+	}
+	return NewRepositoryService(f.deps), client
+}
+
+func withDependencies(apply func(deps *ReposDependencies)) fixtureOption {
+	return func(f *fixture) {
+		apply(&f.deps)
+	}
+}
+
+// withAccount answers the lookup of the account owning the GitHub token.
+func withAccount(login string) fixtureOption {
+	return func(f *fixture) {
+		mockAccount(f.client, login)
+	}
+}
+
+func withMock(method string, url string, status int, body string) fixtureOption {
+	return func(f *fixture) {
+		f.client.AddMock(&restclient.Mock{
+			Url:        url,
+			HttpMethod: method,
+			Response:   &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))},
+		})
+	}
+}
+
+// withCreatedRepo answers the creation of my-org/payments.
+func withCreatedRepo() fixtureOption {
+	return withMock(http.MethodPost, "https://api.github.com/orgs/my-org/repos", http.StatusCreated,
+		`{"id": 1, "name": "payments", "full_name": "my-org/payments", "default_branch": "main", "owner": {"login": "my-org"}}`)
+}
+
 func TestCreateRepoInvalidName(t *testing.T) {
 	request := repositories.CreateRepoRequest{}
 
-	res, err := RepositoryService.CreateRepo(request)
+	service, _ := newMockedService()
+	res, err := service.CreateRepo(context.Background(), request)
 	assert.Nil(t, res)
 	assert.NotNil(t, err)
 
@@ -23,10 +106,67 @@ func TestCreateRepoInvalidName(t *testing.T) {
 	assert.EqualValues(t, "invalid repository name", err.Message())
 }
 
+func TestCreateRepoOrgNotAllowed(t *testing.T) {
+	request := repositories.CreateRepoRequest{Name: "testing_repo", Org: "other-org"}
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"my-org"}})
+
+	service, _ := newMockedService()
+	res, err := service.CreateRepo(ctx, request)
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "caller is not allowed to create repositories in this org", err.Message())
+}
+
+func TestCreateRepoQuotaExceeded(t *testing.T) {
+	registry, client := newMockedProviders()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 2304923, "name": "testing_repo"}`)),
+		},
+	})
+
+	service := &reposService{providers: registry, quotaStore: ratelimit.NewMemoryStore(), dailyQuota: 1}
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{""}})
+
+	res, err := service.CreateRepo(ctx, repositories.CreateRepoRequest{Name: "testing_repo"})
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+
+	res, err = service.CreateRepo(ctx, repositories.CreateRepoRequest{Name: "testing_repo"})
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+	assert.EqualValues(t, "daily repository creation quota of 1 exceeded", err.Message())
+}
+
+func TestCreateRepoErrorFromGithubRefundsQuota(t *testing.T) {
+	registry, client := newMockedProviders()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Error:      errors.NewInternalServerError("unreachable"),
+	})
+
+	store := ratelimit.NewMemoryStore()
+	service := &reposService{providers: registry, quotaStore: store, dailyQuota: 1}
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{""}})
+
+	res, err := service.CreateRepo(ctx, repositories.CreateRepoRequest{Name: "testing_repo"})
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+
+	quota, _ := store.ConsumeQuota("team-a", 1, 1, time.Now())
+	assert.True(t, quota.Allowed)
+}
+
 func TestCreateRepoErrorFromGithub(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
-	restclient.AddMock(&restclient.Mock{
+	service, client := newMockedService()
+	client.AddMock(&restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
 		HttpMethod: http.MethodPost,
 		Response: &http.Response{
@@ -41,7 +181,7 @@ func TestCreateRepoErrorFromGithub(t *testing.T) {
 
 	request := repositories.CreateRepoRequest{Name: "testing_repo"}
 
-	res, err := RepositoryService.CreateRepo(request)
+	res, err := service.CreateRepo(context.Background(), request)
 	assert.Nil(t, res)
 	assert.NotNil(t, err)
 
@@ -50,10 +190,9 @@ func TestCreateRepoErrorFromGithub(t *testing.T) {
 }
 
 func TestCreateRepoNoError(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	service, client := newMockedService()
 
-	restclient.AddMock(&restclient.Mock{
+	client.AddMock(&restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
 		HttpMethod: http.MethodPost,
 		Response: &http.Response{
@@ -69,7 +208,7 @@ func TestCreateRepoNoError(t *testing.T) {
 
 	request := repositories.CreateRepoRequest{Name: "testing_repo"}
 
-	res, err := RepositoryService.CreateRepo(request)
+	res, err := service.CreateRepo(context.Background(), request)
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
 
@@ -79,10 +218,9 @@ func TestCreateRepoNoError(t *testing.T) {
 }
 
 func TestCreateRepoConcurrent(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	registry, client := newMockedProviders()
 
-	restclient.AddMock(&restclient.Mock{
+	client.AddMock(&restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
 		HttpMethod: http.MethodPost,
 		Response: &http.Response{
@@ -99,8 +237,8 @@ func TestCreateRepoConcurrent(t *testing.T) {
 	input := repositories.CreateRepoRequest{Name: "test", Description: "test description"}
 	output := make(chan *repositories.CreateRepositoresResult)
 
-	service := &reposService{}
-	go service.createRepoConcurrent(input, output)
+	service := &reposService{providers: registry}
+	go service.createRepoConcurrent(context.Background(), input, output)
 
 	res := <-output
 
@@ -139,7 +277,8 @@ func TestCreateReposInvalidRequests(t *testing.T) {
 		{},
 	}
 
-	res, err := RepositoryService.CreateRepos(requests)
+	service, _ := newMockedService()
+	res, err := service.CreateRepos(context.Background(), requests)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
@@ -156,11 +295,21 @@ func TestCreateReposInvalidRequests(t *testing.T) {
 	assert.EqualValues(t, "invalid repository name", res.Results[1].Error.Message())
 }
 
+func TestCreateReposEmptyBatch(t *testing.T) {
+	service, _ := newMockedService()
+
+	for _, requests := range [][]repositories.CreateRepoRequest{nil, {}} {
+		res, err := service.CreateRepos(context.Background(), requests)
+		assert.Nil(t, res)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, "no repositories to create", err.Message())
+	}
+}
+
 func TestCreateReposOneSuccessOneFail(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	service, client := newMockedService()
 
-	restclient.AddMock(&restclient.Mock{
+	client.AddMock(&restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
 		HttpMethod: http.MethodPost,
 		Response: &http.Response{
@@ -179,7 +328,7 @@ func TestCreateReposOneSuccessOneFail(t *testing.T) {
 		{},
 	}
 
-	res, err := RepositoryService.CreateRepos(requests)
+	res, err := service.CreateRepos(context.Background(), requests)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
@@ -206,10 +355,9 @@ func TestCreateReposOneSuccessOneFail(t *testing.T) {
 }
 
 func TestCreateReposOnlySuccess(t *testing.T) {
-	restclient.StartMock()
-	defer restclient.StopMock()
+	service, client := newMockedService()
 
-	restclient.AddMock(&restclient.Mock{
+	client.AddMock(&restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
 		HttpMethod: http.MethodPost,
 		Response: &http.Response{
@@ -227,7 +375,7 @@ func TestCreateReposOnlySuccess(t *testing.T) {
 		{Name: "testing_repo"},
 	}
 
-	res, err := RepositoryService.CreateRepos(requests)
+	res, err := service.CreateRepos(context.Background(), requests)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
@@ -240,3 +388,415 @@ func TestCreateReposOnlySuccess(t *testing.T) {
 	assert.EqualValues(t, "testing_repo", res.Results[0].Response.Name)
 	assert.EqualValues(t, "LeJeksey", res.Results[0].Response.Owner)
 }
+
+func TestCreateReposRecordsAudit(t *testing.T) {
+	sink := audit.NewMemorySink()
+	service, _ := newFixture(
+		withMock(http.MethodPost, "https://api.github.com/orgs/my-org/repos", http.StatusCreated,
+			`{"id": 2304923, "name": "testing_repo", "full_name": "my-org/testing_repo", "owner": {"login": "my-org"}}`),
+		withDependencies(func(deps *ReposDependencies) { deps.AuditLog = sink }),
+	)
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"my-org"}})
+
+	res, err := service.CreateRepos(ctx, []repositories.CreateRepoRequest{{Name: " testing_repo ", Org: "my-org"}, {}})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, res.BatchId)
+
+	entries, _ := sink.Query(repositories.AuditFilter{})
+	assert.EqualValues(t, 2, len(entries))
+
+	created, _ := sink.Query(repositories.AuditFilter{Owner: "my-org"})
+	assert.EqualValues(t, 1, len(created))
+	assert.EqualValues(t, "team-a", created[0].Caller)
+	assert.EqualValues(t, res.BatchId, created[0].BatchId)
+	assert.EqualValues(t, "testing_repo", created[0].Request.Name)
+	assert.EqualValues(t, 2304923, created[0].RepoId)
+	assert.EqualValues(t, "my-org/testing_repo", created[0].FullName)
+	assert.EqualValues(t, http.StatusCreated, created[0].Status)
+	assert.EqualValues(t, "", created[0].Error)
+}
+
+type spansExporter struct {
+	mutex sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (e *spansExporter) Export(span tracing.SpanData) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = append(e.spans, span)
+	return nil
+}
+
+func (e *spansExporter) Shutdown() error {
+	return nil
+}
+
+func TestCreateReposTracesEveryItem(t *testing.T) {
+	exporter := &spansExporter{}
+	tracing.Default().SetExporter(exporter)
+	defer tracing.Default().SetExporter(nil)
+
+	service, _ := newMockedService()
+	_, err := service.CreateRepos(context.Background(), []repositories.CreateRepoRequest{{}, {}})
+	assert.Nil(t, err)
+
+	var batch tracing.SpanData
+	var items []tracing.SpanData
+	for _, span := range exporter.spans {
+		switch span.Name {
+		case "reposService.CreateRepos":
+			batch = span
+		case "reposService.CreateRepo":
+			items = append(items, span)
+		}
+	}
+
+	assert.EqualValues(t, "2", batch.Attributes["batch.size"])
+	assert.EqualValues(t, 2, len(items))
+	for _, item := range items {
+		assert.EqualValues(t, batch.TraceId, item.TraceId)
+		assert.EqualValues(t, batch.SpanId, item.ParentSpanId)
+		assert.EqualValues(t, "invalid repository name", item.Error)
+	}
+}
+
+func TestCreateRepoCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	service, _ := newMockedService()
+	res, err := service.CreateRepo(ctx, repositories.CreateRepoRequest{Name: "testing_repo"})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, err.Status())
+	assert.EqualValues(t, "repository creation cancelled before completion", err.Message())
+}
+
+func TestCreateRepoUnknownProvider(t *testing.T) {
+	service, _ := newMockedService()
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "testing_repo", Provider: "Bitbucket"})
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "unknown repository provider bitbucket", err.Message())
+}
+
+type stubProvider struct {
+	created []repositories.CreateRepoRequest
+}
+
+func (p *stubProvider) CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	p.created = append(p.created, request)
+	return &repositories.Repository{Id: 42, Owner: request.Org, Name: request.Name, FullName: request.Org + "/" + request.Name}, nil
+}
+
+func (p *stubProvider) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	return nil, errors.NewNotFoundApiError("Not Found")
+}
+
+func (p *stubProvider) UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	return nil, errors.NewNotFoundApiError("Not Found")
+}
+
+func (p *stubProvider) TransferRepo(ctx context.Context, owner string, name string, request repositories.TransferRepoRequest) (*repositories.Repository, errors.ApiError) {
+	return nil, errors.NewNotFoundApiError("Not Found")
+}
+
+func (p *stubProvider) DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError {
+	return errors.NewNotFoundApiError("Not Found")
+}
+
+func (p *stubProvider) ListRepos(ctx context.Context, options repositories.ListReposOptions) (*repositories.RepositoryPage, errors.ApiError) {
+	return &repositories.RepositoryPage{}, nil
+}
+
+func TestCreateRepoSelectsProviderByOrg(t *testing.T) {
+	registry, _ := newMockedProviders()
+	gitlab := &stubProvider{}
+	registry.Register(providers.Gitlab, gitlab)
+	service := NewRepositoryService(ReposDependencies{Providers: registry})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "testing_repo", Org: "platform", Provider: "gitlab"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "platform/testing_repo", res.FullName)
+	assert.EqualValues(t, providers.Gitlab, res.Provider)
+	assert.EqualValues(t, 1, len(gitlab.created))
+
+	registry = providers.NewRegistry(providers.Github, map[string]string{"platform": providers.Gitlab})
+	registry.Register(providers.Gitlab, gitlab)
+	service = NewRepositoryService(ReposDependencies{Providers: registry})
+
+	res, err = service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "other_repo", Org: "platform"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, providers.Gitlab, res.Provider)
+	assert.EqualValues(t, 2, len(gitlab.created))
+}
+
+func TestGetRepoInvalidName(t *testing.T) {
+	service, _ := newMockedService()
+
+	res, err := service.GetRepo(context.Background(), "", "my-org", " ")
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "invalid repository owner or name", err.Message())
+}
+
+func TestGetRepoOwnerNotAllowed(t *testing.T) {
+	service, _ := newMockedService()
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"my-org"}})
+
+	res, err := service.GetRepo(ctx, "", "other-org", "testing_repo")
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "caller is not allowed to access repositories of this owner", err.Message())
+}
+
+func TestGetRepoOfTokenAccount(t *testing.T) {
+	service, client := newMockedService()
+	mockAccount(client, "LeJeksey")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/LeJeksey/testing_repo",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 2304923, "name": "testing_repo", "owner": {"login": "LeJeksey"}}`)),
+		},
+	})
+
+	orgOnly := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"my-org", "LeJeksey"}})
+	res, err := service.GetRepo(orgOnly, "", "lejeksey", "testing_repo")
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	account := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{""}})
+	res, err = service.GetRepo(account, "", "LeJeksey", "testing_repo")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "LeJeksey", res.Owner)
+
+	res, err = service.GetRepo(account, "", "my-org", "testing_repo")
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
+
+func TestGetRepoAccountLookupFails(t *testing.T) {
+	service, client := newMockedService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		Error:      errors.NewInternalServerError("unreachable"),
+	})
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"my-org"}})
+
+	res, err := service.GetRepo(ctx, "", "my-org", "testing_repo")
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusServiceUnavailable, err.Status())
+	assert.EqualValues(t, "error when resolving the account of provider github", err.Message())
+}
+
+func TestGetRepoNoError(t *testing.T) {
+	service, client := newMockedService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"id": 2304923, "name": "testing_repo", "full_name": "my-org/testing_repo", "private": true, "owner": {"login": "my-org"}}`,
+			)),
+		},
+	})
+
+	res, err := service.GetRepo(context.Background(), "GitHub", "my-org", "testing_repo")
+	assert.Nil(t, err)
+	assert.EqualValues(t, &repositories.Repository{
+		Id:       2304923,
+		Owner:    "my-org",
+		Name:     "testing_repo",
+		FullName: "my-org/testing_repo",
+		Private:  true,
+		Provider: "github",
+	}, res)
+}
+
+func TestUpdateRepoNothingToUpdate(t *testing.T) {
+	service, _ := newMockedService()
+
+	res, err := service.UpdateRepo(context.Background(), "", "my-org", "testing_repo", repositories.UpdateRepoRequest{})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "nothing to update", err.Message())
+}
+
+func TestUpdateRepoOwnerNotAllowed(t *testing.T) {
+	service, _ := newMockedService()
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"my-org"}})
+	archived := true
+
+	res, err := service.UpdateRepo(ctx, "", "other-org", "testing_repo", repositories.UpdateRepoRequest{Archived: &archived})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
+
+func TestUpdateRepoErrorFromGithub(t *testing.T) {
+	service, client := newMockedService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Validation Failed"}`)),
+		},
+	})
+	homepage := "https://example.com"
+
+	res, err := service.UpdateRepo(context.Background(), "", "my-org", "testing_repo", repositories.UpdateRepoRequest{Homepage: &homepage})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.Status())
+	assert.EqualValues(t, "Validation Failed", err.Message())
+}
+
+func TestUpdateRepoNoError(t *testing.T) {
+	service, client := newMockedService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"id": 2304923, "name": "testing_repo", "full_name": "my-org/testing_repo", "homepage": "https://example.com", "archived": true, "owner": {"login": "my-org"}}`,
+			)),
+		},
+	})
+	homepage, archived := " https://example.com ", true
+
+	res, err := service.UpdateRepo(context.Background(), "", "my-org", "testing_repo", repositories.UpdateRepoRequest{Homepage: &homepage, Archived: &archived})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "https://example.com", res.Homepage)
+	assert.True(t, res.Archived)
+}
+
+func TestTransferRepoSameOwner(t *testing.T) {
+	service, _ := newMockedService()
+
+	res, err := service.TransferRepo(context.Background(), "", "my-org", "testing_repo", repositories.TransferRepoRequest{NewOwner: "My-Org"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "repository already belongs to my-org", err.Message())
+}
+
+func TestTransferRepoAcrossProviders(t *testing.T) {
+	registry := providers.NewRegistry(providers.Github, map[string]string{"platform": providers.Gitlab})
+	registry.Register(providers.Github, &stubProvider{})
+	registry.Register(providers.Gitlab, &stubProvider{})
+	service := NewRepositoryService(ReposDependencies{Providers: registry})
+
+	res, err := service.TransferRepo(context.Background(), "", "my-org", "testing_repo", repositories.TransferRepoRequest{NewOwner: "platform"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "cannot transfer repositories from github to gitlab", err.Message())
+}
+
+func TestTransferRepoTargetNotAllowed(t *testing.T) {
+	service, client := newMockedService()
+	mockAccount(client, "LeJeksey")
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"my-org"}})
+
+	res, err := service.TransferRepo(ctx, "", "my-org", "testing_repo", repositories.TransferRepoRequest{NewOwner: "other-org"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
+
+func TestTransferRepoNoError(t *testing.T) {
+	service, client := newMockedService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo/transfer",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusAccepted,
+			Body: io.NopCloser(strings.NewReader(
+				`{"id": 2304923, "name": "testing_repo", "full_name": "new-org/testing_repo", "owner": {"login": "new-org"}}`,
+			)),
+		},
+	})
+
+	res, err := service.TransferRepo(context.Background(), "", "my-org", "testing_repo", repositories.TransferRepoRequest{NewOwner: "new-org"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "new-org/testing_repo", res.FullName)
+	assert.EqualValues(t, "github", res.Provider)
+}
+
+func TestTransferRepoRecordsAudit(t *testing.T) {
+	registry, client := newMockedProviders()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/testing_repo/transfer",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusAccepted,
+			Body: io.NopCloser(strings.NewReader(
+				`{"id": 2304923, "name": "testing_repo", "full_name": "new-org/testing_repo", "owner": {"login": "new-org"}}`,
+			)),
+		},
+	})
+
+	sink := audit.NewMemorySink()
+	service := NewRepositoryService(ReposDependencies{Providers: registry, AuditLog: sink})
+
+	_, err := service.TransferRepo(context.Background(), "", "my-org", "testing_repo", repositories.TransferRepoRequest{NewOwner: "new-org"})
+	assert.Nil(t, err)
+
+	entries, _ := sink.Query(repositories.AuditFilter{Owner: "my-org"})
+	if assert.EqualValues(t, 1, len(entries)) {
+		assert.EqualValues(t, repositories.AuditActionTransfer, entries[0].Action)
+		assert.EqualValues(t, "my-org/testing_repo", entries[0].FullName)
+		assert.EqualValues(t, "new-org", entries[0].Transfer.NewOwner)
+		assert.EqualValues(t, 2304923, entries[0].RepoId)
+		assert.EqualValues(t, http.StatusOK, entries[0].Status)
+	}
+}
+
+func TestListReposInvalidVisibility(t *testing.T) {
+	service, _ := newMockedService()
+
+	res, err := service.ListRepos(context.Background(), "", repositories.ListReposOptions{Visibility: "internal"})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestListReposNoError(t *testing.T) {
+	service, client := newMockedService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user/repos?affiliation=owner&page=1&per_page=30&visibility=private",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Link": {`<https://api.github.com/user/repos?page=2>; rel="next"`}},
+			Body:       io.NopCloser(strings.NewReader(`[{"id": 1, "name": "one", "full_name": "LeJeksey/one", "owner": {"login": "LeJeksey"}}]`)),
+		},
+	})
+
+	res, err := service.ListRepos(context.Background(), "", repositories.ListReposOptions{Visibility: "Private"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, res.Page)
+	assert.EqualValues(t, 2, res.NextPage)
+	assert.EqualValues(t, "LeJeksey/one", res.Repositories[0].FullName)
+}
+
+func TestListReposOfTokenAccount(t *testing.T) {
+	service, client := newMockedService()
+	mockAccount(client, "LeJeksey")
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/user/repos?affiliation=owner&page=1&per_page=30",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`[{"id": 1, "name": "one", "full_name": "LeJeksey/one", "owner": {"login": "LeJeksey"}}]`)),
+		},
+	})
+
+	res, err := service.ListRepos(context.Background(), "", repositories.ListReposOptions{Owner: "lejeksey"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "LeJeksey/one", res.Repositories[0].FullName)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/templates"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newTemplatesService(t *testing.T) (ReposServiceInterface, *restclient.Client) {
+	store := templates.NewStore()
+	assert.Nil(t, store.Add("go-service", "README.md", "# {{.Name}}\n\n{{.Description}}\n"))
+	assert.Nil(t, store.Add("go-service", "CODEOWNERS", "* @{{.Owner}}/{{.Team}}\n"))
+
+	return newFixture(withCreatedRepo(), withDependencies(func(deps *ReposDependencies) { deps.Templates = store }))
+}
+
+func TestCreateRepoUnknownTemplate(t *testing.T) {
+	service, _ := newTemplatesService(t)
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name: "payments", Org: "my-org", Templates: []string{"python-service"},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "unknown template python-service", err.Message())
+}
+
+func TestCreateRepoTemplatesNotSupported(t *testing.T) {
+	registry := providers.NewRegistry(providers.Gitlab, nil)
+	registry.Register(providers.Gitlab, &stubProvider{})
+	service := NewRepositoryService(ReposDependencies{Providers: registry})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name: "payments", Templates: []string{"go-service"},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "provider gitlab does not support templates", err.Message())
+}
+
+func TestCreateRepoWithTemplates(t *testing.T) {
+	service, client := newTemplatesService(t)
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/git/ref/heads/main",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"object": {"sha": "c1"}}`)),
+		},
+	})
+	for _, mock := range []struct{ method, url, body string }{
+		{http.MethodGet, "https://api.github.com/repos/my-org/payments/git/commits/c1", `{"sha": "c1", "tree": {"sha": "t1"}}`},
+		{http.MethodPost, "https://api.github.com/repos/my-org/payments/git/trees", `{"sha": "t2"}`},
+		{http.MethodPost, "https://api.github.com/repos/my-org/payments/git/commits", `{"sha": "c2"}`},
+		{http.MethodPatch, "https://api.github.com/repos/my-org/payments/git/refs/heads/main", `{}`},
+	} {
+		client.AddMock(&restclient.Mock{
+			Url:        mock.url,
+			HttpMethod: mock.method,
+			Response:   &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(mock.body))},
+		})
+	}
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name: "payments", Org: "my-org", Team: "core", Templates: []string{" go-service "},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"CODEOWNERS", "README.md"}, res.Files)
+	assert.Nil(t, res.Warnings)
+}
+
+func TestCreateRepoWithTemplatesCommitFails(t *testing.T) {
+	service, client := newTemplatesService(t)
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/git/ref/heads/main",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Resource not accessible by integration"}`)),
+		},
+	})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name: "payments", Org: "my-org", Templates: []string{"go-service/README.md"},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org/payments", res.FullName)
+	assert.Nil(t, res.Files)
+	assert.EqualValues(t, []string{"initial files were not committed: Resource not accessible by integration"}, res.Warnings)
+}
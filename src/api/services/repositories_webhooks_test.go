@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang-microservices/src/api/auth"
+	"golang-microservices/src/api/domain/clients/restclient"
+	"golang-microservices/src/api/domain/repositories"
+	"golang-microservices/src/api/providers"
+	"golang-microservices/src/api/secrets"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newWebhooksService() (ReposServiceInterface, *restclient.Client) {
+	store := secrets.NewScopedStore(
+		map[string]string{"ci-hook": "s3cr3t", "npm": "t0ken"},
+		map[string]secrets.Scope{"ci-hook": {Orgs: []string{"my-org"}}, "npm": {Callers: []string{"team-b"}}},
+	)
+	return newFixture(withCreatedRepo(), withDependencies(func(deps *ReposDependencies) { deps.Secrets = store }))
+}
+
+func TestCreateRepoUnknownWebhookSecret(t *testing.T) {
+	service, _ := newWebhooksService()
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:     "payments",
+		Org:      "my-org",
+		Webhooks: []repositories.Webhook{{Url: "https://ci.example.com/hooks", Secret: "missing"}},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "unknown secret missing", err.Message())
+}
+
+func TestCreateRepoWebhookSecretOutsideScope(t *testing.T) {
+	service, _ := newWebhooksService()
+	ctx := auth.WithCaller(context.Background(), &auth.Caller{Id: "team-a", Orgs: []string{"*"}})
+
+	res, err := service.CreateRepo(ctx, repositories.CreateRepoRequest{
+		Name:     "payments",
+		Org:      "other-org",
+		Webhooks: []repositories.Webhook{{Url: "https://ci.example.com/hooks", Secret: "ci-hook"}},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "caller is not allowed to use secret ci-hook", err.Message())
+
+	res, err = service.CreateRepo(ctx, repositories.CreateRepoRequest{
+		Name:     "payments",
+		Org:      "my-org",
+		Webhooks: []repositories.Webhook{{Url: "https://ci.example.com/hooks", Secret: "npm"}},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "caller is not allowed to use secret npm", err.Message())
+}
+
+func TestCreateRepoWebhooksNotSupported(t *testing.T) {
+	registry := providers.NewRegistry(providers.Gitlab, nil)
+	registry.Register(providers.Gitlab, &stubProvider{})
+	service := NewRepositoryService(ReposDependencies{Providers: registry})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:     "payments",
+		Webhooks: []repositories.Webhook{{Url: "https://ci.example.com/hooks"}},
+	})
+	assert.Nil(t, res)
+	assert.EqualValues(t, "provider gitlab does not support webhooks", err.Message())
+}
+
+func TestCreateRepoWithWebhooks(t *testing.T) {
+	service, client := newWebhooksService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/hooks",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 12345678, "active": true}`)),
+		},
+	})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:     "payments",
+		Org:      "my-org",
+		Webhooks: []repositories.Webhook{{Url: "https://ci.example.com/hooks", Events: []string{"push"}, Secret: "ci-hook"}},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []int64{12345678}, res.HookIds)
+	assert.Nil(t, res.Warnings)
+}
+
+func TestCreateRepoWebhookFailsWithRedactedUrl(t *testing.T) {
+	service, client := newWebhooksService()
+	client.AddMock(&restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/payments/hooks",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+		},
+	})
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:     "payments",
+		Org:      "my-org",
+		Webhooks: []repositories.Webhook{{Url: "https://hooks.slack.com/services/T000/B000/XXXX", Secret: "ci-hook"}},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, res.HookIds)
+	assert.EqualValues(t, []string{"webhook https://hooks.slack.com was not created: Not Found"}, res.Warnings)
+}
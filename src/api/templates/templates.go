@@ -0,0 +1,155 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"golang-microservices/src/api/domain/repositories"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// templateSuffix is dropped from file names so templates of files that are
+// picked up by tooling, such as workflows, can be stored without side effects.
+const templateSuffix = ".tmpl"
+
+// Vars are the values available to every template.
+type Vars struct {
+	Name        string
+	Owner       string
+	Description string
+	Team        string
+	Year        int
+}
+
+type file struct {
+	path     string
+	template *template.Template
+}
+
+// Store holds template sets, every directory below the root is a set and
+// every file inside it, at any depth, is rendered to the same relative path.
+type Store struct {
+	sets map[string][]file
+}
+
+func NewStore() *Store {
+	return &Store{sets: make(map[string][]file)}
+}
+
+// Load reads every set below dir, an empty dir gives an empty store.
+func Load(dir string) (*Store, error) {
+	store := NewStore()
+	if dir == "" {
+		return store, nil
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if err := store.loadSet(entry.Name(), filepath.Join(dir, entry.Name())); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+func (s *Store) loadSet(name string, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return s.Add(name, filepath.ToSlash(relative), string(content))
+	})
+}
+
+// Add parses content and registers it in set under path.
+func (s *Store) Add(set string, path string, content string) error {
+	path = strings.TrimSuffix(path, templateSuffix)
+	parsed, err := template.New(path).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return fmt.Errorf("invalid template %s/%s: %w", set, path, err)
+	}
+
+	s.sets[set] = append(s.sets[set], file{path: path, template: parsed})
+	sort.Slice(s.sets[set], func(i, j int) bool { return s.sets[set][i].path < s.sets[set][j].path })
+	return nil
+}
+
+func (s *Store) Names() []string {
+	result := make([]string, 0, len(s.sets))
+	for name := range s.sets {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// lookup resolves a reference, either a whole set or a single set/path file.
+func (s *Store) lookup(ref string) []file {
+	if files, ok := s.sets[ref]; ok {
+		return files
+	}
+
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	for _, current := range s.sets[parts[0]] {
+		if current.path == parts[1] {
+			return []file{current}
+		}
+	}
+	return nil
+}
+
+func (s *Store) Has(ref string) bool {
+	return len(s.lookup(ref)) > 0
+}
+
+// Render renders the referenced templates in order, a later reference wins
+// when two of them produce the same path.
+func (s *Store) Render(refs []string, vars Vars) ([]repositories.File, error) {
+	result := make([]repositories.File, 0)
+	positions := make(map[string]int)
+
+	for _, ref := range refs {
+		files := s.lookup(ref)
+		if len(files) == 0 {
+			return nil, fmt.Errorf("unknown template %s", ref)
+		}
+
+		for _, current := range files {
+			var content bytes.Buffer
+			if err := current.template.Execute(&content, vars); err != nil {
+				return nil, fmt.Errorf("error rendering template %s: %w", ref, err)
+			}
+
+			rendered := repositories.File{Path: current.path, Content: content.Bytes()}
+			if position, ok := positions[current.path]; ok {
+				result[position] = rendered
+				continue
+			}
+			positions[current.path] = len(result)
+			result = append(result, rendered)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package templates
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoadAndRender(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go-service", "README.md"), "# {{.Name}}\n\n{{.Description}}\n")
+	writeFile(t, filepath.Join(dir, "go-service", "CODEOWNERS"), "* @{{.Owner}}/{{.Team}}\n")
+	writeFile(t, filepath.Join(dir, "go-service", ".github", "workflows", "ci.yml.tmpl"), "name: {{.Name}} ci\n")
+	writeFile(t, filepath.Join(dir, "licenses", "LICENSE"), "Copyright {{.Year}} {{.Owner}}\n")
+	writeFile(t, filepath.Join(dir, "ignored.txt"), "not a set")
+
+	store, err := Load(dir)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go-service", "licenses"}, store.Names())
+	assert.True(t, store.Has("go-service"))
+	assert.True(t, store.Has("go-service/.github/workflows/ci.yml"))
+	assert.False(t, store.Has("go-service/missing"))
+
+	files, err := store.Render([]string{"go-service", "licenses/LICENSE"},
+		Vars{Name: "payments", Owner: "my-org", Description: "Payments api", Team: "core", Year: 2024})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 4, len(files))
+	assert.EqualValues(t, ".github/workflows/ci.yml", files[0].Path)
+	assert.EqualValues(t, "name: payments ci\n", string(files[0].Content))
+	assert.EqualValues(t, "* @my-org/core\n", string(files[1].Content))
+	assert.EqualValues(t, "# payments\n\nPayments api\n", string(files[2].Content))
+	assert.EqualValues(t, "Copyright 2024 my-org\n", string(files[3].Content))
+}
+
+func TestRenderLaterReferenceWins(t *testing.T) {
+	store := NewStore()
+	assert.Nil(t, store.Add("base", "README.md", "base {{.Name}}"))
+	assert.Nil(t, store.Add("team", "README.md", "team {{.Name}}"))
+
+	files, err := store.Render([]string{"base", "team"}, Vars{Name: "one"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(files))
+	assert.EqualValues(t, "team one", string(files[0].Content))
+}
+
+func TestRenderErrors(t *testing.T) {
+	store := NewStore()
+	assert.NotNil(t, store.Add("broken", "README.md", "{{.Name"))
+	assert.Nil(t, store.Add("base", "README.md", "{{.Missing}}"))
+
+	_, err := store.Render([]string{"unknown"}, Vars{})
+	assert.EqualValues(t, "unknown template unknown", err.Error())
+
+	_, err = store.Render([]string{"base"}, Vars{})
+	assert.NotNil(t, err)
+}
+
+func TestLoadEmptyDir(t *testing.T) {
+	store, err := Load("")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{}, store.Names())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+type stdoutExporter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func NewStdoutExporter(writer io.Writer) Exporter {
+	return &stdoutExporter{writer: writer}
+}
+
+func (e *stdoutExporter) Export(span SpanData) error {
+	bytes, err := json.Marshal(span)
+	if err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	_, err = e.writer.Write(append(bytes, '\n'))
+	return err
+}
+
+func (e *stdoutExporter) Shutdown() error {
+	return nil
+}
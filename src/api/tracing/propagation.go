@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const HeaderTraceparent = "traceparent"
+
+const traceparentVersion = "00"
+const flagSampled = "01"
+const flagNotSampled = "00"
+
+func isHex(value string) bool {
+	for _, char := range value {
+		if !(char >= '0' && char <= '9' || char >= 'a' && char <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	if parts[0] == traceparentVersion && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+	for _, part := range parts[:4] {
+		if !isHex(part) {
+			return SpanContext{}, false
+		}
+	}
+
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	spanContext := SpanContext{TraceId: parts[1], SpanId: parts[2], Sampled: flags&1 == 1}
+	return spanContext, spanContext.IsValid()
+}
+
+func (sc SpanContext) Traceparent() string {
+	flags := flagNotSampled
+	if sc.Sampled {
+		flags = flagSampled
+	}
+	return traceparentVersion + "-" + sc.TraceId + "-" + sc.SpanId + "-" + flags
+}
+
+func Extract(ctx context.Context, headers http.Header) context.Context {
+	spanContext, ok := ParseTraceparent(headers.Get(HeaderTraceparent))
+	if !ok {
+		return ctx
+	}
+	return WithRemoteSpanContext(ctx, spanContext)
+}
+
+func Inject(ctx context.Context, headers http.Header) {
+	if spanContext, ok := SpanContextFromContext(ctx); ok {
+		headers.Set(HeaderTraceparent, spanContext.Traceparent())
+	}
+}
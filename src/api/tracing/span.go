@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type SpanContext struct {
+	TraceId string
+	SpanId  string
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return len(sc.TraceId) == 32 && len(sc.SpanId) == 16 &&
+		sc.TraceId != "00000000000000000000000000000000" && sc.SpanId != "0000000000000000"
+}
+
+type SpanData struct {
+	Name         string            `json:"name"`
+	TraceId      string            `json:"trace_id"`
+	SpanId       string            `json:"span_id"`
+	ParentSpanId string            `json:"parent_span_id,omitempty"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	DurationMs   float64           `json:"duration_ms"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+type Span struct {
+	mutex   sync.Mutex
+	tracer  *Tracer
+	context SpanContext
+	data    SpanData
+	ended   bool
+}
+
+func (s *Span) Context() SpanContext {
+	return s.context
+}
+
+func (s *Span) SetAttribute(key string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]string)
+	}
+	s.data.Attributes[key] = value
+}
+
+func (s *Span) SetError(message string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data.Error = message
+}
+
+func (s *Span) End() {
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.tracer.now()
+	s.data.DurationMs = float64(s.data.End.Sub(s.data.Start).Microseconds()) / 1000
+	data := s.data
+	s.mutex.Unlock()
+
+	if s.context.Sampled {
+		s.tracer.export(data)
+	}
+}
+
+func newId(size int) string {
+	bytes := make([]byte, size)
+	_, _ = rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"golang-microservices/src/api/utils/logger"
+	"sync"
+	"time"
+)
+
+type Exporter interface {
+	Export(span SpanData) error
+	Shutdown() error
+}
+
+type Tracer struct {
+	mutex    sync.RWMutex
+	exporter Exporter
+	now      func() time.Time
+}
+
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter, now: time.Now}
+}
+
+func (t *Tracer) SetExporter(exporter Exporter) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.exporter = exporter
+}
+
+func (t *Tracer) Shutdown() error {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if t.exporter == nil {
+		return nil
+	}
+	return t.exporter.Shutdown()
+}
+
+func (t *Tracer) export(span SpanData) {
+	t.mutex.RLock()
+	exporter := t.exporter
+	t.mutex.RUnlock()
+
+	if exporter == nil {
+		return
+	}
+	if err := exporter.Export(span); err != nil {
+		logger.Default().Warn("error when exporting span", logger.Err(err))
+	}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	spanContext := SpanContext{TraceId: newId(16), SpanId: newId(8), Sampled: true}
+	parentSpanId := ""
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		spanContext.TraceId = parent.TraceId
+		spanContext.Sampled = parent.Sampled
+		parentSpanId = parent.SpanId
+	}
+
+	span := &Span{
+		tracer:  t,
+		context: spanContext,
+		data: SpanData{
+			Name:         name,
+			TraceId:      spanContext.TraceId,
+			SpanId:       spanContext.SpanId,
+			ParentSpanId: parentSpanId,
+			Start:        t.now(),
+		},
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+type spanKey struct{}
+type remoteSpanContextKey struct{}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func WithRemoteSpanContext(ctx context.Context, spanContext SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanContextKey{}, spanContext)
+}
+
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context(), true
+	}
+	spanContext, ok := ctx.Value(remoteSpanContextKey{}).(SpanContext)
+	return spanContext, ok && spanContext.IsValid()
+}
+
+var defaultTracer = NewTracer(nil)
+
+func Default() *Tracer {
+	return defaultTracer
+}
+
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return defaultTracer.Start(ctx, name)
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type memoryExporter struct {
+	spans []SpanData
+}
+
+func (e *memoryExporter) Export(span SpanData) error {
+	e.spans = append(e.spans, span)
+	return nil
+}
+
+func (e *memoryExporter) Shutdown() error {
+	return nil
+}
+
+func TestParseTraceparent(t *testing.T) {
+	spanContext, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(t, ok)
+	assert.EqualValues(t, SpanContext{
+		TraceId: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanId:  "00f067aa0ba902b7",
+		Sampled: true,
+	}, spanContext)
+	assert.EqualValues(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", spanContext.Traceparent())
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+	}
+	for _, value := range invalid {
+		_, ok := ParseTraceparent(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestStartChildSpan(t *testing.T) {
+	exporter := &memoryExporter{}
+	tracer := NewTracer(exporter)
+
+	headers := http.Header{}
+	headers.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := Extract(context.Background(), headers)
+
+	ctx, batch := tracer.Start(ctx, "reposService.CreateRepos")
+	_, item := tracer.Start(ctx, "reposService.CreateRepo")
+	item.SetAttribute("repository.name", "testing_repo")
+	item.SetError("invalid repository name")
+	item.End()
+	batch.End()
+	batch.End()
+
+	assert.EqualValues(t, 2, len(exporter.spans))
+	assert.EqualValues(t, "reposService.CreateRepo", exporter.spans[0].Name)
+	assert.EqualValues(t, "4bf92f3577b34da6a3ce929d0e0e4736", exporter.spans[0].TraceId)
+	assert.EqualValues(t, batch.Context().SpanId, exporter.spans[0].ParentSpanId)
+	assert.EqualValues(t, map[string]string{"repository.name": "testing_repo"}, exporter.spans[0].Attributes)
+	assert.EqualValues(t, "invalid repository name", exporter.spans[0].Error)
+	assert.EqualValues(t, "00f067aa0ba902b7", exporter.spans[1].ParentSpanId)
+}
+
+func TestNotSampledSpansAreNotExported(t *testing.T) {
+	exporter := &memoryExporter{}
+	tracer := NewTracer(exporter)
+
+	headers := http.Header{}
+	headers.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, span := tracer.Start(Extract(context.Background(), headers), "GET /marco")
+	span.End()
+
+	assert.EqualValues(t, 0, len(exporter.spans))
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var buffer bytes.Buffer
+	exporter := NewStdoutExporter(&buffer)
+
+	assert.Nil(t, exporter.Export(SpanData{Name: "GET /marco", TraceId: "abc", SpanId: "def"}))
+
+	var span SpanData
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &span))
+	assert.EqualValues(t, "GET /marco", span.Name)
+}
+
+func TestTransportInjectsTraceparent(t *testing.T) {
+	exporter := &memoryExporter{}
+	Default().SetExporter(exporter)
+	defer Default().SetExporter(nil)
+
+	var actualTraceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actualTraceparent = r.Header.Get(HeaderTraceparent)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	ctx, parent := Start(context.Background(), "reposService.CreateRepo")
+	request, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/user/repos", nil)
+	client := &http.Client{Transport: NewTransport(nil)}
+	_, err := client.Do(request)
+	assert.Nil(t, err)
+
+	assert.EqualValues(t, 1, len(exporter.spans))
+	outbound := exporter.spans[0]
+	assert.EqualValues(t, "POST /user/repos", outbound.Name)
+	assert.EqualValues(t, parent.Context().SpanId, outbound.ParentSpanId)
+	assert.EqualValues(t, "201", outbound.Attributes["http.status_code"])
+	assert.True(t, strings.HasPrefix(actualTraceparent, "00-"+parent.Context().TraceId+"-"+outbound.SpanId))
+}
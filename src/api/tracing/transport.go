@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"net/http"
+	"strconv"
+)
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx, span := Start(request.Context(), request.Method+" "+request.URL.Path)
+	defer span.End()
+
+	span.SetAttribute("http.method", request.Method)
+	span.SetAttribute("http.url", request.URL.Scheme+"://"+request.URL.Host+request.URL.Path)
+
+	outbound := request.Clone(ctx)
+	Inject(ctx, outbound.Header)
+
+	response, err := t.base.RoundTrip(outbound)
+	if err != nil {
+		span.SetError(err.Error())
+		return response, err
+	}
+
+	span.SetAttribute("http.status_code", strconv.Itoa(response.StatusCode))
+	if response.StatusCode >= http.StatusInternalServerError {
+		span.SetError(response.Status)
+	}
+	return response, nil
+}
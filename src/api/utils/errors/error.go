@@ -53,3 +53,19 @@ func NewInternalServerError(message string) ApiError {
 func NewBadRequestApiError(message string) ApiError {
 	return NewApiError(http.StatusBadRequest, message)
 }
+
+func NewUnauthorizedApiError(message string) ApiError {
+	return NewApiError(http.StatusUnauthorized, message)
+}
+
+func NewForbiddenApiError(message string) ApiError {
+	return NewApiError(http.StatusForbidden, message)
+}
+
+func NewTooManyRequestsApiError(message string) ApiError {
+	return NewApiError(http.StatusTooManyRequests, message)
+}
+
+func NewServiceUnavailableApiError(message string) ApiError {
+	return NewApiError(http.StatusServiceUnavailable, message)
+}
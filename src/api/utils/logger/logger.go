@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+func ParseLevel(value string) (Level, bool) {
+	for level, name := range levelNames {
+		if strings.EqualFold(strings.TrimSpace(value), name) {
+			return level, true
+		}
+	}
+	return LevelInfo, false
+}
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error", Value: nil}
+	}
+	return Field{Key: "error", Value: err.Error()}
+}
+
+type Logger interface {
+	Debug(message string, fields ...Field)
+	Info(message string, fields ...Field)
+	Warn(message string, fields ...Field)
+	Error(message string, fields ...Field)
+	With(fields ...Field) Logger
+}
+
+type output struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+type jsonLogger struct {
+	output *output
+	level  Level
+	fields []Field
+	now    func() time.Time
+}
+
+func New(writer io.Writer, level Level) Logger {
+	return &jsonLogger{output: &output{writer: writer}, level: level, now: time.Now}
+}
+
+func (l *jsonLogger) With(fields ...Field) Logger {
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &jsonLogger{output: l.output, level: l.level, fields: merged, now: l.now}
+}
+
+func (l *jsonLogger) Debug(message string, fields ...Field) {
+	l.write(LevelDebug, message, fields)
+}
+
+func (l *jsonLogger) Info(message string, fields ...Field) {
+	l.write(LevelInfo, message, fields)
+}
+
+func (l *jsonLogger) Warn(message string, fields ...Field) {
+	l.write(LevelWarn, message, fields)
+}
+
+func (l *jsonLogger) Error(message string, fields ...Field) {
+	l.write(LevelError, message, fields)
+}
+
+func (l *jsonLogger) write(level Level, message string, fields []Field) {
+	if level < l.level {
+		return
+	}
+
+	record := make(map[string]interface{}, len(l.fields)+len(fields)+3)
+	for _, field := range l.fields {
+		record[field.Key] = redactValue(field.Value)
+	}
+	for _, field := range fields {
+		record[field.Key] = redactValue(field.Value)
+	}
+	record["time"] = l.now().UTC().Format(time.RFC3339Nano)
+	record["level"] = level.String()
+	record["msg"] = RedactString(message)
+
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		bytes, _ = json.Marshal(map[string]string{
+			"time":  record["time"].(string),
+			"level": LevelError.String(),
+			"msg":   "error when encoding log record: " + err.Error(),
+		})
+	}
+
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+	_, _ = l.output.writer.Write(append(bytes, '\n'))
+}
+
+var defaultLogger = New(os.Stderr, LevelInfo)
+
+func Default() Logger {
+	return defaultLogger
+}
+
+func SetDefault(logger Logger) {
+	defaultLogger = logger
+}
+
+type loggerKey struct{}
+
+func WithContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+			return logger
+		}
+	}
+	return defaultLogger
+}
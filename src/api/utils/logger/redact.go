@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"net/http"
+	"regexp"
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"X-Api-Key":           true,
+	"Private-Token":       true,
+	"Cookie":              true,
+}
+
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`github_pat_[A-Za-z0-9_]{20,}`),
+	regexp.MustCompile(`glpat-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+}
+
+var credentialPattern = regexp.MustCompile(`(?i)\b(token|bearer|basic)\s+[A-Za-z0-9\-._~+/=]+`)
+
+func RedactString(value string) string {
+	for _, pattern := range tokenPatterns {
+		value = pattern.ReplaceAllString(value, redacted)
+	}
+	return credentialPattern.ReplaceAllString(value, "$1 "+redacted)
+}
+
+func RedactHeaders(headers http.Header) map[string]string {
+	result := make(map[string]string, len(headers))
+	for name, values := range headers {
+		canonical := http.CanonicalHeaderKey(name)
+		if sensitiveHeaders[canonical] {
+			result[canonical] = redacted
+			continue
+		}
+		if len(values) > 0 {
+			result[canonical] = RedactString(values[0])
+		}
+	}
+	return result
+}
+
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case string:
+		return RedactString(typed)
+	case http.Header:
+		return RedactHeaders(typed)
+	case error:
+		return RedactString(typed.Error())
+	default:
+		return value
+	}
+}
@@ -0,0 +1,47 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const Header = "X-Request-Id"
+
+const maxLength = 128
+
+func New() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(bytes)
+}
+
+func IsValid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, char := range id {
+		if char < '!' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+type requestIdKey struct{}
+
+func WithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}